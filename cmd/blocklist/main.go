@@ -0,0 +1,95 @@
+// blocklist - CLI สำหรับสร้างไฟล์ bloom filter ของรหัสผ่านที่เคยรั่วไหล
+//
+// Usage:
+//
+//	go run ./cmd/blocklist -in pwned-passwords-sha1.txt -out breached.bloom
+//	go run ./cmd/blocklist -in common-passwords.txt -plain -out breached.bloom -fp 0.0001
+//
+// ไฟล์ input เป็น SHA-1 hex บรรทัดละ 1 ค่า (รองรับ "HASH:COUNT") หรือรหัสผ่านตรงๆ เมื่อใช้ -plain
+// นำไฟล์ที่ได้ไปตั้งค่า PASSWORD_BLOCKLIST_FILE และ PASSWORD_BLOCKLIST_FORMAT=bloom
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"auth-microservice/internal/password"
+)
+
+func main() {
+	in := flag.String("in", "", "input file (SHA-1 hex per line, or plaintext with -plain)")
+	out := flag.String("out", "breached.bloom", "output bloom filter file")
+	fp := flag.Float64("fp", 0.001, "target false positive rate")
+	plain := flag.Bool("plain", false, "input lines are plaintext passwords")
+	flag.Parse()
+
+	if *in == "" || *fp <= 0 || *fp >= 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	hashes, err := readHashes(*in, *plain)
+	if err != nil {
+		log.Fatalf("❌ Failed to read %s: %v", *in, err)
+	}
+
+	filter := password.NewBloomFilter(len(hashes), *fp)
+	for _, sum := range hashes {
+		filter.AddSHA1(sum)
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatalf("❌ Failed to create %s: %v", *out, err)
+	}
+	if _, err := filter.WriteTo(f); err != nil {
+		f.Close()
+		log.Fatalf("❌ Failed to write bloom filter: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatalf("❌ Failed to write bloom filter: %v", err)
+	}
+
+	fmt.Printf("✅ Wrote %d passwords to %s (false positive rate %g)\n", len(hashes), *out, *fp)
+}
+
+// readHashes - อ่าน SHA-1 ของทุกบรรทัดในไฟล์ (ข้ามบรรทัดว่างและ #)
+func readHashes(path string, plain bool) ([][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var hashes [][]byte
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if plain {
+			if text == "" {
+				continue
+			}
+			sum := sha1.Sum([]byte(text))
+			hashes = append(hashes, sum[:])
+			continue
+		}
+
+		text = strings.TrimSpace(text)
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		hash, _, _ := strings.Cut(text, ":")
+		sum, err := hex.DecodeString(hash)
+		if err != nil || len(sum) != sha1.Size {
+			return nil, fmt.Errorf("line %d: expected a 40 character SHA-1 hex", line)
+		}
+		hashes = append(hashes, sum)
+	}
+	return hashes, scanner.Err()
+}
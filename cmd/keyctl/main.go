@@ -0,0 +1,72 @@
+// keyctl - CLI สำหรับจัดการ signing keys ใน collection signing_keys
+//
+// Usage:
+//
+//	go run ./cmd/keyctl list
+//	go run ./cmd/keyctl rotate
+//
+// เหมาะสำหรับตั้งเป็น cron job เพื่อ rotate key ตามรอบ (replica ทุกตัวจะโหลด key ใหม่
+// ภายใน KEY_REFRESH_INTERVAL และ token เก่ายังใช้ได้จนหมดอายุ)
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"auth-microservice/internal/config"
+	"auth-microservice/pkg/db"
+	"auth-microservice/pkg/jwt"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	cfg := config.New()
+
+	mongoDB, err := db.NewMongoDB(cfg.MongoURI, cfg.DBName)
+	if err != nil {
+		log.Fatalf("❌ Failed to connect to MongoDB: %v", err)
+	}
+	defer mongoDB.Close()
+
+	keyStore := jwt.NewKeyStore(mongoDB.SigningKeys(), cfg.JWTAlgorithm, cfg.AccessTokenTTL+cfg.KeyRefreshInterval+cfg.TokenLeeway)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	switch os.Args[1] {
+	case "list":
+		// ไม่ต้องทำอะไรเพิ่ม - แสดงรายการด้านล่าง
+	case "rotate":
+		if err := keyStore.Rotate(ctx); err != nil {
+			log.Fatalf("❌ Failed to rotate signing keys: %v", err)
+		}
+		log.Println("🔄 Signing keys rotated")
+	default:
+		usage()
+	}
+
+	keys, err := keyStore.List(ctx)
+	if err != nil {
+		log.Fatalf("❌ Failed to list signing keys: %v", err)
+	}
+
+	fmt.Printf("%-45s %-6s %-8s %-25s %s\n", "KID", "ALG", "STATE", "CREATED", "EXPIRES")
+	for _, k := range keys {
+		expires := "-"
+		if k.ExpiresAt != nil {
+			expires = k.ExpiresAt.Format(time.RFC3339)
+		}
+		fmt.Printf("%-45s %-6s %-8s %-25s %s\n", k.KID, k.Algorithm, k.State, k.CreatedAt.Format(time.RFC3339), expires)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: keyctl <list|rotate>")
+	os.Exit(2)
+}
@@ -1,18 +1,36 @@
 package main
 
 import (
+	"context"
+	"expvar"
+	"fmt"
 	"log"
 	"net"
+	"net/http"
+	"os"
+	"time"
 
+	"auth-microservice/internal/audit"
 	"auth-microservice/internal/auth"
 	"auth-microservice/internal/config"
+	"auth-microservice/internal/group"
+	"auth-microservice/internal/mailer"
 	"auth-microservice/internal/middleware"
+	"auth-microservice/internal/passkey"
+	"auth-microservice/internal/password"
+	"auth-microservice/internal/ratelimit"
+	"auth-microservice/internal/rbac"
+	"auth-microservice/internal/revocation"
+	"auth-microservice/internal/session"
+	"auth-microservice/internal/tenant"
 	"auth-microservice/internal/user"
 	"auth-microservice/pkg/db"
 	"auth-microservice/pkg/jwt"
 	authProto "auth-microservice/proto/auth"
 	userProto "auth-microservice/proto/user"
 
+	"github.com/go-webauthn/webauthn/webauthn"
+	"golang.org/x/crypto/bcrypt"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 )
@@ -35,9 +53,44 @@ func main() {
 		log.Fatalf("❌ MongoDB connection test failed: %v", err)
 	}
 
+	// Ensure indexes (รวมถึง TTL index ของ blacklisted_tokens)
+	indexCtx, cancelIndex := context.WithTimeout(context.Background(), 10*time.Second)
+	if err := mongoDB.EnsureIndexes(indexCtx); err != nil {
+		log.Fatalf("❌ Failed to ensure MongoDB indexes: %v", err)
+	}
+	cancelIndex()
+	log.Println("🔍 MongoDB indexes ensured")
+
 	// Create JWT service
-	jwtService := jwt.NewJWTService(cfg.JWTSecret)
-	log.Println("🔐 JWT service initialized")
+	signingKey, err := loadSigningKey(cfg)
+	if err != nil {
+		log.Fatalf("❌ Failed to load JWT signing key: %v", err)
+	}
+	keyRing := jwt.NewKeyRing(signingKey)
+
+	// Key ring - โหลด signing keys จาก MongoDB (bootstrap ด้วย key จาก config ครั้งแรก)
+	// retired key ต้องตรวจสอบได้จนกว่า token ที่เซ็นโดย replica อื่นจะหมดอายุ
+	keyStore := jwt.NewKeyStore(mongoDB.SigningKeys(), cfg.JWTAlgorithm, cfg.AccessTokenTTL+cfg.KeyRefreshInterval+cfg.TokenLeeway)
+	keyCtx, cancelKey := context.WithTimeout(context.Background(), 10*time.Second)
+	if err := keyStore.EnsureIndexes(keyCtx); err != nil {
+		log.Fatalf("❌ Failed to ensure signing key indexes: %v", err)
+	}
+	if err := keyStore.Bootstrap(keyCtx, signingKey); err != nil {
+		log.Fatalf("❌ Failed to bootstrap signing keys: %v", err)
+	}
+	if err := keyStore.Load(keyCtx, keyRing); err != nil {
+		log.Fatalf("❌ Failed to load signing keys: %v", err)
+	}
+	cancelKey()
+
+	jwtService := jwt.NewJWTService(keyRing, jwt.TokenConfig{
+		AccessTTL:        cfg.AccessTokenTTL,
+		Issuer:           cfg.TokenIssuer,
+		Audience:         cfg.TokenAudience,
+		AllowedAudiences: cfg.AllowedAudiences,
+		Leeway:           cfg.TokenLeeway,
+	})
+	log.Printf("🔐 JWT service initialized (%s, kid: %s)", jwtService.Algorithm(), keyRing.Active().KID)
 
 	// Test JWT service
 	testToken, err := jwtService.GenerateToken("test-id", "test@example.com", "user")
@@ -57,22 +110,153 @@ func main() {
 	userRepo := user.NewRepository(mongoDB)
 	log.Println("📊 User repository initialized")
 
+	// Initialize token revocation store (blacklist + cache)
+	revocationStore := revocation.NewStore(mongoDB, cfg.RevocationCacheTTL)
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	go revocationStore.Watch(watchCtx)
+	go keyStore.Run(watchCtx, keyRing, cfg.KeyRefreshInterval, cfg.KeyRotationInterval)
+	log.Println("🚫 Token revocation store initialized")
+
+	// Initialize session registry
+	sessionStore := session.NewStore(mongoDB, cfg.SessionTouchInterval)
+	log.Println("📱 Session registry initialized")
+
+	// Initialize roles (สร้าง role admin และ user ถ้ายังไม่มี)
+	roleStore := rbac.NewStore(mongoDB, cfg.RoleCacheTTL)
+	roleCtx, cancelRole := context.WithTimeout(context.Background(), 10*time.Second)
+	if err := roleStore.EnsureDefaults(roleCtx); err != nil {
+		log.Fatalf("❌ Failed to ensure default roles: %v", err)
+	}
+	cancelRole()
+	log.Println("🛡️  Role store initialized")
+
+	// Initialize tenants (สร้าง tenant เริ่มต้นและย้าย user ที่ยังไม่มี tenant เข้า tenant นี้)
+	tenantStore := tenant.NewStore(mongoDB)
+	tenantCtx, cancelTenant := context.WithTimeout(context.Background(), 30*time.Second)
+	migrated, err := tenantStore.EnsureDefault(tenantCtx, cfg.DefaultTenant, "Default")
+	if err != nil {
+		log.Fatalf("❌ Failed to ensure default tenant: %v", err)
+	}
+	cancelTenant()
+	if migrated > 0 {
+		log.Printf("🏢 Assigned %d existing users to tenant %s", migrated, cfg.DefaultTenant)
+	}
+	log.Printf("🏢 Tenant store initialized (default: %s)", cfg.DefaultTenant)
+
+	// Initialize groups (roles ของ group ใช้ร่วมกับ role หลักตอนตรวจสิทธิ์)
+	groupStore := group.NewStore(mongoDB, cfg.GroupCacheTTL)
+	log.Println("👥 Group store initialized")
+
+	// Initialize WebAuthn (passkeys)
+	webAuthn, err := webauthn.New(&webauthn.Config{
+		RPID:          cfg.WebAuthnRPID,
+		RPDisplayName: cfg.WebAuthnRPName,
+		RPOrigins:     cfg.WebAuthnOrigins,
+	})
+	if err != nil {
+		log.Fatalf("❌ Invalid WebAuthn configuration: %v", err)
+	}
+	log.Printf("🔑 WebAuthn initialized (RP ID: %s)", cfg.WebAuthnRPID)
+
+	// Initialize mailer
+	mail, err := mailer.New(mailer.Config{
+		Driver:       cfg.MailDriver,
+		From:         cfg.MailFrom,
+		FileDir:      cfg.MailFileDir,
+		SMTPAddr:     cfg.SMTPAddr,
+		SMTPUsername: cfg.SMTPUsername,
+		SMTPPassword: cfg.SMTPPassword,
+	})
+	if err != nil {
+		log.Fatalf("❌ Invalid mail configuration: %v", err)
+	}
+	log.Printf("📧 Mailer initialized (driver: %s)", cfg.MailDriver)
+
+	// Initialize password policy
+	passwordPolicy, err := loadPasswordPolicy(cfg)
+	if err != nil {
+		log.Fatalf("❌ Failed to load password policy: %v", err)
+	}
+	log.Printf("🔏 Password policy initialized (min length: %d, blocklist: %t)", passwordPolicy.MinLength, passwordPolicy.Blocklist != nil)
+
+	passwordHasher, err := loadPasswordHasher(cfg)
+	if err != nil {
+		log.Fatalf("❌ Invalid password hashing configuration: %v", err)
+	}
+	passwordPool := password.NewPool(passwordHasher, password.PoolConfig{
+		Workers:   cfg.PasswordHashWorkers,
+		QueueSize: cfg.PasswordHashQueue,
+		Timeout:   cfg.PasswordHashTimeout,
+	})
+	defer passwordPool.Close()
+	expvar.Publish("password_hash_pool", expvar.Func(func() interface{} { return passwordPool.Stats() }))
+	log.Printf("🧂 Password hasher initialized (%s, workers: %d, queue: %d)", passwordHasher.Current().Name(), passwordPool.Stats().Workers, cfg.PasswordHashQueue)
+
 	// Initialize services
-	authService := auth.NewService(userRepo, jwtService, mongoDB)
+	authService := auth.NewService(auth.Dependencies{
+		UserRepo:    userRepo,
+		JWTService:  jwtService,
+		KeyStore:    keyStore,
+		Revocations: revocationStore,
+		Sessions:    sessionStore,
+		RateLimiter: ratelimit.NewLimiter(mongoDB),
+		WebAuthn:    webAuthn,
+		Passkeys:    passkey.NewStore(mongoDB),
+		Mailer:      mail,
+		Audit:       audit.NewLogger(mongoDB),
+		Roles:       roleStore,
+		Tenants:     tenantStore,
+		Groups:      groupStore,
+		Passwords:   passwordPool,
+		DB:          mongoDB,
+	}, auth.Options{
+		RefreshTokenTTL: cfg.RefreshTokenTTL,
+		LoginLimits: auth.LoginLimits{
+			PerIP:      rateLimitRule("login_ip", cfg.LoginRateLimitIP),
+			PerEmail:   rateLimitRule("login_email", cfg.LoginRateLimitEmail),
+			PerIPEmail: rateLimitRule("login_ip_email", cfg.LoginRateLimitIPEmail),
+		},
+		Lockout: auth.LockoutPolicy{
+			Threshold:    cfg.LockoutThreshold,
+			BaseDuration: cfg.LockoutBaseDuration,
+			MaxDuration:  cfg.LockoutMaxDuration,
+		},
+		MFAChallengeTTL: cfg.MFAChallengeTTL,
+		TOTPIssuer:      cfg.TOTPIssuer,
+
+		WebAuthnCeremonyTTL: cfg.WebAuthnCeremonyTTL,
+
+		RequireVerifiedEmail: cfg.RequireVerifiedEmail,
+		VerificationTokenTTL: cfg.VerificationTokenTTL,
+		PasswordResetTTL:     cfg.PasswordResetTTL,
+		AppBaseURL:           cfg.AppBaseURL,
+		EmailLimit:           rateLimitRule("email", cfg.EmailRateLimit),
+
+		PasswordPolicy:        passwordPolicy,
+		EnumerationProtection: cfg.EnumerationProtection,
+
+		DefaultTenant: cfg.DefaultTenant,
+	})
 	log.Println("🔐 Auth service initialized")
 
 	// Initialize handlers
 	authHandler := auth.NewHandler(authService)
-	userHandler := user.NewHandler(userRepo)
+	adminHandler := auth.NewAdminHandler(authService)
+	groupHandler := auth.NewGroupHandler(authService)
+	userHandler := user.NewHandler(userRepo, authService)
 	log.Println("🎯 gRPC handlers initialized")
 
 	// Initialize middleware
-	authInterceptor := middleware.AuthInterceptor(jwtService)
+	clientInfoInterceptor := middleware.ClientInfoInterceptor(cfg.TrustProxyHeaders)
+	methodRules := middleware.NewMethodRules()
+	authInterceptor := middleware.AuthInterceptor(jwtService, revocationStore, sessionStore, roleStore, groupStore, methodRules, cfg.ServiceClients)
 	loggingInterceptor := middleware.LoggingInterceptor()
 
 	// Create gRPC server with interceptors
 	server := grpc.NewServer(
 		grpc.ChainUnaryInterceptor(
+			clientInfoInterceptor,
 			loggingInterceptor,
 			authInterceptor,
 		),
@@ -80,13 +264,47 @@ func main() {
 
 	// Register services
 	authProto.RegisterAuthServiceServer(server, authHandler)
+	authProto.RegisterAdminServiceServer(server, adminHandler)
+	authProto.RegisterGroupServiceServer(server, groupHandler)
 	userProto.RegisterUserServiceServer(server, userHandler)
 	log.Println("📡 gRPC services registered")
 
+	// โหลดเงื่อนไขการเรียกของแต่ละ method จาก option (authz.authz) ใน proto
+	unannotated, err := methodRules.Load(server.GetServiceInfo())
+	if err != nil {
+		log.Fatalf("❌ Failed to load authz rules: %v", err)
+	}
+	for _, method := range unannotated {
+		log.Printf("⚠️  %s has no (authz) option - all calls will be denied", method)
+	}
+	log.Println("🛡️  Authz rules loaded from service descriptors")
+
 	// Enable reflection (สำหรับ grpcurl testing)
 	reflection.Register(server)
 	log.Println("🔍 gRPC reflection enabled")
 
+	// Start HTTP server สำหรับ JWKS
+	mux := http.NewServeMux()
+	mux.Handle("/.well-known/jwks.json", jwtService.JWKSHandler())
+	go func() {
+		log.Printf("🌐 HTTP server listening on port %s", cfg.HTTPPort)
+		if err := http.ListenAndServe(":"+cfg.HTTPPort, mux); err != nil {
+			log.Fatalf("❌ Failed to serve HTTP: %v", err)
+		}
+	}()
+
+	// Start HTTP server ภายในสำหรับ metrics (expvar มี cmdline และ memstats - แยกจาก port ของ JWKS)
+	if cfg.MetricsAddr != "" {
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("/debug/vars", expvar.Handler())
+		go func() {
+			log.Printf("📈 Metrics server listening on %s", cfg.MetricsAddr)
+			if err := http.ListenAndServe(cfg.MetricsAddr, metricsMux); err != nil {
+				log.Fatalf("❌ Failed to serve metrics: %v", err)
+			}
+		}()
+	}
+
 	// Start listening
 	listener, err := net.Listen("tcp", ":"+cfg.Port)
 	if err != nil {
@@ -96,18 +314,104 @@ func main() {
 	log.Printf("✅ Auth Microservice started successfully!")
 	log.Printf("🌐 gRPC server listening on port %s", cfg.Port)
 	log.Printf("🍃 MongoDB connected: %s", cfg.MongoURI)
-	log.Printf("📚 Collections: users, blacklisted_tokens, rate_limits")
+	log.Printf("📚 Collections: users, blacklisted_tokens, rate_limits, refresh_tokens, sessions, signing_keys, audit_events, roles, tenants, groups")
 	log.Printf("🎯 Ready for API development!")
 	log.Printf("")
 	log.Printf("📋 Available Services:")
-	log.Printf("   🔐 AuthService: Login, Logout, Register")
-	log.Printf("   👥 UserService: ListUsers, GetProfile, UpdateProfile, DeleteProfile")
+	log.Printf("   🔐 AuthService: Login, Logout, Register, Refresh, GetJWKS, RotateSigningKeys, Introspect, RevokeAllSessions, ListSessions, RevokeSession, UnlockAccount, EnrollTOTP, ConfirmTOTP, DisableTOTP, VerifyMFA, Begin/FinishPasskeyRegistration, Begin/FinishPasskeyLogin, VerifyEmail, ResendVerification, RequestPasswordReset, ResetPassword, ChangePassword, ListRoles, CreateRole, UpdateRole, DeleteRole")
+	log.Printf("   🔑 JWKS: http://localhost:%s/.well-known/jwks.json", cfg.HTTPPort)
+	if cfg.MetricsAddr != "" {
+		log.Printf("   📈 Metrics: http://%s/debug/vars", cfg.MetricsAddr)
+	}
+	log.Printf("   👥 UserService: ListUsers, GetProfile, UpdateProfile, DeleteProfile, GetMe, UpdateMe")
+	log.Printf("   🛡️  AdminService: SetRole, DeactivateUser, ReactivateUser, RestoreUser, ListDeletedUsers, ListTenants, CreateTenant")
+	log.Printf("   👥 GroupService: ListGroups, CreateGroup, RenameGroup, SetGroupRoles, DeleteGroup, AddGroupMember, RemoveGroupMember, ListGroupMembers, ListUserGroups")
 	log.Printf("")
 	log.Printf("🧪 Test Credentials:")
+	log.Printf("   🏢 Tenant: %s", cfg.DefaultTenant)
 	log.Printf("   📧 Email: admin@example.com")
 	log.Printf("   🔑 Password: password")
+	log.Printf("   🔐 AdminService/GroupService changes require MFA - enroll with EnrollTOTP + ConfirmTOTP, then log in again")
 
 	if err := server.Serve(listener); err != nil {
 		log.Fatalf("❌ Failed to serve: %v", err)
 	}
 }
+
+// loadSigningKey - สร้าง signing key ตาม config
+func loadSigningKey(cfg *config.Config) (*jwt.SigningKey, error) {
+	if cfg.JWTAlgorithm == jwt.AlgHS256 {
+		return jwt.NewHMACKey(cfg.JWTSecret), nil
+	}
+
+	if cfg.JWTPrivateKeyFile == "" {
+		// ไม่มี key file - สร้าง key ใหม่ (ถูกเก็บใน signing_keys ถ้ายังไม่มี active key)
+		log.Printf("⚠️  JWT_PRIVATE_KEY_FILE not set - generating new %s key", cfg.JWTAlgorithm)
+		return jwt.GenerateSigningKey(cfg.JWTAlgorithm)
+	}
+
+	pemData, err := os.ReadFile(cfg.JWTPrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return jwt.ParseSigningKey(cfg.JWTAlgorithm, pemData)
+}
+
+// loadPasswordPolicy - สร้าง password policy ตาม config (รวมถึงโหลด blocklist ถ้ามี)
+func loadPasswordPolicy(cfg *config.Config) (*password.Policy, error) {
+	policy := &password.Policy{
+		MinLength:            cfg.PasswordMinLength,
+		MaxLength:            cfg.PasswordMaxLength,
+		RequireUpper:         cfg.PasswordRequireUpper,
+		RequireLower:         cfg.PasswordRequireLower,
+		RequireDigit:         cfg.PasswordRequireDigit,
+		RequireSymbol:        cfg.PasswordRequireSymbol,
+		DisallowPersonalInfo: cfg.PasswordDisallowPersonalInfo,
+	}
+
+	if cfg.PasswordBlocklistFile != "" {
+		blocklist, err := password.LoadBlocklist(cfg.PasswordBlocklistFile, cfg.PasswordBlocklistFormat)
+		if err != nil {
+			return nil, err
+		}
+		policy.Blocklist = blocklist
+	}
+
+	return policy, nil
+}
+
+// loadPasswordHasher - เลือก algorithm สำหรับ hash รหัสผ่านใหม่ (อีก algorithm ใช้ตรวจ hash เดิม)
+func loadPasswordHasher(cfg *config.Config) (*password.Hasher, error) {
+	if cfg.Argon2Time < 1 || cfg.Argon2Parallelism < 1 || cfg.Argon2Parallelism > 255 || cfg.Argon2Memory < 8*cfg.Argon2Parallelism {
+		return nil, fmt.Errorf("invalid Argon2id parameters (memory: %d KiB, time: %d, parallelism: %d)", cfg.Argon2Memory, cfg.Argon2Time, cfg.Argon2Parallelism)
+	}
+	if cfg.BcryptCost < bcrypt.MinCost || cfg.BcryptCost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("invalid bcrypt cost %d", cfg.BcryptCost)
+	}
+
+	params := password.DefaultArgon2idParams()
+	params.Memory = uint32(cfg.Argon2Memory)
+	params.Time = uint32(cfg.Argon2Time)
+	params.Parallelism = uint8(cfg.Argon2Parallelism)
+
+	argon2id := password.NewArgon2id(params)
+	bcryptHasher := password.NewBcrypt(cfg.BcryptCost)
+
+	switch cfg.PasswordHashAlgorithm {
+	case argon2id.Name():
+		return password.NewHasher(argon2id, bcryptHasher), nil
+	case bcryptHasher.Name():
+		return password.NewHasher(bcryptHasher, argon2id), nil
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm %q", cfg.PasswordHashAlgorithm)
+	}
+}
+
+// rateLimitRule - แปลง rate limit จาก config เป็น rule ของ limiter
+func rateLimitRule(name string, limit config.RateLimit) ratelimit.Rule {
+	return ratelimit.Rule{
+		Name:   name,
+		Limit:  limit.Limit,
+		Window: limit.Window,
+	}
+}
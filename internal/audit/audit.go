@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"auth-microservice/internal/middleware"
+	"auth-microservice/pkg/db"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ประเภทของ audit event
+const (
+	EventPasswordChanged = "password.changed"
+
+	EventRoleCreated = "role.created"
+	EventRoleUpdated = "role.updated"
+	EventRoleDeleted = "role.deleted"
+
+	EventUserRoleChanged = "user.role_changed"
+	EventUserDeactivated = "user.deactivated"
+	EventUserReactivated = "user.reactivated"
+	EventUserRestored    = "user.restored"
+	EventUserDeleted     = "user.deleted"
+
+	EventTenantCreated = "tenant.created"
+
+	EventGroupCreated       = "group.created"
+	EventGroupRenamed       = "group.renamed"
+	EventGroupRolesChanged  = "group.roles_changed"
+	EventGroupDeleted       = "group.deleted"
+	EventGroupMemberAdded   = "group.member_added"
+	EventGroupMemberRemoved = "group.member_removed"
+)
+
+// Event - การกระทำที่ต้องเก็บหลักฐาน (ใคร ทำอะไร กับใคร จากที่ไหน)
+type Event struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Type      string             `bson:"type"`
+	ActorID   string             `bson:"actor_id,omitempty"`  // ผู้กระทำ (ว่าง = ระบบ หรือยังไม่ได้ login)
+	TargetID  string             `bson:"target_id,omitempty"` // user ที่ถูกกระทำ
+	IPAddress string             `bson:"ip_address,omitempty"`
+	UserAgent string             `bson:"user_agent,omitempty"`
+	Details   map[string]string  `bson:"details,omitempty"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
+
+// Logger - บันทึก audit events ลง collection audit_events
+type Logger struct {
+	db *db.MongoDB
+}
+
+func NewLogger(database *db.MongoDB) *Logger {
+	return &Logger{
+		db: database,
+	}
+}
+
+// Record - บันทึก event (เติม IP และ user agent ของผู้เรียกจาก context ให้อัตโนมัติ)
+func (l *Logger) Record(ctx context.Context, event Event) error {
+	client := middleware.ClientInfoFromContext(ctx)
+	if event.IPAddress == "" {
+		event.IPAddress = client.IP
+	}
+	if event.UserAgent == "" {
+		event.UserAgent = client.UserAgent
+	}
+
+	event.ID = primitive.NewObjectID()
+	event.CreatedAt = time.Now()
+
+	if _, err := l.db.AuditEvents().InsertOne(ctx, event); err != nil {
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"auth-microservice/pkg/db"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// errInvalidActionToken - token ไม่มีอยู่ หมดอายุ หรือถูกใช้ไปแล้ว
+var errInvalidActionToken = errors.New("invalid action token")
+
+// จุดประสงค์ของ action token
+const (
+	purposeVerifyEmail   = "verify_email"
+	purposeResetPassword = "reset_password"
+)
+
+// actionToken - token ใช้ครั้งเดียวที่ส่งทาง email (เก็บเฉพาะ hash)
+type actionToken struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	TokenHash string             `bson:"token_hash"`
+	Purpose   string             `bson:"purpose"`
+	UserID    string             `bson:"user_id"`
+	TenantID  string             `bson:"tenant_id,omitempty"` // ว่าง = ออกก่อนรองรับ tenant (ใช้ tenant เริ่มต้น)
+	Email     string             `bson:"email"`               // email ตอนออก token (ใช้ไม่ได้ถ้า user เปลี่ยน email แล้ว)
+	CreatedAt time.Time          `bson:"created_at"`
+	ExpiresAt time.Time          `bson:"expires_at"`
+}
+
+type actionTokenStore struct {
+	db *db.MongoDB
+}
+
+func newActionTokenStore(database *db.MongoDB) *actionTokenStore {
+	return &actionTokenStore{
+		db: database,
+	}
+}
+
+// issue - ออก token ใหม่ (token เดิมของ user ที่มีจุดประสงค์เดียวกันจะใช้ไม่ได้อีก)
+func (r *actionTokenStore) issue(ctx context.Context, purpose, userID, tenantID, email string, ttl time.Duration) (string, error) {
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := r.db.ActionTokens().DeleteMany(ctx, bson.M{"user_id": userID, "purpose": purpose}); err != nil {
+		return "", fmt.Errorf("failed to invalidate previous tokens: %w", err)
+	}
+
+	now := time.Now()
+	doc := actionToken{
+		ID:        primitive.NewObjectID(),
+		TokenHash: hashOpaqueToken(token),
+		Purpose:   purpose,
+		UserID:    userID,
+		TenantID:  tenantID,
+		Email:     email,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+
+	if _, err := r.db.ActionTokens().InsertOne(ctx, doc); err != nil {
+		return "", fmt.Errorf("failed to store token: %w", err)
+	}
+
+	return token, nil
+}
+
+// lookup - ดึงข้อมูลของ token ที่ยังใช้ได้โดยไม่ใช้ token
+func (r *actionTokenStore) lookup(ctx context.Context, purpose, token string) (*actionToken, error) {
+	var doc actionToken
+	err := r.db.ActionTokens().FindOne(ctx, actionTokenFilter(purpose, token)).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errInvalidActionToken
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return &doc, nil
+}
+
+// consume - ใช้ token (ลบทิ้งแบบ atomic) และคืนข้อมูลของ token
+func (r *actionTokenStore) consume(ctx context.Context, purpose, token string) (*actionToken, error) {
+	var doc actionToken
+	err := r.db.ActionTokens().FindOneAndDelete(ctx, actionTokenFilter(purpose, token)).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errInvalidActionToken
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return &doc, nil
+}
+
+// actionTokenFilter - filter ของ token ที่ยังไม่หมดอายุ
+func actionTokenFilter(purpose, token string) bson.M {
+	return bson.M{
+		"token_hash": hashOpaqueToken(token),
+		"purpose":    purpose,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}
+}
@@ -0,0 +1,287 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"auth-microservice/internal/audit"
+	"auth-microservice/internal/models"
+	"auth-microservice/internal/rbac"
+	"auth-microservice/internal/user"
+)
+
+// maxDeactivationReasonLength - ความยาวสูงสุดของเหตุผลที่ระงับบัญชี
+const maxDeactivationReasonLength = 500
+
+// SetRole - เปลี่ยน role ของ user (access token เดิมใช้ไม่ได้ทันที ได้ role ใหม่เมื่อ refresh)
+// เปลี่ยน role ของตัวเองไม่ได้ เพื่อไม่ให้ admin คนสุดท้ายล็อคตัวเองออกจากระบบ
+func (s *Service) SetRole(ctx context.Context, actorID, userID, role string) (*SetRoleResponse, error) {
+	if userID == actorID {
+		return &SetRoleResponse{
+			Success: false,
+			Message: "You cannot change your own role",
+		}, nil
+	}
+
+	role = strings.TrimSpace(role)
+	if _, err := s.roles.Get(ctx, role); err != nil {
+		if err == rbac.ErrRoleNotFound {
+			return &SetRoleResponse{
+				Success: false,
+				Message: "Role not found",
+			}, nil
+		}
+		return &SetRoleResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	if !s.fromDefaultTenant(ctx) {
+		global, err := s.grantsGlobalRights(ctx, role)
+		if err != nil {
+			return &SetRoleResponse{
+				Success: false,
+				Message: "Internal server error",
+			}, err
+		}
+		if global {
+			return &SetRoleResponse{
+				Success: false,
+				Message: globalRolesDefaultTenantOnlyMessage,
+			}, nil
+		}
+	}
+
+	user, err := s.userRepo.GetByIDAnyState(ctx, userID)
+	if err != nil || user.DeletedAt != nil {
+		return &SetRoleResponse{
+			Success: false,
+			Message: "User not found",
+		}, nil
+	}
+
+	if user.Role == role {
+		return &SetRoleResponse{
+			Success: true,
+			Message: "User already has this role",
+		}, nil
+	}
+
+	if err := s.userRepo.SetRole(ctx, userID, role); err != nil {
+		log.Printf("Failed to set role for user %s: %v", user.Email, err)
+		return &SetRoleResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+	s.revocations.ForgetUser(userID)
+
+	s.recordAudit(ctx, audit.Event{
+		Type:     audit.EventUserRoleChanged,
+		ActorID:  actorID,
+		TargetID: userID,
+		Details: map[string]string{
+			"from": user.Role,
+			"to":   role,
+		},
+	})
+	log.Printf("Role changed for user %s: %s -> %s", user.Email, user.Role, role)
+
+	return &SetRoleResponse{
+		Success: true,
+		Message: "Role updated successfully",
+	}, nil
+}
+
+// DeactivateUser - ระงับบัญชีพร้อมเหตุผล และยกเลิกทุก session ของ user
+func (s *Service) DeactivateUser(ctx context.Context, actorID, userID, reason string) (*DeactivateUserResponse, error) {
+	if userID == actorID {
+		return &DeactivateUserResponse{
+			Success: false,
+			Message: "You cannot deactivate your own account",
+		}, nil
+	}
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return &DeactivateUserResponse{
+			Success: false,
+			Message: "Reason is required",
+		}, nil
+	}
+	if utf8.RuneCountInString(reason) > maxDeactivationReasonLength {
+		return &DeactivateUserResponse{
+			Success: false,
+			Message: "Reason is too long",
+		}, nil
+	}
+
+	user, err := s.userRepo.GetByIDAnyState(ctx, userID)
+	if err != nil || user.DeletedAt != nil {
+		return &DeactivateUserResponse{
+			Success: false,
+			Message: "User not found",
+		}, nil
+	}
+	if !user.IsActive {
+		return &DeactivateUserResponse{
+			Success: false,
+			Message: "User is already deactivated",
+		}, nil
+	}
+
+	if err := s.userRepo.Deactivate(ctx, userID, reason, actorID); err != nil {
+		log.Printf("Failed to deactivate user %s: %v", user.Email, err)
+		return &DeactivateUserResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	// token_version ถูกเพิ่มแล้วใน Deactivate - เหลือ refresh token และ session
+	if err := s.revokeAllSessions(ctx, userID); err != nil {
+		log.Printf("Failed to revoke sessions of deactivated user %s: %v", user.Email, err)
+		return &DeactivateUserResponse{
+			Success: false,
+			Message: "User deactivated, but failed to revoke sessions",
+		}, err
+	}
+
+	s.recordAudit(ctx, audit.Event{
+		Type:     audit.EventUserDeactivated,
+		ActorID:  actorID,
+		TargetID: userID,
+		Details: map[string]string{
+			"reason": reason,
+		},
+	})
+	log.Printf("User deactivated: %s (reason: %s)", user.Email, reason)
+
+	return &DeactivateUserResponse{
+		Success: true,
+		Message: "User deactivated successfully",
+	}, nil
+}
+
+// ReactivateUser - เปิดใช้งานบัญชีที่ถูกระงับ
+func (s *Service) ReactivateUser(ctx context.Context, actorID, userID string) (*ReactivateUserResponse, error) {
+	target, err := s.userRepo.GetByIDAnyState(ctx, userID)
+	if err != nil || target.DeletedAt != nil {
+		return &ReactivateUserResponse{
+			Success: false,
+			Message: "User not found",
+		}, nil
+	}
+	if target.IsActive {
+		return &ReactivateUserResponse{
+			Success: false,
+			Message: "User is not deactivated",
+		}, nil
+	}
+
+	if err := s.userRepo.Reactivate(ctx, userID); err != nil {
+		if errors.Is(err, user.ErrEmailExists) {
+			return &ReactivateUserResponse{
+				Success: false,
+				Message: "Email is already used by another account",
+			}, nil
+		}
+		log.Printf("Failed to reactivate user %s: %v", target.Email, err)
+		return &ReactivateUserResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+	s.revocations.ForgetUser(userID)
+
+	s.recordAudit(ctx, audit.Event{
+		Type:     audit.EventUserReactivated,
+		ActorID:  actorID,
+		TargetID: userID,
+		Details: map[string]string{
+			"previous_reason": target.DeactivationReason,
+		},
+	})
+	log.Printf("User reactivated: %s", target.Email)
+
+	return &ReactivateUserResponse{
+		Success: true,
+		Message: "User reactivated successfully",
+	}, nil
+}
+
+// DeleteUser - ลบบัญชี (soft delete) และยกเลิกทุก session และ refresh token
+// ไม่เช่นนั้น refresh token เดิมจะกลับมาใช้ได้เมื่อบัญชีถูกกู้คืนด้วย RestoreUser
+func (s *Service) DeleteUser(ctx context.Context, actorID, userID string) error {
+	if err := s.userRepo.SoftDelete(ctx, userID); err != nil {
+		return err
+	}
+
+	// token_version ถูกเพิ่มแล้วใน SoftDelete - เหลือ refresh token และ session
+	if err := s.revokeAllSessions(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke sessions of deleted user: %w", err)
+	}
+
+	s.recordAudit(ctx, audit.Event{
+		Type:     audit.EventUserDeleted,
+		ActorID:  actorID,
+		TargetID: userID,
+	})
+	log.Printf("User deleted: %s", userID)
+	return nil
+}
+
+// RestoreUser - กู้คืนบัญชีที่ถูกลบ (ใช้ไม่ได้ถ้า email ถูกบัญชีอื่นใช้ไปแล้ว)
+func (s *Service) RestoreUser(ctx context.Context, actorID, userID string) (*RestoreUserResponse, error) {
+	target, err := s.userRepo.GetByIDAnyState(ctx, userID)
+	if err != nil || target.DeletedAt == nil {
+		return &RestoreUserResponse{
+			Success: false,
+			Message: "Deleted user not found",
+		}, nil
+	}
+
+	if err := s.userRepo.Restore(ctx, userID); err != nil {
+		if errors.Is(err, user.ErrEmailExists) {
+			return &RestoreUserResponse{
+				Success: false,
+				Message: "Email is already used by another account",
+			}, nil
+		}
+		log.Printf("Failed to restore user %s: %v", target.Email, err)
+		return &RestoreUserResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+	s.revocations.ForgetUser(userID)
+
+	s.recordAudit(ctx, audit.Event{
+		Type:     audit.EventUserRestored,
+		ActorID:  actorID,
+		TargetID: userID,
+		Details: map[string]string{
+			"deleted_at": target.DeletedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		},
+	})
+	log.Printf("User restored: %s", target.Email)
+
+	message := "User restored successfully"
+	if target.DeactivatedAt != nil {
+		message = "User restored, but remains deactivated"
+	}
+	return &RestoreUserResponse{
+		Success: true,
+		Message: message,
+	}, nil
+}
+
+// ListDeletedUsers - รายการบัญชีที่ถูกลบ (สำหรับเลือกกู้คืน)
+func (s *Service) ListDeletedUsers(ctx context.Context, skip, limit int) ([]*models.User, int64, error) {
+	return s.userRepo.ListDeleted(ctx, skip, limit)
+}
@@ -0,0 +1,246 @@
+package auth
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"auth-microservice/internal/middleware"
+	"auth-microservice/internal/tenant"
+	"auth-microservice/proto/auth"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AdminHandler - gRPC handler สำหรับ User Administration และ tenants (สิทธิ์ที่ต้องมีกำหนดใน proto)
+type AdminHandler struct {
+	auth.UnimplementedAdminServiceServer
+	service *Service
+}
+
+// NewAdminHandler - สร้าง admin handler ใหม่
+func NewAdminHandler(service *Service) *AdminHandler {
+	return &AdminHandler{
+		service: service,
+	}
+}
+
+// SetRole - gRPC handler สำหรับเปลี่ยน role ของ user
+func (h *AdminHandler) SetRole(ctx context.Context, req *auth.SetRoleRequest) (*auth.SetRoleResponse, error) {
+	log.Printf("🛡️  SetRole request for user: %s (role: %s)", req.UserId, req.Role)
+
+	actorID, _ := middleware.UserIDFromContext(ctx)
+	result, err := h.service.SetRole(ctx, actorID, req.UserId, req.Role)
+	if err != nil {
+		log.Printf("❌ SetRole service error: %v", err)
+		return &auth.SetRoleResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	if result.Success {
+		log.Printf("✅ SetRole successful for user: %s", req.UserId)
+	} else {
+		log.Printf("❌ SetRole failed for user: %s - %s", req.UserId, result.Message)
+	}
+
+	return &auth.SetRoleResponse{
+		Success: result.Success,
+		Message: result.Message,
+	}, nil
+}
+
+// DeactivateUser - gRPC handler สำหรับระงับบัญชี
+func (h *AdminHandler) DeactivateUser(ctx context.Context, req *auth.DeactivateUserRequest) (*auth.DeactivateUserResponse, error) {
+	log.Printf("⛔ DeactivateUser request for user: %s", req.UserId)
+
+	actorID, _ := middleware.UserIDFromContext(ctx)
+	result, err := h.service.DeactivateUser(ctx, actorID, req.UserId, req.Reason)
+	if err != nil {
+		log.Printf("❌ DeactivateUser service error: %v", err)
+		return &auth.DeactivateUserResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	if result.Success {
+		log.Printf("✅ DeactivateUser successful for user: %s", req.UserId)
+	} else {
+		log.Printf("❌ DeactivateUser failed for user: %s - %s", req.UserId, result.Message)
+	}
+
+	return &auth.DeactivateUserResponse{
+		Success: result.Success,
+		Message: result.Message,
+	}, nil
+}
+
+// ReactivateUser - gRPC handler สำหรับเปิดใช้งานบัญชีที่ถูกระงับ
+func (h *AdminHandler) ReactivateUser(ctx context.Context, req *auth.ReactivateUserRequest) (*auth.ReactivateUserResponse, error) {
+	log.Printf("♻️  ReactivateUser request for user: %s", req.UserId)
+
+	actorID, _ := middleware.UserIDFromContext(ctx)
+	result, err := h.service.ReactivateUser(ctx, actorID, req.UserId)
+	if err != nil {
+		log.Printf("❌ ReactivateUser service error: %v", err)
+		return &auth.ReactivateUserResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	if result.Success {
+		log.Printf("✅ ReactivateUser successful for user: %s", req.UserId)
+	} else {
+		log.Printf("❌ ReactivateUser failed for user: %s - %s", req.UserId, result.Message)
+	}
+
+	return &auth.ReactivateUserResponse{
+		Success: result.Success,
+		Message: result.Message,
+	}, nil
+}
+
+// RestoreUser - gRPC handler สำหรับกู้คืนบัญชีที่ถูกลบ
+func (h *AdminHandler) RestoreUser(ctx context.Context, req *auth.RestoreUserRequest) (*auth.RestoreUserResponse, error) {
+	log.Printf("♻️  RestoreUser request for user: %s", req.UserId)
+
+	actorID, _ := middleware.UserIDFromContext(ctx)
+	result, err := h.service.RestoreUser(ctx, actorID, req.UserId)
+	if err != nil {
+		log.Printf("❌ RestoreUser service error: %v", err)
+		return &auth.RestoreUserResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	if result.Success {
+		log.Printf("✅ RestoreUser successful for user: %s", req.UserId)
+	} else {
+		log.Printf("❌ RestoreUser failed for user: %s - %s", req.UserId, result.Message)
+	}
+
+	return &auth.RestoreUserResponse{
+		Success: result.Success,
+		Message: result.Message,
+	}, nil
+}
+
+// ListDeletedUsers - gRPC handler สำหรับแสดงรายการบัญชีที่ถูกลบ
+func (h *AdminHandler) ListDeletedUsers(ctx context.Context, req *auth.ListDeletedUsersRequest) (*auth.ListDeletedUsersResponse, error) {
+	log.Printf("🗑️  ListDeletedUsers request - Page: %d, Limit: %d", req.Page, req.Limit)
+
+	page := int(req.Page)
+	limit := int(req.Limit)
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	users, total, err := h.service.ListDeletedUsers(ctx, (page-1)*limit, limit)
+	if err != nil {
+		log.Printf("❌ ListDeletedUsers service error: %v", err)
+		return nil, status.Errorf(codes.Internal, "Failed to list deleted users")
+	}
+
+	protoUsers := make([]*auth.DeletedUser, 0, len(users))
+	for _, u := range users {
+		protoUser := &auth.DeletedUser{
+			Id:        u.ID.Hex(),
+			Email:     u.Email,
+			FirstName: u.FirstName,
+			LastName:  u.LastName,
+			Role:      u.Role,
+		}
+		if u.DeletedAt != nil {
+			protoUser.DeletedAt = u.DeletedAt.Format(time.RFC3339)
+		}
+		protoUsers = append(protoUsers, protoUser)
+	}
+
+	log.Printf("✅ ListDeletedUsers successful - Found %d users (Total: %d)", len(protoUsers), total)
+
+	return &auth.ListDeletedUsersResponse{
+		Users:      protoUsers,
+		Total:      int32(total),
+		Page:       int32(page),
+		Limit:      int32(limit),
+		TotalPages: int32((int(total) + limit - 1) / limit),
+	}, nil
+}
+
+// ListTenants - gRPC handler สำหรับแสดง tenant ทั้งหมด (ต้องมีสิทธิ์ tenants:manage)
+func (h *AdminHandler) ListTenants(ctx context.Context, req *auth.ListTenantsRequest) (*auth.ListTenantsResponse, error) {
+	log.Printf("🏢 ListTenants request received")
+
+	tenants, err := h.service.ListTenants(ctx)
+	if isStatusError(err) {
+		log.Printf("❌ ListTenants denied: %v", err)
+		return nil, err
+	}
+	if err != nil {
+		log.Printf("❌ ListTenants service error: %v", err)
+		return nil, status.Errorf(codes.Internal, "Failed to list tenants")
+	}
+
+	protoTenants := make([]*auth.Tenant, 0, len(tenants))
+	for i := range tenants {
+		protoTenants = append(protoTenants, toProtoTenant(&tenants[i]))
+	}
+
+	log.Printf("✅ ListTenants successful - Found %d tenants", len(protoTenants))
+	return &auth.ListTenantsResponse{
+		Tenants: protoTenants,
+	}, nil
+}
+
+// CreateTenant - gRPC handler สำหรับสร้าง tenant (ต้องมีสิทธิ์ tenants:manage)
+func (h *AdminHandler) CreateTenant(ctx context.Context, req *auth.CreateTenantRequest) (*auth.TenantResponse, error) {
+	log.Printf("🏢 CreateTenant request for tenant: %s", req.Id)
+
+	actorID, _ := middleware.UserIDFromContext(ctx)
+	result, err := h.service.CreateTenant(ctx, actorID, req.Id, req.Name, TenantAdmin{
+		Email:     req.AdminEmail,
+		FirstName: req.AdminFirstName,
+		LastName:  req.AdminLastName,
+	})
+	if err != nil {
+		log.Printf("❌ CreateTenant service error: %v", err)
+		return &auth.TenantResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	if !result.Success {
+		log.Printf("❌ CreateTenant failed for tenant: %s - %s", req.Id, result.Message)
+		return &auth.TenantResponse{
+			Success: result.Success,
+			Message: result.Message,
+		}, nil
+	}
+
+	log.Printf("✅ CreateTenant successful for tenant: %s", req.Id)
+	return &auth.TenantResponse{
+		Success:     result.Success,
+		Message:     result.Message,
+		Tenant:      toProtoTenant(result.Tenant),
+		AdminUserId: result.AdminUserID,
+	}, nil
+}
+
+func toProtoTenant(t *tenant.Tenant) *auth.Tenant {
+	return &auth.Tenant{
+		Id:        t.ID,
+		Name:      t.Name,
+		IsActive:  t.IsActive,
+		CreatedAt: t.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"auth-microservice/internal/audit"
+	"auth-microservice/internal/session"
+)
+
+// ChangePassword - เปลี่ยนรหัสผ่านของผู้เรียก (ต้องยืนยันรหัสผ่านปัจจุบัน)
+// signOutOthers = ยกเลิกทุก session อื่นยกเว้น session ที่ใช้เรียก API นี้
+func (s *Service) ChangePassword(ctx context.Context, userID, currentSessionID, currentPassword, newPassword string, signOutOthers bool) (*ChangePasswordResponse, error) {
+	if currentPassword == "" {
+		return &ChangePasswordResponse{
+			Success: false,
+			Message: "Current password is required",
+		}, nil
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return &ChangePasswordResponse{
+			Success: false,
+			Message: "User not found",
+		}, nil
+	}
+
+	if now := time.Now(); user.IsLocked(now) {
+		return &ChangePasswordResponse{
+			Success: false,
+			Message: "Account is temporarily locked. Please try again later.",
+		}, rateLimitError(ctx, "Account is temporarily locked. Please try again later.", user.LockedUntil.Sub(now))
+	}
+
+	// นับรวมกับ login ผิด เพื่อไม่ให้ใช้ session ที่ถูกขโมยเดารหัสผ่านได้เรื่อยๆ
+	ok, err := user.CheckPassword(ctx, s.passwords, currentPassword)
+	if err != nil {
+		log.Printf("ChangePassword failed - could not check password for %s: %v", user.Email, err)
+		message, err := hashingFailure(ctx, err)
+		return &ChangePasswordResponse{
+			Success: false,
+			Message: message,
+		}, err
+	}
+	if !ok {
+		log.Printf("ChangePassword failed - invalid current password: %s", user.Email)
+		s.recordFailedLogin(ctx, user)
+		return &ChangePasswordResponse{
+			Success: false,
+			Message: "Current password is incorrect",
+		}, nil
+	}
+
+	if violations := s.passwordViolations("new_password", newPassword, user.Email, user.FirstName, user.LastName); len(violations) > 0 {
+		return &ChangePasswordResponse{
+			Success: false,
+			Message: violations[0].Description,
+		}, invalidArgumentError(violations)
+	}
+
+	if newPassword == currentPassword {
+		return &ChangePasswordResponse{
+			Success: false,
+			Message: "New password must be different from the current password",
+		}, nil
+	}
+
+	if err := user.HashPassword(ctx, s.passwords, newPassword); err != nil {
+		log.Printf("Failed to hash password: %v", err)
+		message, err := hashingFailure(ctx, err)
+		return &ChangePasswordResponse{
+			Success: false,
+			Message: message,
+		}, err
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, userID, user.PasswordHash); err != nil {
+		log.Printf("Failed to change password for user %s: %v", user.Email, err)
+		return &ChangePasswordResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	var signedOut int
+	if signOutOthers {
+		signedOut, err = s.endOtherSessions(ctx, userID, currentSessionID)
+		if err != nil {
+			log.Printf("Failed to sign out other sessions for user %s: %v", user.Email, err)
+			return &ChangePasswordResponse{
+				Success: false,
+				Message: "Password changed, but failed to sign out other sessions",
+			}, err
+		}
+	}
+
+	s.recordAudit(ctx, audit.Event{
+		Type:     audit.EventPasswordChanged,
+		ActorID:  userID,
+		TargetID: userID,
+		Details: map[string]string{
+			"sign_out_others":  strconv.FormatBool(signOutOthers),
+			"signed_out_count": strconv.Itoa(signedOut),
+		},
+	})
+	s.notifyPasswordChanged(ctx, user, signOutOthers)
+
+	log.Printf("Password changed for user: %s", user.Email)
+
+	return &ChangePasswordResponse{
+		Success:           true,
+		Message:           "Password changed successfully",
+		SignedOutSessions: int32(signedOut),
+	}, nil
+}
+
+// endOtherSessions - ยกเลิกทุก session ของ user ยกเว้น keepSessionID
+func (s *Service) endOtherSessions(ctx context.Context, userID, keepSessionID string) (int, error) {
+	sessions, err := s.sessions.ListByUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	for _, sess := range sessions {
+		sessionID := sess.ID.Hex()
+		if sessionID == keepSessionID {
+			continue
+		}
+		if err := s.endSession(ctx, userID, sessionID); err != nil && err != session.ErrNotFound {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// recordAudit - บันทึก audit event (บันทึกไม่สำเร็จไม่ทำให้ request ล้มเหลว)
+func (s *Service) recordAudit(ctx context.Context, event audit.Event) {
+	if err := s.audit.Record(ctx, event); err != nil {
+		log.Printf("⚠️  %v (type: %s, target: %s)", err, event.Type, event.TargetID)
+	}
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"auth-microservice/internal/mailer"
+	"auth-microservice/internal/models"
+)
+
+// dummyPassword - รหัสผ่านที่ใช้สร้าง hash สำหรับเทียบเมื่อไม่พบ user (ค่าไม่สำคัญ)
+const dummyPassword = "enumeration-protection-dummy-password"
+
+// registrationAcceptedMessage - ข้อความเดียวกันทั้งสมัครสำเร็จและ email ซ้ำ (โหมด EnumerationProtection)
+const registrationAcceptedMessage = "Registration received. Please check your email to continue."
+
+// dummyPasswordCheck - ตรวจรหัสผ่านกับ hash หลอก เพื่อให้เวลาตอบเท่ากับกรณีที่มี user
+// error เหมือนกับการตรวจรหัสผ่านจริง (เช่นคิว hash เต็ม) เพื่อไม่ให้ต่างกัน
+func (s *Service) dummyPasswordCheck(ctx context.Context, password string) error {
+	if s.dummyHash == "" {
+		return nil
+	}
+	_, err := s.passwords.Verify(ctx, s.dummyHash, password)
+	return err
+}
+
+// notifyRegistrationAttempt - แจ้งเจ้าของ email ว่ามีคนพยายามสมัครด้วย email นี้ (แทนการตอบว่า email ซ้ำ)
+func (s *Service) notifyRegistrationAttempt(ctx context.Context, user *models.User) {
+	err := s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Someone tried to create an account with your email",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone tried to create a new account using this email address, but you already have an account.\n\n"+
+			"If this was you, sign in with your existing password or reset it here:\n\n%s\n\n"+
+			"If this was not you, you can ignore this email.\n",
+			user.FirstName, s.opts.AppBaseURL+"/forgot-password"),
+	})
+	if err != nil {
+		log.Printf("Failed to send registration notice to %s: %v", user.Email, err)
+	}
+}
@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"auth-microservice/internal/models"
+	"auth-microservice/internal/password"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// enumerationAttempts - จำนวนครั้งที่วัดเวลาต่อกรณี (ลดผลของ scheduler)
+const enumerationAttempts = 3
+
+// hashDelay - เวลาที่ slowAlgorithm ใช้ต่อการ hash/ตรวจ 1 ครั้ง
+const hashDelay = 25 * time.Millisecond
+
+type enumerationResult struct {
+	success  bool
+	message  string
+	userID   string
+	err      error
+	elapsed  time.Duration
+	hashes   int32
+	verifies int32
+	mails    int
+}
+
+func TestLoginEnumerationProtection(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	const email = "alice@example.com"
+
+	login := func(mt *mtest.T, existing bool) enumerationResult {
+		algorithm := newSlowAlgorithm(hashDelay)
+		pool := password.NewPool(password.NewHasher(algorithm), password.PoolConfig{})
+		defer pool.Close()
+
+		s := newTestService(mt, Dependencies{Passwords: pool}, Options{EnumerationProtection: true})
+		account := testUser(mt, pool, email, "correct-password")
+		failed := *account
+		failed.FailedLoginAttempts = 1
+
+		for i := 0; i < enumerationAttempts; i++ {
+			if existing {
+				mt.AddMockResponses(
+					cursorResponse(mt, "tenants", tenantDoc(testTenant)),
+					cursorResponse(mt, "users", toDoc(account)),
+					findAndModifyResponse(toDoc(&failed)),
+				)
+			} else {
+				mt.AddMockResponses(
+					cursorResponse(mt, "tenants", tenantDoc(testTenant)),
+					cursorResponse(mt, "users"),
+				)
+			}
+		}
+
+		algorithm.reset()
+		var result enumerationResult
+		start := time.Now()
+		for i := 0; i < enumerationAttempts; i++ {
+			resp, err := s.Login(context.Background(), "", email, "wrong-password", "")
+			result.success, result.message, result.err = resp.Success, resp.Message, err
+		}
+		result.elapsed = time.Since(start)
+		result.hashes = algorithm.hashes.Load()
+		result.verifies = algorithm.verifies.Load()
+		return result
+	}
+
+	var existing, unknown enumerationResult
+	mt.Run("existing account", func(mt *mtest.T) { existing = login(mt, true) })
+	mt.Run("unknown account", func(mt *mtest.T) { unknown = login(mt, false) })
+
+	if existing.err != nil || unknown.err != nil {
+		t.Fatalf("unexpected errors: existing=%v unknown=%v", existing.err, unknown.err)
+	}
+	if existing.success || unknown.success || existing.message != unknown.message {
+		t.Errorf("responses differ: existing=(%t, %q) unknown=(%t, %q)",
+			existing.success, existing.message, unknown.success, unknown.message)
+	}
+	if existing.verifies != enumerationAttempts || unknown.verifies != enumerationAttempts {
+		t.Errorf("password checks differ: existing=%d unknown=%d, want %d each",
+			existing.verifies, unknown.verifies, enumerationAttempts)
+	}
+	assertSimilarTiming(t, existing.elapsed, unknown.elapsed)
+}
+
+func TestRegisterEnumerationProtection(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	req := &RegisterRequest{
+		Email:     "alice@example.com",
+		Password:  "Vq7!mountain-lantern",
+		FirstName: "Test",
+		LastName:  "User",
+	}
+
+	register := func(mt *mtest.T, responses func(existing *models.User) []bson.D) enumerationResult {
+		algorithm := newSlowAlgorithm(hashDelay)
+		pool := password.NewPool(password.NewHasher(algorithm), password.PoolConfig{})
+		defer pool.Close()
+
+		mail := &recordingMailer{}
+		s := newTestService(mt, Dependencies{Passwords: pool, Mailer: mail}, Options{EnumerationProtection: true})
+		existing := testUser(mt, pool, req.Email, "another-password")
+
+		for i := 0; i < enumerationAttempts; i++ {
+			mt.AddMockResponses(responses(existing)...)
+		}
+
+		algorithm.reset()
+		var result enumerationResult
+		start := time.Now()
+		for i := 0; i < enumerationAttempts; i++ {
+			resp, err := s.Register(context.Background(), req)
+			if err != nil {
+				result.err = err
+				break
+			}
+			result.success, result.message, result.userID = resp.Success, resp.Message, resp.UserID
+		}
+		result.elapsed = time.Since(start)
+		result.hashes = algorithm.hashes.Load()
+		result.mails = len(mail.messages())
+		for _, msg := range mail.messages() {
+			if msg.To != req.Email {
+				mt.Errorf("email sent to %q, want %q", msg.To, req.Email)
+			}
+		}
+		return result
+	}
+
+	var created, duplicate, inactive enumerationResult
+	mt.Run("new account", func(mt *mtest.T) {
+		created = register(mt, func(*models.User) []bson.D {
+			return []bson.D{
+				cursorResponse(mt, "tenants", tenantDoc(testTenant)),
+				cursorResponse(mt, "users"),
+				mtest.CreateSuccessResponse(), // users.insert
+				mtest.CreateSuccessResponse(), // action_tokens.delete
+				mtest.CreateSuccessResponse(), // action_tokens.insert
+			}
+		})
+	})
+	mt.Run("existing account", func(mt *mtest.T) {
+		duplicate = register(mt, func(existing *models.User) []bson.D {
+			return []bson.D{
+				cursorResponse(mt, "tenants", tenantDoc(testTenant)),
+				cursorResponse(mt, "users", toDoc(existing)),
+			}
+		})
+	})
+	mt.Run("deactivated account", func(mt *mtest.T) {
+		inactive = register(mt, func(*models.User) []bson.D {
+			return []bson.D{
+				cursorResponse(mt, "tenants", tenantDoc(testTenant)),
+				cursorResponse(mt, "users"),
+				mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "E11000 duplicate key error"}),
+			}
+		})
+	})
+
+	for name, result := range map[string]enumerationResult{"new": created, "existing": duplicate, "deactivated": inactive} {
+		if result.err != nil {
+			t.Fatalf("%s account: unexpected error: %v", name, result.err)
+		}
+		if !result.success || result.message != registrationAcceptedMessage || result.userID != "" {
+			t.Errorf("%s account: got (%t, %q, %q), want the generic accepted response",
+				name, result.success, result.message, result.userID)
+		}
+		if result.hashes != enumerationAttempts {
+			t.Errorf("%s account: %d password hashes, want %d", name, result.hashes, enumerationAttempts)
+		}
+	}
+
+	// สมัครใหม่ได้ลิงก์ยืนยัน email ซ้ำได้คำเตือน - ผู้สมัครแยกไม่ออกจากจำนวน email ที่ได้รับ
+	if created.mails != enumerationAttempts || duplicate.mails != enumerationAttempts {
+		t.Errorf("emails sent: new=%d existing=%d, want %d each", created.mails, duplicate.mails, enumerationAttempts)
+	}
+	assertSimilarTiming(t, created.elapsed, duplicate.elapsed)
+	assertSimilarTiming(t, created.elapsed, inactive.elapsed)
+}
+
+func TestLoginWithoutEnumerationProtection(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("unknown account skips password check", func(mt *mtest.T) {
+		algorithm := newSlowAlgorithm(0)
+		pool := password.NewPool(password.NewHasher(algorithm), password.PoolConfig{})
+		defer pool.Close()
+
+		s := newTestService(mt, Dependencies{Passwords: pool}, Options{})
+		mt.AddMockResponses(
+			cursorResponse(mt, "tenants", tenantDoc(testTenant)),
+			cursorResponse(mt, "users"),
+		)
+
+		resp, err := s.Login(context.Background(), "", "nobody@example.com", "whatever", "")
+		if err != nil || resp.Success || resp.Message != "Invalid email or password" {
+			mt.Fatalf("Login = (%+v, %v)", resp, err)
+		}
+		if n := algorithm.verifies.Load(); n != 0 {
+			mt.Errorf("password checks = %d, want 0 when protection is off", n)
+		}
+	})
+}
+
+// assertSimilarTiming - เวลาตอบรวมต่างกันไม่เกินการ hash 1 ครั้ง (ถ้าข้าม hash จะต่างกันอย่างน้อย enumerationAttempts ครั้ง)
+func assertSimilarTiming(t *testing.T, a, b time.Duration) {
+	t.Helper()
+
+	diff := a - b
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > hashDelay {
+		t.Errorf("response times differ by %s (%s vs %s), want at most %s", diff, a, b, hashDelay)
+	}
+}
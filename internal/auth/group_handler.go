@@ -0,0 +1,276 @@
+package auth
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"auth-microservice/internal/group"
+	"auth-microservice/internal/middleware"
+	"auth-microservice/internal/rbac"
+	"auth-microservice/proto/auth"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GroupHandler - gRPC handler สำหรับ groups ใน tenant ของผู้เรียก (สิทธิ์ที่ต้องมีกำหนดใน proto)
+type GroupHandler struct {
+	auth.UnimplementedGroupServiceServer
+	service *Service
+}
+
+// NewGroupHandler - สร้าง group handler ใหม่
+func NewGroupHandler(service *Service) *GroupHandler {
+	return &GroupHandler{
+		service: service,
+	}
+}
+
+// ListGroups - gRPC handler สำหรับแสดง groups ทั้งหมด (ต้องมีสิทธิ์ groups:read)
+func (h *GroupHandler) ListGroups(ctx context.Context, req *auth.ListGroupsRequest) (*auth.ListGroupsResponse, error) {
+	log.Printf("👥 ListGroups request received")
+
+	groups, err := h.service.ListGroups(ctx)
+	if err != nil {
+		log.Printf("❌ ListGroups service error: %v", err)
+		return nil, status.Errorf(codes.Internal, "Failed to list groups")
+	}
+
+	log.Printf("✅ ListGroups successful - Found %d groups", len(groups))
+	return &auth.ListGroupsResponse{
+		Groups: toProtoGroups(groups),
+	}, nil
+}
+
+// ListUserGroups - gRPC handler สำหรับแสดง groups ของ user (ของตัวเอง หรือ user อื่นเมื่อมีสิทธิ์ groups:read)
+func (h *GroupHandler) ListUserGroups(ctx context.Context, req *auth.ListUserGroupsRequest) (*auth.ListGroupsResponse, error) {
+	principal, ok := middleware.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "Authentication required")
+	}
+
+	userID := req.UserId
+	if userID == "" {
+		userID = principal.UserID
+	}
+	log.Printf("👥 ListUserGroups request for user: %s", userID)
+
+	if !principal.CanActOn(userID, rbac.PermGroupsRead) {
+		log.Printf("❌ Access denied - %s is not allowed to list groups of user %s", principal.UserID, userID)
+		return nil, status.Errorf(codes.PermissionDenied, "Permission denied: %s required", rbac.PermGroupsRead)
+	}
+
+	groups, err := h.service.ListUserGroups(ctx, userID)
+	if err != nil {
+		log.Printf("❌ ListUserGroups service error: %v", err)
+		return nil, status.Errorf(codes.Internal, "Failed to list groups")
+	}
+
+	log.Printf("✅ ListUserGroups successful - Found %d groups for user: %s", len(groups), userID)
+	return &auth.ListGroupsResponse{
+		Groups: toProtoGroups(groups),
+	}, nil
+}
+
+// CreateGroup - gRPC handler สำหรับสร้าง group (ต้องมีสิทธิ์ groups:manage)
+func (h *GroupHandler) CreateGroup(ctx context.Context, req *auth.CreateGroupRequest) (*auth.GroupResponse, error) {
+	log.Printf("👥 CreateGroup request for group: %s", req.Name)
+
+	actorID, _ := middleware.UserIDFromContext(ctx)
+	result, err := h.service.CreateGroup(ctx, actorID, req.Name, req.Description, req.Roles)
+	if err != nil {
+		log.Printf("❌ CreateGroup service error: %v", err)
+		return &auth.GroupResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	return toGroupResponse("CreateGroup", req.Name, result), nil
+}
+
+// RenameGroup - gRPC handler สำหรับเปลี่ยนชื่อ group (ต้องมีสิทธิ์ groups:manage)
+func (h *GroupHandler) RenameGroup(ctx context.Context, req *auth.RenameGroupRequest) (*auth.GroupResponse, error) {
+	log.Printf("👥 RenameGroup request for group: %s (name: %s)", req.GroupId, req.Name)
+
+	actorID, _ := middleware.UserIDFromContext(ctx)
+	result, err := h.service.RenameGroup(ctx, actorID, req.GroupId, req.Name)
+	if err != nil {
+		log.Printf("❌ RenameGroup service error: %v", err)
+		return &auth.GroupResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	return toGroupResponse("RenameGroup", req.GroupId, result), nil
+}
+
+// SetGroupRoles - gRPC handler สำหรับแทนที่ roles ของ group (ต้องมีสิทธิ์ groups:manage และ users:admin)
+func (h *GroupHandler) SetGroupRoles(ctx context.Context, req *auth.SetGroupRolesRequest) (*auth.GroupResponse, error) {
+	log.Printf("👥 SetGroupRoles request for group: %s", req.GroupId)
+
+	actorID, _ := middleware.UserIDFromContext(ctx)
+	result, err := h.service.SetGroupRoles(ctx, actorID, req.GroupId, req.Roles)
+	if err != nil {
+		log.Printf("❌ SetGroupRoles service error: %v", err)
+		return &auth.GroupResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	return toGroupResponse("SetGroupRoles", req.GroupId, result), nil
+}
+
+// DeleteGroup - gRPC handler สำหรับลบ group (ต้องมีสิทธิ์ groups:manage)
+func (h *GroupHandler) DeleteGroup(ctx context.Context, req *auth.DeleteGroupRequest) (*auth.DeleteGroupResponse, error) {
+	log.Printf("👥 DeleteGroup request for group: %s", req.GroupId)
+
+	actorID, _ := middleware.UserIDFromContext(ctx)
+	result, err := h.service.DeleteGroup(ctx, actorID, req.GroupId)
+	if err != nil {
+		log.Printf("❌ DeleteGroup service error: %v", err)
+		return &auth.DeleteGroupResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	if result.Success {
+		log.Printf("✅ DeleteGroup successful for group: %s", req.GroupId)
+	} else {
+		log.Printf("❌ DeleteGroup failed for group: %s - %s", req.GroupId, result.Message)
+	}
+
+	return &auth.DeleteGroupResponse{
+		Success: result.Success,
+		Message: result.Message,
+	}, nil
+}
+
+// AddGroupMember - gRPC handler สำหรับเพิ่มสมาชิกเข้า group (ต้องมีสิทธิ์ groups:manage)
+func (h *GroupHandler) AddGroupMember(ctx context.Context, req *auth.GroupMemberRequest) (*auth.GroupMemberResponse, error) {
+	log.Printf("👥 AddGroupMember request for group: %s (user: %s)", req.GroupId, req.UserId)
+
+	actorID, _ := middleware.UserIDFromContext(ctx)
+	result, err := h.service.AddGroupMember(ctx, actorID, req.GroupId, req.UserId)
+	if err != nil {
+		log.Printf("❌ AddGroupMember service error: %v", err)
+		return &auth.GroupMemberResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	return toGroupMemberResponse("AddGroupMember", req, result), nil
+}
+
+// RemoveGroupMember - gRPC handler สำหรับนำสมาชิกออกจาก group (ต้องมีสิทธิ์ groups:manage)
+func (h *GroupHandler) RemoveGroupMember(ctx context.Context, req *auth.GroupMemberRequest) (*auth.GroupMemberResponse, error) {
+	log.Printf("👥 RemoveGroupMember request for group: %s (user: %s)", req.GroupId, req.UserId)
+
+	actorID, _ := middleware.UserIDFromContext(ctx)
+	result, err := h.service.RemoveGroupMember(ctx, actorID, req.GroupId, req.UserId)
+	if err != nil {
+		log.Printf("❌ RemoveGroupMember service error: %v", err)
+		return &auth.GroupMemberResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	return toGroupMemberResponse("RemoveGroupMember", req, result), nil
+}
+
+// ListGroupMembers - gRPC handler สำหรับแสดงสมาชิกของ group (ต้องมีสิทธิ์ groups:read)
+func (h *GroupHandler) ListGroupMembers(ctx context.Context, req *auth.ListGroupMembersRequest) (*auth.ListGroupMembersResponse, error) {
+	log.Printf("👥 ListGroupMembers request for group: %s", req.GroupId)
+
+	result, err := h.service.ListGroupMembers(ctx, req.GroupId)
+	if err != nil {
+		log.Printf("❌ ListGroupMembers service error: %v", err)
+		return &auth.ListGroupMembersResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	if !result.Success {
+		log.Printf("❌ ListGroupMembers failed for group: %s - %s", req.GroupId, result.Message)
+		return &auth.ListGroupMembersResponse{
+			Success: result.Success,
+			Message: result.Message,
+		}, nil
+	}
+
+	members := make([]*auth.GroupMember, 0, len(result.Members))
+	for _, u := range result.Members {
+		members = append(members, &auth.GroupMember{
+			UserId:    u.ID.Hex(),
+			Email:     u.Email,
+			FirstName: u.FirstName,
+			LastName:  u.LastName,
+		})
+	}
+
+	log.Printf("✅ ListGroupMembers successful - Found %d members in group: %s", len(members), req.GroupId)
+	return &auth.ListGroupMembersResponse{
+		Success: result.Success,
+		Message: result.Message,
+		Members: members,
+	}, nil
+}
+
+// toGroupResponse - แปลงผลของ CreateGroup/RenameGroup/SetGroupRoles เป็น proto
+func toGroupResponse(method, name string, result *GroupResponse) *auth.GroupResponse {
+	if !result.Success {
+		log.Printf("❌ %s failed for group: %s - %s", method, name, result.Message)
+		return &auth.GroupResponse{
+			Success: result.Success,
+			Message: result.Message,
+		}
+	}
+
+	log.Printf("✅ %s successful for group: %s", method, name)
+	return &auth.GroupResponse{
+		Success: result.Success,
+		Message: result.Message,
+		Group:   toProtoGroup(result.Group),
+	}
+}
+
+// toGroupMemberResponse - แปลงผลของ AddGroupMember/RemoveGroupMember เป็น proto
+func toGroupMemberResponse(method string, req *auth.GroupMemberRequest, result *GroupMemberResponse) *auth.GroupMemberResponse {
+	if result.Success {
+		log.Printf("✅ %s successful for group: %s (user: %s)", method, req.GroupId, req.UserId)
+	} else {
+		log.Printf("❌ %s failed for group: %s (user: %s) - %s", method, req.GroupId, req.UserId, result.Message)
+	}
+
+	return &auth.GroupMemberResponse{
+		Success: result.Success,
+		Message: result.Message,
+	}
+}
+
+func toProtoGroups(groups []group.Group) []*auth.Group {
+	protoGroups := make([]*auth.Group, 0, len(groups))
+	for i := range groups {
+		protoGroups = append(protoGroups, toProtoGroup(&groups[i]))
+	}
+	return protoGroups
+}
+
+func toProtoGroup(g *group.Group) *auth.Group {
+	return &auth.Group{
+		Id:          g.ID.Hex(),
+		Name:        g.Name,
+		Description: g.Description,
+		Roles:       g.Roles,
+		MemberCount: int32(len(g.Members)),
+		CreatedAt:   g.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   g.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,387 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"auth-microservice/internal/audit"
+	"auth-microservice/internal/group"
+	"auth-microservice/internal/middleware"
+	"auth-microservice/internal/rbac"
+)
+
+// maxGroupNameLength - ความยาวสูงสุดของชื่อ group
+const maxGroupNameLength = 64
+
+// errNoTenant - request ไม่มี tenant (AuthInterceptor ใส่ไว้เสมอสำหรับ access token ที่ใช้ได้)
+var errNoTenant = errors.New("tenant missing from request context")
+
+// ListGroups - groups ทั้งหมดใน tenant ของผู้เรียก
+func (s *Service) ListGroups(ctx context.Context) ([]group.Group, error) {
+	tenantID, ok := middleware.TenantIDFromContext(ctx)
+	if !ok {
+		return nil, errNoTenant
+	}
+	return s.groups.List(ctx, tenantID)
+}
+
+// ListUserGroups - groups ที่ user เป็นสมาชิกใน tenant ของผู้เรียก
+func (s *Service) ListUserGroups(ctx context.Context, userID string) ([]group.Group, error) {
+	tenantID, ok := middleware.TenantIDFromContext(ctx)
+	if !ok {
+		return nil, errNoTenant
+	}
+	return s.groups.ListByMember(ctx, tenantID, userID)
+}
+
+// CreateGroup - สร้าง group ใหม่ (กำหนด roles ได้เฉพาะผู้มีสิทธิ์ users:admin)
+func (s *Service) CreateGroup(ctx context.Context, actorID, name, description string, roles []string) (*GroupResponse, error) {
+	tenantID, ok := middleware.TenantIDFromContext(ctx)
+	if !ok {
+		return &GroupResponse{Success: false, Message: "Internal server error"}, errNoTenant
+	}
+
+	name, message := normalizeGroupName(name)
+	if message != "" {
+		return &GroupResponse{Success: false, Message: message}, nil
+	}
+
+	roles, message, err := s.normalizeGroupRoles(ctx, roles)
+	if err != nil {
+		return &GroupResponse{Success: false, Message: "Internal server error"}, err
+	}
+	if message != "" {
+		return &GroupResponse{Success: false, Message: message}, nil
+	}
+	if len(roles) > 0 && !middleware.HasPermission(ctx, rbac.PermUsersAdmin) {
+		return &GroupResponse{
+			Success: false,
+			Message: "Assigning roles to a group requires users:admin",
+		}, nil
+	}
+
+	created, err := s.groups.Create(ctx, tenantID, name, strings.TrimSpace(description), roles)
+	if err == group.ErrGroupExists {
+		return &GroupResponse{
+			Success: false,
+			Message: "Group already exists",
+		}, nil
+	}
+	if err != nil {
+		return &GroupResponse{Success: false, Message: "Internal server error"}, err
+	}
+
+	s.recordAudit(ctx, audit.Event{
+		Type:    audit.EventGroupCreated,
+		ActorID: actorID,
+		Details: map[string]string{
+			"group": created.ID.Hex(),
+			"name":  created.Name,
+			"roles": strings.Join(created.Roles, " "),
+		},
+	})
+	log.Printf("Group created: %s (%s) in tenant %s", created.Name, created.ID.Hex(), tenantID)
+
+	return &GroupResponse{
+		Success: true,
+		Message: "Group created successfully",
+		Group:   created,
+	}, nil
+}
+
+// RenameGroup - เปลี่ยนชื่อ group
+func (s *Service) RenameGroup(ctx context.Context, actorID, groupID, name string) (*GroupResponse, error) {
+	tenantID, ok := middleware.TenantIDFromContext(ctx)
+	if !ok {
+		return &GroupResponse{Success: false, Message: "Internal server error"}, errNoTenant
+	}
+
+	name, message := normalizeGroupName(name)
+	if message != "" {
+		return &GroupResponse{Success: false, Message: message}, nil
+	}
+
+	existing, err := s.groups.Get(ctx, tenantID, groupID)
+	if err == group.ErrGroupNotFound {
+		return &GroupResponse{Success: false, Message: "Group not found"}, nil
+	}
+	if err != nil {
+		return &GroupResponse{Success: false, Message: "Internal server error"}, err
+	}
+	if existing.Name == name {
+		return &GroupResponse{
+			Success: true,
+			Message: "Group already has this name",
+			Group:   existing,
+		}, nil
+	}
+
+	renamed, err := s.groups.Rename(ctx, tenantID, groupID, name)
+	switch err {
+	case nil:
+	case group.ErrGroupNotFound:
+		return &GroupResponse{Success: false, Message: "Group not found"}, nil
+	case group.ErrGroupExists:
+		return &GroupResponse{Success: false, Message: "Group already exists"}, nil
+	default:
+		return &GroupResponse{Success: false, Message: "Internal server error"}, err
+	}
+
+	s.recordAudit(ctx, audit.Event{
+		Type:    audit.EventGroupRenamed,
+		ActorID: actorID,
+		Details: map[string]string{
+			"group": renamed.ID.Hex(),
+			"from":  existing.Name,
+			"to":    renamed.Name,
+		},
+	})
+	log.Printf("Group renamed: %s -> %s in tenant %s", existing.Name, renamed.Name, tenantID)
+
+	return &GroupResponse{
+		Success: true,
+		Message: "Group renamed successfully",
+		Group:   renamed,
+	}, nil
+}
+
+// SetGroupRoles - แทนที่ roles ของ group (ต้องมีสิทธิ์ users:admin เพราะเปลี่ยนสิทธิ์ของสมาชิกทุกคน)
+func (s *Service) SetGroupRoles(ctx context.Context, actorID, groupID string, roles []string) (*GroupResponse, error) {
+	tenantID, ok := middleware.TenantIDFromContext(ctx)
+	if !ok {
+		return &GroupResponse{Success: false, Message: "Internal server error"}, errNoTenant
+	}
+
+	if !middleware.HasPermission(ctx, rbac.PermUsersAdmin) {
+		return &GroupResponse{
+			Success: false,
+			Message: "Changing group roles requires users:admin",
+		}, nil
+	}
+
+	roles, message, err := s.normalizeGroupRoles(ctx, roles)
+	if err != nil {
+		return &GroupResponse{Success: false, Message: "Internal server error"}, err
+	}
+	if message != "" {
+		return &GroupResponse{Success: false, Message: message}, nil
+	}
+
+	existing, err := s.groups.Get(ctx, tenantID, groupID)
+	if err == group.ErrGroupNotFound {
+		return &GroupResponse{Success: false, Message: "Group not found"}, nil
+	}
+	if err != nil {
+		return &GroupResponse{Success: false, Message: "Internal server error"}, err
+	}
+
+	updated, err := s.groups.SetRoles(ctx, tenantID, groupID, roles)
+	if err == group.ErrGroupNotFound {
+		return &GroupResponse{Success: false, Message: "Group not found"}, nil
+	}
+	if err != nil {
+		return &GroupResponse{Success: false, Message: "Internal server error"}, err
+	}
+
+	s.recordAudit(ctx, audit.Event{
+		Type:    audit.EventGroupRolesChanged,
+		ActorID: actorID,
+		Details: map[string]string{
+			"group": updated.ID.Hex(),
+			"name":  updated.Name,
+			"from":  strings.Join(existing.Roles, " "),
+			"to":    strings.Join(updated.Roles, " "),
+		},
+	})
+	log.Printf("Group roles changed: %s (%s) -> (%s) in tenant %s",
+		updated.Name, strings.Join(existing.Roles, " "), strings.Join(updated.Roles, " "), tenantID)
+
+	return &GroupResponse{
+		Success: true,
+		Message: "Group roles updated successfully",
+		Group:   updated,
+	}, nil
+}
+
+// DeleteGroup - ลบ group (สมาชิกเสียสิทธิ์ที่ได้จาก group ทันทีบน replica นี้)
+func (s *Service) DeleteGroup(ctx context.Context, actorID, groupID string) (*DeleteGroupResponse, error) {
+	tenantID, ok := middleware.TenantIDFromContext(ctx)
+	if !ok {
+		return &DeleteGroupResponse{Success: false, Message: "Internal server error"}, errNoTenant
+	}
+
+	deleted, err := s.groups.Delete(ctx, tenantID, groupID)
+	if err == group.ErrGroupNotFound {
+		return &DeleteGroupResponse{Success: false, Message: "Group not found"}, nil
+	}
+	if err != nil {
+		return &DeleteGroupResponse{Success: false, Message: "Internal server error"}, err
+	}
+
+	s.recordAudit(ctx, audit.Event{
+		Type:    audit.EventGroupDeleted,
+		ActorID: actorID,
+		Details: map[string]string{
+			"group":   deleted.ID.Hex(),
+			"name":    deleted.Name,
+			"members": fmt.Sprint(len(deleted.Members)),
+		},
+	})
+	log.Printf("Group deleted: %s (%d members) in tenant %s", deleted.Name, len(deleted.Members), tenantID)
+
+	return &DeleteGroupResponse{
+		Success: true,
+		Message: "Group deleted successfully",
+	}, nil
+}
+
+// AddGroupMember - เพิ่ม user ใน tenant เดียวกันเข้า group
+func (s *Service) AddGroupMember(ctx context.Context, actorID, groupID, userID string) (*GroupMemberResponse, error) {
+	return s.changeGroupMember(ctx, actorID, groupID, userID, true)
+}
+
+// RemoveGroupMember - นำ user ออกจาก group
+func (s *Service) RemoveGroupMember(ctx context.Context, actorID, groupID, userID string) (*GroupMemberResponse, error) {
+	return s.changeGroupMember(ctx, actorID, groupID, userID, false)
+}
+
+// changeGroupMember - เพิ่มหรือนำสมาชิกออก (group ที่มี roles ต้องมีสิทธิ์ users:admin เหมือน SetRole)
+func (s *Service) changeGroupMember(ctx context.Context, actorID, groupID, userID string, add bool) (*GroupMemberResponse, error) {
+	tenantID, ok := middleware.TenantIDFromContext(ctx)
+	if !ok {
+		return &GroupMemberResponse{Success: false, Message: "Internal server error"}, errNoTenant
+	}
+
+	existing, err := s.groups.Get(ctx, tenantID, groupID)
+	if err == group.ErrGroupNotFound {
+		return &GroupMemberResponse{Success: false, Message: "Group not found"}, nil
+	}
+	if err != nil {
+		return &GroupMemberResponse{Success: false, Message: "Internal server error"}, err
+	}
+
+	if len(existing.Roles) > 0 && !middleware.HasPermission(ctx, rbac.PermUsersAdmin) {
+		return &GroupMemberResponse{
+			Success: false,
+			Message: "Changing members of a group with roles requires users:admin",
+		}, nil
+	}
+
+	eventType := audit.EventGroupMemberRemoved
+	if add {
+		// user ที่ถูกลบหรือถูกระงับเพิ่มเข้า group ไม่ได้ แต่ยังนำออกได้
+		if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+			return &GroupMemberResponse{Success: false, Message: "User not found"}, nil
+		}
+		eventType = audit.EventGroupMemberAdded
+		err = s.groups.AddMember(ctx, tenantID, groupID, userID)
+	} else {
+		err = s.groups.RemoveMember(ctx, tenantID, groupID, userID)
+	}
+
+	switch err {
+	case nil:
+	case group.ErrGroupNotFound:
+		return &GroupMemberResponse{Success: false, Message: "Group not found"}, nil
+	case group.ErrAlreadyMember:
+		return &GroupMemberResponse{Success: true, Message: "User is already a member of this group"}, nil
+	case group.ErrNotMember:
+		return &GroupMemberResponse{Success: false, Message: "User is not a member of this group"}, nil
+	default:
+		return &GroupMemberResponse{Success: false, Message: "Internal server error"}, err
+	}
+
+	s.recordAudit(ctx, audit.Event{
+		Type:     eventType,
+		ActorID:  actorID,
+		TargetID: userID,
+		Details: map[string]string{
+			"group": existing.ID.Hex(),
+			"name":  existing.Name,
+		},
+	})
+
+	if add {
+		log.Printf("User %s added to group %s in tenant %s", userID, existing.Name, tenantID)
+		return &GroupMemberResponse{Success: true, Message: "Member added successfully"}, nil
+	}
+	log.Printf("User %s removed from group %s in tenant %s", userID, existing.Name, tenantID)
+	return &GroupMemberResponse{Success: true, Message: "Member removed successfully"}, nil
+}
+
+// ListGroupMembers - สมาชิกของ group ที่ยังใช้งานได้ (บัญชีที่ถูกลบหรือระงับไม่แสดง)
+func (s *Service) ListGroupMembers(ctx context.Context, groupID string) (*GroupMembersResponse, error) {
+	tenantID, ok := middleware.TenantIDFromContext(ctx)
+	if !ok {
+		return &GroupMembersResponse{Success: false, Message: "Internal server error"}, errNoTenant
+	}
+
+	existing, err := s.groups.Get(ctx, tenantID, groupID)
+	if err == group.ErrGroupNotFound {
+		return &GroupMembersResponse{Success: false, Message: "Group not found"}, nil
+	}
+	if err != nil {
+		return &GroupMembersResponse{Success: false, Message: "Internal server error"}, err
+	}
+
+	members, err := s.userRepo.ListByIDs(ctx, existing.Members)
+	if err != nil {
+		return &GroupMembersResponse{Success: false, Message: "Internal server error"}, err
+	}
+
+	return &GroupMembersResponse{
+		Success: true,
+		Message: "Group members retrieved successfully",
+		Members: members,
+	}, nil
+}
+
+// normalizeGroupName - ตัดช่องว่างและตรวจสอบความยาวของชื่อ group (message ว่าง = ใช้ได้)
+func normalizeGroupName(name string) (string, string) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", "Group name is required"
+	}
+	if utf8.RuneCountInString(name) > maxGroupNameLength {
+		return "", fmt.Sprintf("Group name must be at most %d characters", maxGroupNameLength)
+	}
+	return name, ""
+}
+
+// normalizeGroupRoles - ตัดค่าซ้ำ เรียงลำดับ และตรวจสอบว่าทุก role มีอยู่จริงและผู้เรียกกำหนดให้ได้ (message ว่าง = ใช้ได้)
+func (s *Service) normalizeGroupRoles(ctx context.Context, roles []string) ([]string, string, error) {
+	seen := make(map[string]bool, len(roles))
+	result := make([]string, 0, len(roles))
+	for _, role := range roles {
+		role = strings.TrimSpace(role)
+		if seen[role] {
+			continue
+		}
+		if _, err := s.roles.Get(ctx, role); err != nil {
+			if err == rbac.ErrRoleNotFound {
+				return nil, fmt.Sprintf("Role not found: %q", role), nil
+			}
+			return nil, "", err
+		}
+		seen[role] = true
+		result = append(result, role)
+	}
+	sort.Strings(result)
+
+	// สมาชิกของ group ได้ roles ของ group - ใช้กฎเดียวกับ SetRole
+	if len(result) > 0 && !s.fromDefaultTenant(ctx) {
+		global, err := s.grantsGlobalRights(ctx, result...)
+		if err != nil {
+			return nil, "", err
+		}
+		if global {
+			return nil, globalRolesDefaultTenantOnlyMessage, nil
+		}
+	}
+	return result, "", nil
+}
@@ -3,8 +3,14 @@ package auth
 import (
 	"context"
 	"log"
+	"time"
 
+	"auth-microservice/internal/middleware"
+	"auth-microservice/internal/rbac"
 	"auth-microservice/proto/auth"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // Handler - gRPC handler สำหรับ Authentication
@@ -25,7 +31,12 @@ func (h *Handler) Login(ctx context.Context, req *auth.LoginRequest) (*auth.Logi
 	log.Printf("🔐 Login request received for email: %s", req.Email)
 
 	// เรียก service layer
-	result, err := h.service.Login(ctx, req.Email, req.Password)
+	result, err := h.service.Login(ctx, req.Tenant, req.Email, req.Password, req.Audience)
+	if isStatusError(err) {
+		// เช่น ResourceExhausted จาก rate limit - ส่ง status กลับให้ client ตรงๆ
+		log.Printf("❌ Login rejected for: %s - %v", req.Email, err)
+		return nil, err
+	}
 	if err != nil {
 		log.Printf("❌ Login service error: %v", err)
 		return &auth.LoginResponse{
@@ -36,12 +47,19 @@ func (h *Handler) Login(ctx context.Context, req *auth.LoginRequest) (*auth.Logi
 
 	// แปลง response
 	response := &auth.LoginResponse{
-		Success: result.Success,
-		Message: result.Message,
-		Token:   result.Token,
+		Success:      result.Success,
+		Message:      result.Message,
+		Token:        result.Token,
+		RefreshToken: result.RefreshToken,
+		ExpiresIn:    result.ExpiresIn,
+		MfaRequired:  result.MFARequired,
+		MfaToken:     result.MFAToken,
+		MfaMethods:   result.MFAMethods,
 	}
 
-	if result.Success {
+	if result.MFARequired {
+		log.Printf("🔐 Login requires MFA for: %s", req.Email)
+	} else if result.Success {
 		log.Printf("✅ Login successful for: %s", req.Email)
 	} else {
 		log.Printf("❌ Login failed for: %s - %s", req.Email, result.Message)
@@ -55,7 +73,7 @@ func (h *Handler) Logout(ctx context.Context, req *auth.LogoutRequest) (*auth.Lo
 	log.Printf("🚪 Logout request received")
 
 	// เรียก service layer
-	result, err := h.service.Logout(ctx, req.Token)
+	result, err := h.service.Logout(ctx, req.Token, req.RefreshToken)
 	if err != nil {
 		log.Printf("❌ Logout service error: %v", err)
 		return &auth.LogoutResponse{
@@ -89,11 +107,15 @@ func (h *Handler) Register(ctx context.Context, req *auth.RegisterRequest) (*aut
 		Password:  req.Password,
 		FirstName: req.FirstName,
 		LastName:  req.LastName,
+		TenantID:  req.Tenant,
 	}
 
 	// เรียก service layer
 	result, err := h.service.Register(ctx, serviceReq)
 	if err != nil {
+		if isStatusError(err) {
+			return nil, err
+		}
 		log.Printf("❌ Register service error: %v", err)
 		return &auth.RegisterResponse{
 			Success: false,
@@ -116,3 +138,730 @@ func (h *Handler) Register(ctx context.Context, req *auth.RegisterRequest) (*aut
 
 	return response, nil
 }
+
+// Refresh - gRPC handler สำหรับขอ access token ใหม่ด้วย refresh token
+func (h *Handler) Refresh(ctx context.Context, req *auth.RefreshRequest) (*auth.RefreshResponse, error) {
+	log.Printf("🔄 Refresh request received")
+
+	// เรียก service layer
+	result, err := h.service.Refresh(ctx, req.RefreshToken)
+	if err != nil {
+		log.Printf("❌ Refresh service error: %v", err)
+		return &auth.RefreshResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	// แปลง response
+	response := &auth.RefreshResponse{
+		Success:      result.Success,
+		Message:      result.Message,
+		Token:        result.Token,
+		RefreshToken: result.RefreshToken,
+		ExpiresIn:    result.ExpiresIn,
+	}
+
+	if result.Success {
+		log.Printf("✅ Refresh successful")
+	} else {
+		log.Printf("❌ Refresh failed: %s", result.Message)
+	}
+
+	return response, nil
+}
+
+// GetJWKS - gRPC handler สำหรับดึง public keys (JWKS)
+func (h *Handler) GetJWKS(ctx context.Context, req *auth.GetJWKSRequest) (*auth.GetJWKSResponse, error) {
+	jwks := h.service.JWKS()
+
+	keys := make([]*auth.JWK, 0, len(jwks.Keys))
+	for _, k := range jwks.Keys {
+		keys = append(keys, &auth.JWK{
+			Kty: k.Kty,
+			Kid: k.Kid,
+			Use: k.Use,
+			Alg: k.Alg,
+			N:   k.N,
+			E:   k.E,
+			Crv: k.Crv,
+			X:   k.X,
+			Y:   k.Y,
+		})
+	}
+
+	return &auth.GetJWKSResponse{Keys: keys}, nil
+}
+
+// RotateSigningKeys - gRPC handler สำหรับ rotate signing keys (ต้องมีสิทธิ์ keys:rotate)
+func (h *Handler) RotateSigningKeys(ctx context.Context, req *auth.RotateSigningKeysRequest) (*auth.RotateSigningKeysResponse, error) {
+	log.Printf("🔑 RotateSigningKeys request received")
+
+	keys, err := h.service.RotateSigningKeys(ctx)
+	if isStatusError(err) {
+		log.Printf("❌ RotateSigningKeys denied: %v", err)
+		return nil, err
+	}
+	if err != nil {
+		log.Printf("❌ RotateSigningKeys service error: %v", err)
+		return &auth.RotateSigningKeysResponse{
+			Success: false,
+			Message: "Failed to rotate signing keys",
+		}, nil
+	}
+
+	protoKeys := make([]*auth.SigningKeyInfo, 0, len(keys))
+	for _, k := range keys {
+		protoKey := &auth.SigningKeyInfo{
+			Kid:       k.KID,
+			Algorithm: k.Algorithm,
+			State:     string(k.State),
+			CreatedAt: k.CreatedAt.Format(time.RFC3339),
+		}
+		if k.ActivatedAt != nil {
+			protoKey.ActivatedAt = k.ActivatedAt.Format(time.RFC3339)
+		}
+		if k.ExpiresAt != nil {
+			protoKey.ExpiresAt = k.ExpiresAt.Format(time.RFC3339)
+		}
+		protoKeys = append(protoKeys, protoKey)
+	}
+
+	log.Printf("✅ RotateSigningKeys successful")
+	return &auth.RotateSigningKeysResponse{
+		Success: true,
+		Message: "Signing keys rotated successfully",
+		Keys:    protoKeys,
+	}, nil
+}
+
+// Introspect - gRPC handler สำหรับให้ service อื่นตรวจสอบ token
+func (h *Handler) Introspect(ctx context.Context, req *auth.IntrospectRequest) (*auth.IntrospectResponse, error) {
+	clientID, _ := middleware.ServiceClientFromContext(ctx)
+	log.Printf("🔎 Introspect request from client: %s", clientID)
+
+	if req.TokenTypeHint != "" && req.TokenTypeHint != "access_token" {
+		return &auth.IntrospectResponse{Active: false}, nil
+	}
+
+	result, err := h.service.Introspect(ctx, req.Token)
+	if err != nil {
+		log.Printf("❌ Introspect service error: %v", err)
+		return nil, status.Errorf(codes.Unavailable, "Unable to introspect token")
+	}
+
+	return &auth.IntrospectResponse{
+		Active:    result.Active,
+		Sub:       result.Sub,
+		Email:     result.Email,
+		Role:      result.Role,
+		Scope:     result.Scope,
+		Exp:       result.Exp,
+		Iat:       result.Iat,
+		Iss:       result.Iss,
+		Aud:       result.Aud,
+		Nbf:       result.Nbf,
+		TokenType: result.TokenType,
+		TenantId:  result.TenantID,
+	}, nil
+}
+
+// RevokeAllSessions - gRPC handler สำหรับยกเลิกทุก session ของ user
+// (user ยกเลิกของตัวเองได้ ส่วน user อื่นต้องเป็น admin)
+func (h *Handler) RevokeAllSessions(ctx context.Context, req *auth.RevokeAllSessionsRequest) (*auth.RevokeAllSessionsResponse, error) {
+	userID, err := h.resolveTargetUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("🚫 RevokeAllSessions request for user: %s", userID)
+
+	result, err := h.service.RevokeAllSessions(ctx, userID)
+	if err != nil {
+		log.Printf("❌ RevokeAllSessions service error: %v", err)
+		return &auth.RevokeAllSessionsResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	if result.Success {
+		log.Printf("✅ RevokeAllSessions successful for user: %s", userID)
+	} else {
+		log.Printf("❌ RevokeAllSessions failed for user: %s - %s", userID, result.Message)
+	}
+
+	return &auth.RevokeAllSessionsResponse{
+		Success: result.Success,
+		Message: result.Message,
+	}, nil
+}
+
+// ListSessions - gRPC handler สำหรับแสดงรายการ session ที่ยัง login อยู่
+func (h *Handler) ListSessions(ctx context.Context, req *auth.ListSessionsRequest) (*auth.ListSessionsResponse, error) {
+	userID, err := h.resolveTargetUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("📱 ListSessions request for user: %s", userID)
+
+	sessions, err := h.service.ListSessions(ctx, userID)
+	if err != nil {
+		log.Printf("❌ ListSessions service error: %v", err)
+		return &auth.ListSessionsResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	currentID, _ := middleware.SessionIDFromContext(ctx)
+
+	protoSessions := make([]*auth.Session, 0, len(sessions))
+	for _, s := range sessions {
+		protoSessions = append(protoSessions, &auth.Session{
+			Id:         s.ID.Hex(),
+			IpAddress:  s.IPAddress,
+			UserAgent:  s.UserAgent,
+			CreatedAt:  s.CreatedAt.Format(time.RFC3339),
+			LastSeenAt: s.LastSeenAt.Format(time.RFC3339),
+			ExpiresAt:  s.ExpiresAt.Format(time.RFC3339),
+			Current:    s.ID.Hex() == currentID,
+		})
+	}
+
+	log.Printf("✅ ListSessions successful - Found %d sessions", len(protoSessions))
+	return &auth.ListSessionsResponse{
+		Success:  true,
+		Message:  "Sessions retrieved successfully",
+		Sessions: protoSessions,
+	}, nil
+}
+
+// RevokeSession - gRPC handler สำหรับยกเลิก session เดียว
+func (h *Handler) RevokeSession(ctx context.Context, req *auth.RevokeSessionRequest) (*auth.RevokeSessionResponse, error) {
+	userID, err := h.resolveTargetUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("📴 RevokeSession request for session: %s", req.SessionId)
+
+	result, err := h.service.RevokeSession(ctx, userID, req.SessionId)
+	if err != nil {
+		log.Printf("❌ RevokeSession service error: %v", err)
+		return &auth.RevokeSessionResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	if result.Success {
+		log.Printf("✅ RevokeSession successful for session: %s", req.SessionId)
+	} else {
+		log.Printf("❌ RevokeSession failed for session: %s - %s", req.SessionId, result.Message)
+	}
+
+	return &auth.RevokeSessionResponse{
+		Success: result.Success,
+		Message: result.Message,
+	}, nil
+}
+
+// resolveTargetUser - user ที่ถูกกระทำ (ว่าง = ผู้เรียกเอง, user อื่นต้องมีสิทธิ์ sessions:manage และอยู่ใน tenant เดียวกัน)
+func (h *Handler) resolveTargetUser(ctx context.Context, requestedID string) (string, error) {
+	principal, ok := middleware.PrincipalFromContext(ctx)
+	if !ok {
+		return "", status.Errorf(codes.Unauthenticated, "Authentication required")
+	}
+	if requestedID == "" {
+		return principal.UserID, nil
+	}
+
+	if !principal.CanActOn(requestedID, rbac.PermSessionsManage) {
+		log.Printf("❌ Access denied - %s is not allowed to act on user %s", principal.UserID, requestedID)
+		return "", status.Errorf(codes.PermissionDenied, "Permission denied: %s required", rbac.PermSessionsManage)
+	}
+
+	// sessions ไม่ได้แยกตาม tenant - ตรวจสอบว่า user อยู่ใน tenant ของผู้เรียกก่อน
+	if !principal.IsSelf(requestedID) && !h.service.isTenantUser(ctx, requestedID) {
+		log.Printf("❌ Access denied - user %s is not in tenant %s", requestedID, principal.TenantID)
+		return "", status.Errorf(codes.NotFound, "User not found")
+	}
+	return requestedID, nil
+}
+
+// isStatusError - ตรวจสอบว่า service คืน gRPC status error ที่ควรส่งต่อให้ client
+func isStatusError(err error) bool {
+	if err == nil {
+		return false
+	}
+	_, ok := status.FromError(err)
+	return ok
+}
+
+// UnlockAccount - gRPC handler สำหรับปลดล็อคบัญชีที่ถูกล็อคจาก login ผิด (ต้องมีสิทธิ์ accounts:unlock)
+func (h *Handler) UnlockAccount(ctx context.Context, req *auth.UnlockAccountRequest) (*auth.UnlockAccountResponse, error) {
+	log.Printf("🔓 UnlockAccount request for user: %s", req.UserId)
+
+	if req.UserId == "" {
+		return &auth.UnlockAccountResponse{
+			Success: false,
+			Message: "User ID is required",
+		}, nil
+	}
+
+	result, err := h.service.UnlockAccount(ctx, req.UserId)
+	if err != nil {
+		log.Printf("❌ UnlockAccount service error: %v", err)
+		return &auth.UnlockAccountResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	return &auth.UnlockAccountResponse{
+		Success: result.Success,
+		Message: result.Message,
+	}, nil
+}
+
+// VerifyMFA - gRPC handler สำหรับยืนยัน MFA หลัง Login (ขั้นที่สอง)
+func (h *Handler) VerifyMFA(ctx context.Context, req *auth.VerifyMFARequest) (*auth.LoginResponse, error) {
+	log.Printf("🔐 VerifyMFA request received")
+
+	result, err := h.service.VerifyMFA(ctx, req.MfaToken, req.Code)
+	if isStatusError(err) {
+		log.Printf("❌ VerifyMFA rejected - %v", err)
+		return nil, err
+	}
+	if err != nil {
+		log.Printf("❌ VerifyMFA service error: %v", err)
+		return &auth.LoginResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	if result.Success {
+		log.Printf("✅ VerifyMFA successful")
+	} else {
+		log.Printf("❌ VerifyMFA failed - %s", result.Message)
+	}
+
+	return &auth.LoginResponse{
+		Success:      result.Success,
+		Message:      result.Message,
+		Token:        result.Token,
+		RefreshToken: result.RefreshToken,
+		ExpiresIn:    result.ExpiresIn,
+	}, nil
+}
+
+// EnrollTOTP - gRPC handler สำหรับเริ่มตั้งค่า TOTP ของผู้เรียก
+func (h *Handler) EnrollTOTP(ctx context.Context, req *auth.EnrollTOTPRequest) (*auth.EnrollTOTPResponse, error) {
+	userID, ok := middleware.UserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "Authentication required")
+	}
+	log.Printf("🔐 EnrollTOTP request for user: %s", userID)
+
+	result, err := h.service.EnrollTOTP(ctx, userID)
+	if err != nil {
+		log.Printf("❌ EnrollTOTP service error: %v", err)
+		return &auth.EnrollTOTPResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	return &auth.EnrollTOTPResponse{
+		Success: result.Success,
+		Message: result.Message,
+		Secret:  result.Secret,
+		Uri:     result.URI,
+	}, nil
+}
+
+// ConfirmTOTP - gRPC handler สำหรับยืนยัน TOTP และเปิดใช้ MFA
+func (h *Handler) ConfirmTOTP(ctx context.Context, req *auth.ConfirmTOTPRequest) (*auth.ConfirmTOTPResponse, error) {
+	userID, ok := middleware.UserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "Authentication required")
+	}
+	log.Printf("🔐 ConfirmTOTP request for user: %s", userID)
+
+	result, err := h.service.ConfirmTOTP(ctx, userID, req.Code)
+	if err != nil {
+		log.Printf("❌ ConfirmTOTP service error: %v", err)
+		return &auth.ConfirmTOTPResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	return &auth.ConfirmTOTPResponse{
+		Success:       result.Success,
+		Message:       result.Message,
+		RecoveryCodes: result.RecoveryCodes,
+	}, nil
+}
+
+// DisableTOTP - gRPC handler สำหรับปิด MFA ของผู้เรียก
+func (h *Handler) DisableTOTP(ctx context.Context, req *auth.DisableTOTPRequest) (*auth.DisableTOTPResponse, error) {
+	userID, ok := middleware.UserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "Authentication required")
+	}
+	log.Printf("🔐 DisableTOTP request for user: %s", userID)
+
+	result, err := h.service.DisableTOTP(ctx, userID, req.Code)
+	if err != nil {
+		log.Printf("❌ DisableTOTP service error: %v", err)
+		return &auth.DisableTOTPResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	return &auth.DisableTOTPResponse{
+		Success: result.Success,
+		Message: result.Message,
+	}, nil
+}
+
+// BeginPasskeyRegistration - gRPC handler สำหรับเริ่มลงทะเบียน passkey ของผู้เรียก
+func (h *Handler) BeginPasskeyRegistration(ctx context.Context, req *auth.BeginPasskeyRegistrationRequest) (*auth.PasskeyCeremonyResponse, error) {
+	userID, ok := middleware.UserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "Authentication required")
+	}
+	log.Printf("🔑 BeginPasskeyRegistration request for user: %s", userID)
+
+	result, err := h.service.BeginPasskeyRegistration(ctx, userID, req.Name)
+	if err != nil {
+		log.Printf("❌ BeginPasskeyRegistration service error: %v", err)
+		return &auth.PasskeyCeremonyResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	return toPasskeyCeremonyResponse(result), nil
+}
+
+// FinishPasskeyRegistration - gRPC handler สำหรับบันทึก passkey หลัง authenticator สร้าง credential
+func (h *Handler) FinishPasskeyRegistration(ctx context.Context, req *auth.FinishPasskeyRegistrationRequest) (*auth.FinishPasskeyRegistrationResponse, error) {
+	userID, ok := middleware.UserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "Authentication required")
+	}
+	log.Printf("🔑 FinishPasskeyRegistration request for user: %s", userID)
+
+	result, err := h.service.FinishPasskeyRegistration(ctx, userID, req.CeremonyId, req.CredentialJson)
+	if err != nil {
+		log.Printf("❌ FinishPasskeyRegistration service error: %v", err)
+		return &auth.FinishPasskeyRegistrationResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	return &auth.FinishPasskeyRegistrationResponse{
+		Success:      result.Success,
+		Message:      result.Message,
+		CredentialId: result.CredentialID,
+	}, nil
+}
+
+// BeginPasskeyLogin - gRPC handler สำหรับเริ่ม login ด้วย passkey
+func (h *Handler) BeginPasskeyLogin(ctx context.Context, req *auth.BeginPasskeyLoginRequest) (*auth.PasskeyCeremonyResponse, error) {
+	log.Printf("🔑 BeginPasskeyLogin request received")
+
+	result, err := h.service.BeginPasskeyLogin(ctx, req.Tenant, req.MfaToken, req.Audience)
+	if err != nil {
+		log.Printf("❌ BeginPasskeyLogin service error: %v", err)
+		return &auth.PasskeyCeremonyResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	return toPasskeyCeremonyResponse(result), nil
+}
+
+// FinishPasskeyLogin - gRPC handler สำหรับตรวจสอบ passkey และออก token
+func (h *Handler) FinishPasskeyLogin(ctx context.Context, req *auth.FinishPasskeyLoginRequest) (*auth.LoginResponse, error) {
+	log.Printf("🔑 FinishPasskeyLogin request received")
+
+	result, err := h.service.FinishPasskeyLogin(ctx, req.CeremonyId, req.CredentialJson, req.MfaToken)
+	if isStatusError(err) {
+		log.Printf("❌ FinishPasskeyLogin rejected - %v", err)
+		return nil, err
+	}
+	if err != nil {
+		log.Printf("❌ FinishPasskeyLogin service error: %v", err)
+		return &auth.LoginResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	if result.Success {
+		log.Printf("✅ FinishPasskeyLogin successful")
+	} else {
+		log.Printf("❌ FinishPasskeyLogin failed - %s", result.Message)
+	}
+
+	return &auth.LoginResponse{
+		Success:      result.Success,
+		Message:      result.Message,
+		Token:        result.Token,
+		RefreshToken: result.RefreshToken,
+		ExpiresIn:    result.ExpiresIn,
+	}, nil
+}
+
+func toPasskeyCeremonyResponse(result *PasskeyCeremonyResponse) *auth.PasskeyCeremonyResponse {
+	return &auth.PasskeyCeremonyResponse{
+		Success:     result.Success,
+		Message:     result.Message,
+		CeremonyId:  result.CeremonyID,
+		OptionsJson: result.OptionsJSON,
+	}
+}
+
+// VerifyEmail - gRPC handler สำหรับยืนยัน email จากลิงก์
+func (h *Handler) VerifyEmail(ctx context.Context, req *auth.VerifyEmailRequest) (*auth.VerifyEmailResponse, error) {
+	log.Printf("📧 VerifyEmail request received")
+
+	result, err := h.service.VerifyEmail(ctx, req.Token)
+	if err != nil {
+		log.Printf("❌ VerifyEmail service error: %v", err)
+		return &auth.VerifyEmailResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	return &auth.VerifyEmailResponse{
+		Success: result.Success,
+		Message: result.Message,
+	}, nil
+}
+
+// ResendVerification - gRPC handler สำหรับขอลิงก์ยืนยัน email ใหม่
+func (h *Handler) ResendVerification(ctx context.Context, req *auth.ResendVerificationRequest) (*auth.ResendVerificationResponse, error) {
+	log.Printf("📧 ResendVerification request for: %s", req.Email)
+
+	result, err := h.service.ResendVerification(ctx, req.Tenant, req.Email)
+	if isStatusError(err) {
+		log.Printf("❌ ResendVerification rejected - %v", err)
+		return nil, err
+	}
+	if err != nil {
+		log.Printf("❌ ResendVerification service error: %v", err)
+		return &auth.ResendVerificationResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	return &auth.ResendVerificationResponse{
+		Success: result.Success,
+		Message: result.Message,
+	}, nil
+}
+
+// RequestPasswordReset - gRPC handler สำหรับขอลิงก์ reset รหัสผ่าน
+func (h *Handler) RequestPasswordReset(ctx context.Context, req *auth.RequestPasswordResetRequest) (*auth.RequestPasswordResetResponse, error) {
+	log.Printf("🔑 RequestPasswordReset request for: %s", req.Email)
+
+	result, err := h.service.RequestPasswordReset(ctx, req.Tenant, req.Email)
+	if isStatusError(err) {
+		log.Printf("❌ RequestPasswordReset rejected - %v", err)
+		return nil, err
+	}
+	if err != nil {
+		log.Printf("❌ RequestPasswordReset service error: %v", err)
+		return &auth.RequestPasswordResetResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	return &auth.RequestPasswordResetResponse{
+		Success: result.Success,
+		Message: result.Message,
+	}, nil
+}
+
+// ResetPassword - gRPC handler สำหรับตั้งรหัสผ่านใหม่ด้วย token จาก email
+func (h *Handler) ResetPassword(ctx context.Context, req *auth.ResetPasswordRequest) (*auth.ResetPasswordResponse, error) {
+	log.Printf("🔑 ResetPassword request received")
+
+	result, err := h.service.ResetPassword(ctx, req.Token, req.NewPassword)
+	if err != nil {
+		if isStatusError(err) {
+			return nil, err
+		}
+		log.Printf("❌ ResetPassword service error: %v", err)
+		return &auth.ResetPasswordResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	if result.Success {
+		log.Printf("✅ ResetPassword successful")
+	} else {
+		log.Printf("❌ ResetPassword failed - %s", result.Message)
+	}
+
+	return &auth.ResetPasswordResponse{
+		Success: result.Success,
+		Message: result.Message,
+	}, nil
+}
+
+// ChangePassword - เปลี่ยนรหัสผ่านของผู้เรียก
+func (h *Handler) ChangePassword(ctx context.Context, req *auth.ChangePasswordRequest) (*auth.ChangePasswordResponse, error) {
+	userID, ok := middleware.UserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "Authentication required")
+	}
+	log.Printf("🔑 ChangePassword request for user: %s", userID)
+
+	sessionID, _ := middleware.SessionIDFromContext(ctx)
+	result, err := h.service.ChangePassword(ctx, userID, sessionID, req.CurrentPassword, req.NewPassword, req.SignOutOtherSessions)
+	if err != nil {
+		if isStatusError(err) {
+			return nil, err
+		}
+		log.Printf("❌ ChangePassword service error: %v", err)
+		return &auth.ChangePasswordResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	if result.Success {
+		log.Printf("✅ ChangePassword successful for user: %s (signed out %d sessions)", userID, result.SignedOutSessions)
+	} else {
+		log.Printf("❌ ChangePassword failed for user: %s - %s", userID, result.Message)
+	}
+
+	return &auth.ChangePasswordResponse{
+		Success:           result.Success,
+		Message:           result.Message,
+		SignedOutSessions: result.SignedOutSessions,
+	}, nil
+}
+
+// ListRoles - gRPC handler สำหรับแสดง role ทั้งหมด (ต้องมีสิทธิ์ roles:read)
+func (h *Handler) ListRoles(ctx context.Context, req *auth.ListRolesRequest) (*auth.ListRolesResponse, error) {
+	log.Printf("🛡️  ListRoles request received")
+
+	roles, err := h.service.ListRoles(ctx)
+	if err != nil {
+		log.Printf("❌ ListRoles service error: %v", err)
+		return nil, status.Errorf(codes.Internal, "Failed to list roles")
+	}
+
+	protoRoles := make([]*auth.Role, 0, len(roles))
+	for i := range roles {
+		protoRoles = append(protoRoles, toProtoRole(&roles[i]))
+	}
+
+	log.Printf("✅ ListRoles successful - Found %d roles", len(protoRoles))
+	return &auth.ListRolesResponse{
+		Roles:                protoRoles,
+		AvailablePermissions: rbac.KnownPermissions,
+	}, nil
+}
+
+// CreateRole - gRPC handler สำหรับสร้าง role (ต้องมีสิทธิ์ roles:manage)
+func (h *Handler) CreateRole(ctx context.Context, req *auth.CreateRoleRequest) (*auth.RoleResponse, error) {
+	log.Printf("🛡️  CreateRole request for role: %s", req.Name)
+
+	actorID, _ := middleware.UserIDFromContext(ctx)
+	result, err := h.service.CreateRole(ctx, actorID, req.Name, req.Description, req.Permissions)
+	if err != nil {
+		log.Printf("❌ CreateRole service error: %v", err)
+		return &auth.RoleResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	return toRoleResponse("CreateRole", req.Name, result), nil
+}
+
+// UpdateRole - gRPC handler สำหรับแก้ไขสิทธิ์ของ role (ต้องมีสิทธิ์ roles:manage)
+func (h *Handler) UpdateRole(ctx context.Context, req *auth.UpdateRoleRequest) (*auth.RoleResponse, error) {
+	log.Printf("🛡️  UpdateRole request for role: %s", req.Name)
+
+	actorID, _ := middleware.UserIDFromContext(ctx)
+	result, err := h.service.UpdateRole(ctx, actorID, req.Name, req.Description, req.Permissions)
+	if err != nil {
+		log.Printf("❌ UpdateRole service error: %v", err)
+		return &auth.RoleResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	return toRoleResponse("UpdateRole", req.Name, result), nil
+}
+
+// DeleteRole - gRPC handler สำหรับลบ role (ต้องมีสิทธิ์ roles:manage)
+func (h *Handler) DeleteRole(ctx context.Context, req *auth.DeleteRoleRequest) (*auth.DeleteRoleResponse, error) {
+	log.Printf("🛡️  DeleteRole request for role: %s", req.Name)
+
+	actorID, _ := middleware.UserIDFromContext(ctx)
+	result, err := h.service.DeleteRole(ctx, actorID, req.Name)
+	if err != nil {
+		log.Printf("❌ DeleteRole service error: %v", err)
+		return &auth.DeleteRoleResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, nil
+	}
+
+	if result.Success {
+		log.Printf("✅ DeleteRole successful for role: %s", req.Name)
+	} else {
+		log.Printf("❌ DeleteRole failed for role: %s - %s", req.Name, result.Message)
+	}
+
+	return &auth.DeleteRoleResponse{
+		Success: result.Success,
+		Message: result.Message,
+	}, nil
+}
+
+// toRoleResponse - แปลงผลของ CreateRole/UpdateRole เป็น proto
+func toRoleResponse(method, name string, result *RoleResponse) *auth.RoleResponse {
+	if !result.Success {
+		log.Printf("❌ %s failed for role: %s - %s", method, name, result.Message)
+		return &auth.RoleResponse{
+			Success: result.Success,
+			Message: result.Message,
+		}
+	}
+
+	log.Printf("✅ %s successful for role: %s", method, name)
+	return &auth.RoleResponse{
+		Success: result.Success,
+		Message: result.Message,
+		Role:    toProtoRole(result.Role),
+	}
+}
+
+func toProtoRole(role *rbac.Role) *auth.Role {
+	return &auth.Role{
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: role.Permissions,
+		Builtin:     role.Builtin,
+		CreatedAt:   role.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   role.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"auth-microservice/internal/models"
+	"auth-microservice/internal/password"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// hashingRetryAfter - เวลาที่แนะนำให้ client รอก่อนลองใหม่เมื่อคิว hash เต็ม
+const hashingRetryAfter = time.Second
+
+const serverBusyMessage = "Server is busy. Please try again later."
+
+// hashingFailure - แปลง error จาก hashing pool เป็นข้อความและ gRPC status
+// คิวเต็ม = ResourceExhausted (พร้อม retry-after), หมดเวลา = Unavailable
+func hashingFailure(ctx context.Context, err error) (string, error) {
+	switch {
+	case errors.Is(err, password.ErrPoolFull):
+		return serverBusyMessage, rateLimitError(ctx, serverBusyMessage, hashingRetryAfter)
+	case errors.Is(err, password.ErrPoolTimeout), errors.Is(err, password.ErrPoolClosed):
+		return serverBusyMessage, status.Error(codes.Unavailable, serverBusyMessage)
+	}
+	return "Internal server error", err
+}
+
+// rehashPassword - เปลี่ยน hash ให้เป็น algorithm และ parameters ปัจจุบัน (ล้มเหลวไม่กระทบการ login)
+func (s *Service) rehashPassword(ctx context.Context, user *models.User, plain string) {
+	hasher := s.passwords.Hasher()
+	if !hasher.NeedsRehash(user.PasswordHash) {
+		return
+	}
+
+	oldHash := user.PasswordHash
+	if err := user.HashPassword(ctx, s.passwords, plain); err != nil {
+		log.Printf("Failed to rehash password for user %s: %v", user.Email, err)
+		return
+	}
+
+	if err := s.userRepo.RehashPassword(ctx, user.ID.Hex(), oldHash, user.PasswordHash); err != nil {
+		log.Printf("Failed to store rehashed password for user %s: %v", user.Email, err)
+		return
+	}
+
+	log.Printf("Password rehashed with %s for user: %s", hasher.Current().Name(), user.Email)
+}
@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"auth-microservice/internal/middleware"
+	"auth-microservice/internal/password"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// updateCommand - filter และ update ของคำสั่ง update แรกใน collection
+func updateCommand(mt *mtest.T, collection string) (bson.Raw, bson.Raw, bool) {
+	for _, evt := range mt.GetAllStartedEvents() {
+		if evt.CommandName != "update" || evt.Command.Lookup("update").StringValue() != collection {
+			continue
+		}
+		updates, err := evt.Command.Lookup("updates").Array().Values()
+		if err != nil || len(updates) == 0 {
+			mt.Fatalf("update of %s has no statements: %v", collection, err)
+		}
+		stmt := updates[0].Document()
+		return stmt.Lookup("q").Document(), stmt.Lookup("u").Document(), true
+	}
+	return nil, nil, false
+}
+
+func TestRehashPassword(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	const plain = "correct-password"
+
+	argon2id := password.NewArgon2id(password.Argon2idParams{Memory: 64, Time: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+	legacy := password.NewBcrypt(bcrypt.MinCost)
+
+	newPool := func() *password.Pool {
+		return password.NewPool(password.NewHasher(argon2id, legacy), password.PoolConfig{Workers: 1, QueueSize: 1})
+	}
+
+	mt.Run("legacy hash", func(mt *mtest.T) {
+		pool := newPool()
+		defer pool.Close()
+		s := newTestService(mt, Dependencies{Passwords: pool}, Options{})
+
+		legacyPool := password.NewPool(password.NewHasher(legacy), password.PoolConfig{})
+		defer legacyPool.Close()
+		account := testUser(mt, legacyPool, "alice@example.com", plain)
+		oldHash := account.PasswordHash
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		ctx := middleware.WithTenant(context.Background(), testTenant)
+		s.rehashPassword(ctx, account, plain)
+
+		if !strings.HasPrefix(account.PasswordHash, "$argon2id$") {
+			t.Fatalf("PasswordHash = %q, want an argon2id hash", account.PasswordHash)
+		}
+		if ok, err := pool.Hasher().Verify(account.PasswordHash, plain); err != nil || !ok {
+			t.Errorf("new hash does not verify: (%t, %v)", ok, err)
+		}
+
+		filter, update, ok := updateCommand(mt, "users")
+		if !ok {
+			t.Fatal("rehashed password was not stored")
+		}
+		// เขียนทับเฉพาะเมื่อ hash ยังเป็นค่าเดิม (ไม่ทับรหัสผ่านที่เปลี่ยนไประหว่างนั้น)
+		if got := filter.Lookup("password_hash").StringValue(); got != oldHash {
+			t.Errorf("filter password_hash = %q, want the old hash %q", got, oldHash)
+		}
+		if got := filter.Lookup("_id").ObjectID(); got != account.ID {
+			t.Errorf("filter _id = %s, want %s", got.Hex(), account.ID.Hex())
+		}
+		if got := filter.Lookup("tenant_id").StringValue(); got != testTenant {
+			t.Errorf("filter tenant_id = %q, want %q", got, testTenant)
+		}
+		if got := update.Lookup("$set", "password_hash").StringValue(); got != account.PasswordHash {
+			t.Errorf("$set password_hash = %q, want %q", got, account.PasswordHash)
+		}
+	})
+
+	mt.Run("current hash", func(mt *mtest.T) {
+		pool := newPool()
+		defer pool.Close()
+		s := newTestService(mt, Dependencies{Passwords: pool}, Options{})
+
+		account := testUser(mt, pool, "alice@example.com", plain)
+		current := account.PasswordHash
+
+		ctx := middleware.WithTenant(context.Background(), testTenant)
+		s.rehashPassword(ctx, account, plain)
+
+		if account.PasswordHash != current {
+			t.Errorf("PasswordHash changed from %q to %q", current, account.PasswordHash)
+		}
+		if _, _, ok := updateCommand(mt, "users"); ok {
+			t.Error("current hash was rewritten")
+		}
+	})
+
+	mt.Run("outdated parameters", func(mt *mtest.T) {
+		pool := newPool()
+		defer pool.Close()
+		s := newTestService(mt, Dependencies{Passwords: pool}, Options{})
+
+		weaker := password.NewArgon2id(password.Argon2idParams{Memory: 32, Time: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+		weakerPool := password.NewPool(password.NewHasher(weaker), password.PoolConfig{})
+		defer weakerPool.Close()
+		account := testUser(mt, weakerPool, "alice@example.com", plain)
+		oldHash := account.PasswordHash
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		ctx := middleware.WithTenant(context.Background(), testTenant)
+		s.rehashPassword(ctx, account, plain)
+
+		if account.PasswordHash == oldHash || pool.Hasher().NeedsRehash(account.PasswordHash) {
+			t.Errorf("PasswordHash = %q, want a hash with the current parameters", account.PasswordHash)
+		}
+		if _, update, ok := updateCommand(mt, "users"); !ok || update.Lookup("$set", "password_hash").StringValue() != account.PasswordHash {
+			t.Error("rehashed password was not stored")
+		}
+	})
+}
@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"auth-microservice/internal/mailer"
+	"auth-microservice/internal/models"
+	"auth-microservice/internal/password"
+	"auth-microservice/internal/ratelimit"
+	"auth-microservice/internal/tenant"
+	"auth-microservice/internal/user"
+	"auth-microservice/pkg/db"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testTenant - tenant ที่ใช้ใน test ทั้งหมด
+const testTenant = "default"
+
+// newTestService - auth service ที่ต่อกับ mock deployment ของ mtest (ตอบ command ตามลำดับที่ AddMockResponses)
+// dependency ที่ไม่ได้กำหนดใน deps จะใช้ค่าที่ test ส่วนใหญ่ต้องการ
+func newTestService(mt *mtest.T, deps Dependencies, opts Options) *Service {
+	database := &db.MongoDB{Client: mt.Client, Database: mt.DB}
+
+	deps.DB = database
+	if deps.UserRepo == nil {
+		deps.UserRepo = user.NewRepository(database)
+	}
+	if deps.Tenants == nil {
+		deps.Tenants = tenant.NewStore(database)
+	}
+	if deps.RateLimiter == nil {
+		deps.RateLimiter = ratelimit.NewLimiter(database)
+	}
+	if deps.Mailer == nil {
+		deps.Mailer = &recordingMailer{}
+	}
+	if deps.Passwords == nil {
+		deps.Passwords = password.NewPool(password.NewHasher(password.NewBcrypt(bcrypt.MinCost)), password.PoolConfig{})
+	}
+	if opts.DefaultTenant == "" {
+		opts.DefaultTenant = testTenant
+	}
+
+	return NewService(deps, opts)
+}
+
+// cursorResponse - ผลของ find (รวมถึง FindOne) ใน collection ของ mock database
+func cursorResponse(mt *mtest.T, collection string, docs ...bson.D) bson.D {
+	return mtest.CreateCursorResponse(0, mt.DB.Name()+"."+collection, mtest.FirstBatch, docs...)
+}
+
+// findAndModifyResponse - ผลของ FindOneAndUpdate/FindOneAndDelete ที่คืน doc
+func findAndModifyResponse(doc bson.D) bson.D {
+	return bson.D{{Key: "ok", Value: 1}, {Key: "value", Value: doc}}
+}
+
+// tenantDoc - tenant ที่เปิดใช้งานอยู่
+func tenantDoc(id string) bson.D {
+	now := time.Now()
+	return toDoc(&tenant.Tenant{ID: id, Name: id, IsActive: true, CreatedAt: now, UpdatedAt: now})
+}
+
+// toDoc - แปลง struct เป็น bson.D ตาม bson tags (ใช้สร้างผลของ mock)
+func toDoc(v interface{}) bson.D {
+	raw, err := bson.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	var doc bson.D
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		panic(err)
+	}
+	return doc
+}
+
+// testUser - user ที่ใช้งานได้ใน testTenant พร้อม hash ของรหัสผ่าน
+func testUser(t testing.TB, pool *password.Pool, email, plain string) *models.User {
+	t.Helper()
+
+	u := &models.User{
+		TenantID:  testTenant,
+		Email:     email,
+		FirstName: "Test",
+		LastName:  "User",
+		Role:      "user",
+		IsActive:  true,
+	}
+	// hash ตรงด้วย hasher ของ pool - ไม่ต้องรอ worker ว่าง (คิวของ PoolConfig{} ไม่มี buffer)
+	hashed, err := pool.Hasher().Hash(plain)
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	u.PasswordHash = hashed
+	u.ID = [12]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+	return u
+}
+
+// recordingMailer - เก็บ email ที่ส่งไว้ตรวจใน test
+type recordingMailer struct {
+	mu   sync.Mutex
+	sent []mailer.Message
+}
+
+func (m *recordingMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func (m *recordingMailer) messages() []mailer.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]mailer.Message(nil), m.sent...)
+}
+
+// slowAlgorithm - algorithm ที่ใช้เวลาคงที่ต่อการ hash/ตรวจ และนับจำนวนครั้ง
+// ใช้ตรวจว่าทุก path ทำงาน hash เท่ากัน (เวลาตอบจึงใกล้กัน) โดยไม่ขึ้นกับความเร็วของเครื่อง
+type slowAlgorithm struct {
+	password.Algorithm
+	delay    time.Duration
+	hashes   atomic.Int32
+	verifies atomic.Int32
+}
+
+func newSlowAlgorithm(delay time.Duration) *slowAlgorithm {
+	return &slowAlgorithm{Algorithm: password.NewBcrypt(bcrypt.MinCost), delay: delay}
+}
+
+func (a *slowAlgorithm) Hash(plain string) (string, error) {
+	a.hashes.Add(1)
+	time.Sleep(a.delay)
+	return a.Algorithm.Hash(plain)
+}
+
+func (a *slowAlgorithm) Verify(encoded, plain string) (bool, error) {
+	a.verifies.Add(1)
+	time.Sleep(a.delay)
+	return a.Algorithm.Verify(encoded, plain)
+}
+
+func (a *slowAlgorithm) reset() {
+	a.hashes.Store(0)
+	a.verifies.Store(0)
+}
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"auth-microservice/internal/models"
+)
+
+// maxLockoutDuration - เพดานของระยะเวลาล็อคเมื่อไม่ได้กำหนด MaxDuration
+// (time.Duration ที่เพิ่ม 2 เท่าไปเรื่อยๆ จะ overflow เป็น 0 หรือค่าติดลบ แล้วบัญชีจะไม่ถูกล็อคอีก)
+const maxLockoutDuration = 365 * 24 * time.Hour
+
+// LockoutPolicy - ล็อคบัญชีชั่วคราวหลัง login ผิดติดต่อกัน
+// ล็อคครั้งแรกเมื่อผิดครบ Threshold แล้วเพิ่มเป็น 2 เท่าทุกครั้งที่ผิดซ้ำ (ไม่เกิน MaxDuration)
+type LockoutPolicy struct {
+	Threshold    int // 0 = ปิด
+	BaseDuration time.Duration
+	MaxDuration  time.Duration // 0 = ไม่จำกัด (ใช้ maxLockoutDuration)
+}
+
+// lockDuration - ระยะเวลาล็อคหลังผิดติดต่อกัน failures ครั้ง (0 = ยังไม่ล็อค)
+func (p LockoutPolicy) lockDuration(failures int) time.Duration {
+	if p.Threshold <= 0 || failures < p.Threshold {
+		return 0
+	}
+
+	limit := p.MaxDuration
+	if limit <= 0 || limit > maxLockoutDuration {
+		limit = maxLockoutDuration
+	}
+
+	d := p.BaseDuration
+	for i := p.Threshold; i < failures && d < limit; i++ {
+		d *= 2
+	}
+	if d > limit {
+		return limit
+	}
+	return d
+}
+
+// recordFailedLogin - นับ login ผิดและล็อคบัญชีเมื่อถึง threshold
+func (s *Service) recordFailedLogin(ctx context.Context, user *models.User) {
+	userID := user.ID.Hex()
+
+	failures, err := s.userRepo.RecordFailedLogin(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to record failed login for user %s: %v", userID, err)
+		return
+	}
+
+	d := s.opts.Lockout.lockDuration(failures)
+	if d == 0 {
+		return
+	}
+
+	if err := s.userRepo.Lock(ctx, userID, time.Now().Add(d)); err != nil {
+		log.Printf("Failed to lock user %s: %v", userID, err)
+		return
+	}
+
+	log.Printf("⚠️  Account locked for %s after %d failed login attempts: %s", d, failures, user.Email)
+}
+
+// UnlockAccount - ปลดล็อคบัญชีและล้างจำนวน login ผิด (admin)
+func (s *Service) UnlockAccount(ctx context.Context, userID string) (*UnlockAccountResponse, error) {
+	if err := s.userRepo.ResetFailedLogins(ctx, userID); err != nil {
+		log.Printf("Failed to unlock user %s: %v", userID, err)
+		return &UnlockAccountResponse{
+			Success: false,
+			Message: "User not found",
+		}, nil
+	}
+
+	log.Printf("Account unlocked: %s", userID)
+
+	return &UnlockAccountResponse{
+		Success: true,
+		Message: "Account unlocked successfully",
+	}, nil
+}
@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLockDuration(t *testing.T) {
+	policy := LockoutPolicy{Threshold: 5, BaseDuration: time.Minute, MaxDuration: time.Hour}
+	unbounded := LockoutPolicy{Threshold: 5, BaseDuration: time.Minute}
+
+	tests := []struct {
+		name     string
+		policy   LockoutPolicy
+		failures int
+		want     time.Duration
+	}{
+		{"disabled", LockoutPolicy{BaseDuration: time.Minute}, 100, 0},
+		{"below threshold", policy, 4, 0},
+		{"at threshold", policy, 5, time.Minute},
+		{"doubles", policy, 6, 2 * time.Minute},
+		{"doubles again", policy, 8, 8 * time.Minute},
+		{"capped", policy, 12, time.Hour},
+		{"capped after many failures", policy, 1000, time.Hour},
+		{"unbounded doubles", unbounded, 15, 1024 * time.Minute},
+		// ก่อนมีเพดาน d *= 2 overflow หลังผิดราว 40 ครั้ง ทำให้ไม่ล็อคอีก
+		{"unbounded does not overflow", unbounded, 45, maxLockoutDuration},
+		{"unbounded after many failures", unbounded, 1 << 20, maxLockoutDuration},
+		{"max above ceiling", LockoutPolicy{Threshold: 5, BaseDuration: time.Minute, MaxDuration: 100 * 365 * 24 * time.Hour}, 100, maxLockoutDuration},
+		{"base above max", LockoutPolicy{Threshold: 1, BaseDuration: 2 * time.Hour, MaxDuration: time.Hour}, 1, time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.lockDuration(tt.failures); got != tt.want {
+				t.Errorf("lockDuration(%d) = %s, want %s", tt.failures, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,405 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"auth-microservice/internal/mfa"
+	"auth-microservice/internal/models"
+	"auth-microservice/internal/tenant"
+	"auth-microservice/pkg/jwt"
+)
+
+// ค่า amr (RFC 8176) ที่ใส่ใน access token
+const (
+	amrPassword    = "pwd"
+	amrOTP         = "otp"
+	amrHardwareKey = "hwk"
+	amrMFA         = "mfa"
+)
+
+// วิธียืนยันตัวตนขั้นที่สองที่แจ้ง client ใน LoginResponse
+const (
+	mfaMethodTOTP     = "totp"
+	mfaMethodWebAuthn = "webauthn"
+)
+
+// mfaMethods - วิธี MFA ที่ user ตั้งค่าไว้ (ว่าง = ไม่ได้เปิดใช้ MFA)
+func (s *Service) mfaMethods(ctx context.Context, user *models.User) ([]string, error) {
+	var methods []string
+	if user.TOTPEnabled {
+		methods = append(methods, mfaMethodTOTP)
+	}
+
+	passkeys, err := s.passkeys.CountByUser(ctx, user.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	if passkeys > 0 {
+		methods = append(methods, mfaMethodWebAuthn)
+	}
+	return methods, nil
+}
+
+// issueMFAChallenge - ออก challenge token อายุสั้นหลังตรวจสอบรหัสผ่านผ่าน
+func (s *Service) issueMFAChallenge(user *models.User, audience string, methods []string) (*LoginResponse, error) {
+	challenge, err := s.jwtService.GenerateToken(user.ID.Hex(), user.Email, user.Role,
+		jwt.WithTokenUse(jwt.TokenUseMFAChallenge, s.opts.MFAChallengeTTL),
+		jwt.WithTenantID(user.TenantID),
+		jwt.WithTokenVersion(user.TokenVersion),
+		jwt.WithAudience(audience),
+		jwt.WithAMR(amrPassword),
+	)
+	if err != nil {
+		log.Printf("Failed to generate MFA challenge for user %s: %v", user.Email, err)
+		return &LoginResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	log.Printf("MFA required for user: %s", user.Email)
+
+	return &LoginResponse{
+		Success:     false,
+		Message:     "MFA verification required",
+		MFARequired: true,
+		MFAToken:    challenge,
+		MFAMethods:  methods,
+		ExpiresIn:   int64(s.opts.MFAChallengeTTL.Seconds()),
+	}, nil
+}
+
+// errInvalidMFAToken - challenge token ไม่ถูกต้อง หมดอายุ หรือถูกใช้ไปแล้ว
+var errInvalidMFAToken = errors.New("invalid MFA token")
+
+// loadMFAChallenge - ตรวจสอบ challenge token และโหลด user เจ้าของ
+// คืน context ที่มี tenant ของ user สำหรับใช้ต่อใน flow เดียวกัน
+func (s *Service) loadMFAChallenge(ctx context.Context, mfaToken string) (context.Context, *jwt.Claims, *models.User, error) {
+	claims, err := s.jwtService.ValidateTokenUse(mfaToken, jwt.TokenUseMFAChallenge)
+	if err != nil {
+		return ctx, nil, nil, errInvalidMFAToken
+	}
+
+	// challenge ใช้ได้ครั้งเดียว
+	revoked, err := s.revocations.IsRevoked(ctx, mfaToken)
+	if err != nil {
+		return ctx, nil, nil, err
+	}
+	if revoked {
+		return ctx, nil, nil, errInvalidMFAToken
+	}
+
+	ctx, err = s.tenantContext(ctx, claims.TenantID)
+	if err == tenant.ErrTenantNotFound {
+		return ctx, nil, nil, errInvalidMFAToken
+	}
+	if err != nil {
+		return ctx, nil, nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil || user.TokenVersion != claims.TokenVersion {
+		return ctx, nil, nil, errInvalidMFAToken
+	}
+
+	return ctx, claims, user, nil
+}
+
+// consumeMFAChallenge - ใช้ challenge token (atomic) ก่อนตรวจ factor ที่สอง
+// request ที่ใช้ challenge เดียวกันพร้อมกัน (เช่น TOTP กับ recovery code) จะผ่านได้เพียงตัวเดียว
+// ตรวจ factor ไม่ผ่านก็เสีย challenge ไปแล้ว - ต้อง login ด้วยรหัสผ่านใหม่ (เดาได้ครั้งเดียวต่อรหัสผ่าน)
+func (s *Service) consumeMFAChallenge(ctx context.Context, claims *jwt.Claims, mfaToken string) error {
+	first, err := s.revocations.Consume(ctx, mfaToken, claims.ExpiresAt.Time)
+	if err != nil {
+		log.Printf("Failed to consume MFA challenge: %v", err)
+		return err
+	}
+	if !first {
+		return errInvalidMFAToken
+	}
+	return nil
+}
+
+// completeMFALogin - ออก access token + refresh token หลังผ่าน factor ที่สอง (challenge ถูกใช้ไปแล้ว)
+func (s *Service) completeMFALogin(ctx context.Context, user *models.User, claims *jwt.Claims, amr []string) (*LoginResponse, error) {
+	if user.FailedLoginAttempts > 0 {
+		if err := s.userRepo.ResetFailedLogins(ctx, user.ID.Hex()); err != nil {
+			log.Printf("Failed to reset failed logins for user %s: %v", user.Email, err)
+		}
+	}
+
+	tokens, err := s.issueTokens(ctx, user, "", s.jwtService.RequestedAudience(claims), amr)
+	if err != nil {
+		log.Printf("Failed to generate token for user %s: %v", user.Email, err)
+		return &LoginResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	log.Printf("MFA login successful for user: %s", user.Email)
+
+	return &LoginResponse{
+		Success:      true,
+		Message:      "Login successful",
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		User:         user.ToSafeUser(),
+	}, nil
+}
+
+// VerifyMFA - ยืนยัน TOTP code หรือ recovery code แล้วออก access token (ขั้นที่สองของ Login)
+func (s *Service) VerifyMFA(ctx context.Context, mfaToken, code string) (*LoginResponse, error) {
+	if mfaToken == "" || code == "" {
+		return &LoginResponse{
+			Success: false,
+			Message: "MFA token and code are required",
+		}, nil
+	}
+
+	ctx, claims, user, err := s.loadMFAChallenge(ctx, mfaToken)
+	if err == errInvalidMFAToken || (err == nil && !user.TOTPEnabled) {
+		return &LoginResponse{
+			Success: false,
+			Message: "Invalid or expired MFA token",
+		}, nil
+	}
+	if err != nil {
+		return &LoginResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	if err := s.checkLoginRate(ctx, user.Email); err != nil {
+		return &LoginResponse{
+			Success: false,
+			Message: "Too many login attempts. Please try again later.",
+		}, err
+	}
+
+	if now := time.Now(); user.IsLocked(now) {
+		log.Printf("MFA verification failed - account locked: %s", user.Email)
+		return &LoginResponse{
+			Success: false,
+			Message: "Account is temporarily locked. Please try again later.",
+		}, rateLimitError(ctx, "Account is temporarily locked. Please try again later.", user.LockedUntil.Sub(now))
+	}
+
+	if err := s.consumeMFAChallenge(ctx, claims, mfaToken); err != nil {
+		return mfaChallengeFailure(err)
+	}
+
+	method, err := s.verifyMFACode(ctx, user, code)
+	if err != nil {
+		return &LoginResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+	if method == "" {
+		log.Printf("MFA verification failed - invalid code: %s", user.Email)
+		s.recordFailedLogin(ctx, user)
+		return &LoginResponse{
+			Success: false,
+			Message: "Invalid verification code",
+		}, nil
+	}
+
+	amr := []string{amrPassword, amrMFA}
+	if method == amrOTP {
+		amr = []string{amrPassword, amrOTP, amrMFA}
+	}
+	return s.completeMFALogin(ctx, user, claims, amr)
+}
+
+// mfaChallengeFailure - response เมื่อใช้ challenge token ไม่ได้
+func mfaChallengeFailure(err error) (*LoginResponse, error) {
+	if err == errInvalidMFAToken {
+		return &LoginResponse{
+			Success: false,
+			Message: "Invalid or expired MFA token",
+		}, nil
+	}
+	return &LoginResponse{
+		Success: false,
+		Message: "Internal server error",
+	}, err
+}
+
+// verifyMFACode - ตรวจสอบ TOTP code หรือ recovery code (ใช้ได้ครั้งเดียวทั้งคู่)
+// คืนค่า amr ของวิธีที่ใช้ (otp หรือ mfa สำหรับ recovery code) หรือค่าว่างถ้า code ไม่ถูกต้อง
+func (s *Service) verifyMFACode(ctx context.Context, user *models.User, code string) (string, error) {
+	code = strings.TrimSpace(code)
+
+	if len(code) == mfa.Digits {
+		step, ok := mfa.Validate(user.TOTPSecret, code, time.Now())
+		if !ok {
+			return "", nil
+		}
+		fresh, err := s.userRepo.UseTOTPStep(ctx, user.ID.Hex(), step)
+		if err != nil || !fresh {
+			return "", err
+		}
+		return amrOTP, nil
+	}
+
+	used, err := s.userRepo.UseRecoveryCode(ctx, user.ID.Hex(), mfa.HashRecoveryCode(code))
+	if err != nil || !used {
+		return "", err
+	}
+	log.Printf("Recovery code used by user: %s (%d remaining)", user.Email, len(user.RecoveryCodes)-1)
+	return amrMFA, nil
+}
+
+// EnrollTOTP - เริ่มตั้งค่า TOTP: สร้าง secret ใหม่ที่ต้องยืนยันด้วย ConfirmTOTP
+func (s *Service) EnrollTOTP(ctx context.Context, userID string) (*EnrollTOTPResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return &EnrollTOTPResponse{
+			Success: false,
+			Message: "User not found",
+		}, nil
+	}
+
+	if user.TOTPEnabled {
+		return &EnrollTOTPResponse{
+			Success: false,
+			Message: "MFA is already enabled",
+		}, nil
+	}
+
+	secret, err := mfa.GenerateSecret()
+	if err != nil {
+		return &EnrollTOTPResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	if err := s.userRepo.SetPendingTOTP(ctx, userID, secret); err != nil {
+		log.Printf("Failed to store TOTP secret for user %s: %v", userID, err)
+		return &EnrollTOTPResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	log.Printf("TOTP enrollment started for user: %s", user.Email)
+
+	return &EnrollTOTPResponse{
+		Success: true,
+		Message: "Scan the QR code and confirm with a code from your authenticator app",
+		Secret:  secret,
+		URI:     mfa.URI(s.opts.TOTPIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP - ยืนยัน code แรกจาก authenticator แล้วเปิดใช้ MFA พร้อมออก recovery codes
+func (s *Service) ConfirmTOTP(ctx context.Context, userID, code string) (*ConfirmTOTPResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return &ConfirmTOTPResponse{
+			Success: false,
+			Message: "User not found",
+		}, nil
+	}
+
+	if user.TOTPEnabled {
+		return &ConfirmTOTPResponse{
+			Success: false,
+			Message: "MFA is already enabled",
+		}, nil
+	}
+
+	if user.TOTPPendingSecret == "" {
+		return &ConfirmTOTPResponse{
+			Success: false,
+			Message: "No pending MFA enrollment. Call EnrollTOTP first.",
+		}, nil
+	}
+
+	step, ok := mfa.Validate(user.TOTPPendingSecret, code, time.Now())
+	if !ok {
+		return &ConfirmTOTPResponse{
+			Success: false,
+			Message: "Invalid verification code",
+		}, nil
+	}
+
+	codes, hashes, err := mfa.GenerateRecoveryCodes()
+	if err != nil {
+		return &ConfirmTOTPResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	if err := s.userRepo.EnableTOTP(ctx, userID, user.TOTPPendingSecret, step, hashes); err != nil {
+		log.Printf("Failed to enable TOTP for user %s: %v", userID, err)
+		return &ConfirmTOTPResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	log.Printf("TOTP enabled for user: %s", user.Email)
+
+	return &ConfirmTOTPResponse{
+		Success:       true,
+		Message:       "MFA enabled. Store your recovery codes in a safe place.",
+		RecoveryCodes: codes,
+	}, nil
+}
+
+// DisableTOTP - ปิด MFA (ต้องยืนยันด้วย TOTP code หรือ recovery code)
+func (s *Service) DisableTOTP(ctx context.Context, userID, code string) (*DisableTOTPResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return &DisableTOTPResponse{
+			Success: false,
+			Message: "User not found",
+		}, nil
+	}
+
+	if !user.TOTPEnabled {
+		return &DisableTOTPResponse{
+			Success: false,
+			Message: "MFA is not enabled",
+		}, nil
+	}
+
+	method, err := s.verifyMFACode(ctx, user, code)
+	if err != nil {
+		return &DisableTOTPResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+	if method == "" {
+		return &DisableTOTPResponse{
+			Success: false,
+			Message: "Invalid verification code",
+		}, nil
+	}
+
+	if err := s.userRepo.DisableTOTP(ctx, userID); err != nil {
+		log.Printf("Failed to disable TOTP for user %s: %v", userID, err)
+		return &DisableTOTPResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	log.Printf("TOTP disabled for user: %s", user.Email)
+
+	return &DisableTOTPResponse{
+		Success: true,
+		Message: "MFA disabled successfully",
+	}, nil
+}
@@ -0,0 +1,465 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"auth-microservice/internal/models"
+	"auth-microservice/internal/passkey"
+	"auth-microservice/internal/tenant"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+// defaultPasskeyName - ชื่อของ passkey เมื่อ client ไม่ได้ระบุ
+const defaultPasskeyName = "Passkey"
+
+// errPasskeyCloned - signature counter ไม่เพิ่มขึ้น (อาจมี authenticator ที่ถูก clone)
+var errPasskeyCloned = errors.New("passkey signature counter regression")
+
+// passkeyUser - user พร้อม credentials สำหรับ WebAuthn ceremony
+func (s *Service) passkeyUser(ctx context.Context, user *models.User) (*passkey.User, error) {
+	credentials, err := s.passkeys.ListByUser(ctx, user.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	return passkey.NewUser(user, credentials), nil
+}
+
+// BeginPasskeyRegistration - เริ่มลงทะเบียน passkey ใหม่ให้ผู้เรียก
+func (s *Service) BeginPasskeyRegistration(ctx context.Context, userID, name string) (*PasskeyCeremonyResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return &PasskeyCeremonyResponse{
+			Success: false,
+			Message: "User not found",
+		}, nil
+	}
+
+	wu, err := s.passkeyUser(ctx, user)
+	if err != nil {
+		return &PasskeyCeremonyResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	// ไม่ให้ลงทะเบียน authenticator เดิมซ้ำ และขอ discoverable credential เพื่อใช้ login แบบไม่ใช้รหัสผ่าน
+	creation, data, err := s.webAuthn.BeginRegistration(wu,
+		webauthn.WithExclusions(webauthn.Credentials(wu.WebAuthnCredentials()).CredentialDescriptors()),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementPreferred),
+	)
+	if err != nil {
+		return &PasskeyCeremonyResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = defaultPasskeyName
+	}
+
+	return s.saveCeremony(ctx, &passkey.Ceremony{
+		Type:   passkey.CeremonyRegistration,
+		UserID: userID,
+		Name:   name,
+	}, data, creation)
+}
+
+// FinishPasskeyRegistration - ตรวจสอบ attestation จาก authenticator แล้วบันทึก credential
+func (s *Service) FinishPasskeyRegistration(ctx context.Context, userID, ceremonyID, credentialJSON string) (*FinishPasskeyRegistrationResponse, error) {
+	ceremony, err := s.ceremonies.Consume(ctx, ceremonyID, passkey.CeremonyRegistration)
+	if err == passkey.ErrCeremonyNotFound || (err == nil && ceremony.UserID != userID) {
+		return &FinishPasskeyRegistrationResponse{
+			Success: false,
+			Message: "Invalid or expired registration ceremony",
+		}, nil
+	}
+	if err != nil {
+		return &FinishPasskeyRegistrationResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes([]byte(credentialJSON))
+	if err != nil {
+		log.Printf("Invalid passkey registration response from user %s: %v", userID, err)
+		return &FinishPasskeyRegistrationResponse{
+			Success: false,
+			Message: "Invalid credential",
+		}, nil
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return &FinishPasskeyRegistrationResponse{
+			Success: false,
+			Message: "User not found",
+		}, nil
+	}
+
+	wu, err := s.passkeyUser(ctx, user)
+	if err != nil {
+		return &FinishPasskeyRegistrationResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	data, err := ceremony.SessionData()
+	if err != nil {
+		return &FinishPasskeyRegistrationResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	credential, err := s.webAuthn.CreateCredential(wu, data, parsed)
+	if err != nil {
+		log.Printf("Passkey registration failed for user %s: %v", userID, err)
+		return &FinishPasskeyRegistrationResponse{
+			Success: false,
+			Message: "Passkey verification failed",
+		}, nil
+	}
+
+	if err := s.passkeys.Create(ctx, passkey.NewCredential(userID, ceremony.Name, credential)); err != nil {
+		log.Printf("Failed to store passkey for user %s: %v", userID, err)
+		return &FinishPasskeyRegistrationResponse{
+			Success: false,
+			Message: "Failed to register passkey",
+		}, err
+	}
+
+	log.Printf("Passkey registered for user: %s", user.Email)
+
+	return &FinishPasskeyRegistrationResponse{
+		Success:      true,
+		Message:      "Passkey registered successfully",
+		CredentialID: base64.RawURLEncoding.EncodeToString(credential.ID),
+	}, nil
+}
+
+// BeginPasskeyLogin - เริ่ม assertion
+// mfaToken ไม่ว่าง = ใช้ passkey เป็นขั้นที่สองหลังรหัสผ่าน, ว่าง = login แบบไม่ใช้รหัสผ่าน (discoverable credential)
+// tenantID ใช้กับ passwordless เท่านั้น (ขั้นที่สองใช้ tenant จาก challenge token)
+func (s *Service) BeginPasskeyLogin(ctx context.Context, tenantID, mfaToken, audience string) (*PasskeyCeremonyResponse, error) {
+	if mfaToken == "" {
+		if audience != "" && !s.jwtService.IsAllowedAudience(audience) {
+			return &PasskeyCeremonyResponse{
+				Success: false,
+				Message: "Invalid audience",
+			}, nil
+		}
+
+		_, err := s.tenantContext(ctx, tenantID)
+		if err == tenant.ErrTenantNotFound {
+			return &PasskeyCeremonyResponse{
+				Success: false,
+				Message: "Invalid tenant",
+			}, nil
+		}
+		if err != nil {
+			return &PasskeyCeremonyResponse{
+				Success: false,
+				Message: "Internal server error",
+			}, err
+		}
+
+		// ไม่มีรหัสผ่าน - authenticator ต้องยืนยันตัวผู้ใช้ (PIN/biometric) เอง
+		assertion, data, err := s.webAuthn.BeginDiscoverableLogin(
+			webauthn.WithUserVerification(protocol.VerificationRequired),
+		)
+		if err != nil {
+			return &PasskeyCeremonyResponse{
+				Success: false,
+				Message: "Internal server error",
+			}, err
+		}
+
+		return s.saveCeremony(ctx, &passkey.Ceremony{
+			Type:     passkey.CeremonyLogin,
+			TenantID: tenantID,
+			Audience: audience,
+		}, data, assertion)
+	}
+
+	ctx, _, user, err := s.loadMFAChallenge(ctx, mfaToken)
+	if err == errInvalidMFAToken {
+		return &PasskeyCeremonyResponse{
+			Success: false,
+			Message: "Invalid or expired MFA token",
+		}, nil
+	}
+	if err != nil {
+		return &PasskeyCeremonyResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	wu, err := s.passkeyUser(ctx, user)
+	if err != nil {
+		return &PasskeyCeremonyResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+	if len(wu.WebAuthnCredentials()) == 0 {
+		return &PasskeyCeremonyResponse{
+			Success: false,
+			Message: "No passkeys registered",
+		}, nil
+	}
+
+	assertion, data, err := s.webAuthn.BeginLogin(wu)
+	if err != nil {
+		return &PasskeyCeremonyResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	return s.saveCeremony(ctx, &passkey.Ceremony{
+		Type:   passkey.CeremonyLogin,
+		UserID: user.ID.Hex(),
+	}, data, assertion)
+}
+
+// FinishPasskeyLogin - ตรวจสอบ assertion แล้วออก access token แบบเดียวกับ Login
+func (s *Service) FinishPasskeyLogin(ctx context.Context, ceremonyID, credentialJSON, mfaToken string) (*LoginResponse, error) {
+	ceremony, err := s.ceremonies.Consume(ctx, ceremonyID, passkey.CeremonyLogin)
+	if err == passkey.ErrCeremonyNotFound {
+		return &LoginResponse{
+			Success: false,
+			Message: "Invalid or expired login ceremony",
+		}, nil
+	}
+	if err != nil {
+		return &LoginResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	data, err := ceremony.SessionData()
+	if err != nil {
+		return &LoginResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes([]byte(credentialJSON))
+	if err != nil {
+		return &LoginResponse{
+			Success: false,
+			Message: "Invalid credential",
+		}, nil
+	}
+
+	if ceremony.UserID != "" {
+		return s.finishPasskeySecondFactor(ctx, ceremony, data, parsed, mfaToken)
+	}
+	return s.finishPasswordlessLogin(ctx, ceremony, data, parsed)
+}
+
+// finishPasskeySecondFactor - passkey เป็นขั้นที่สองหลังตรวจสอบรหัสผ่าน
+func (s *Service) finishPasskeySecondFactor(ctx context.Context, ceremony *passkey.Ceremony, data webauthn.SessionData, parsed *protocol.ParsedCredentialAssertionData, mfaToken string) (*LoginResponse, error) {
+	ctx, claims, user, err := s.loadMFAChallenge(ctx, mfaToken)
+	if err == errInvalidMFAToken || (err == nil && user.ID.Hex() != ceremony.UserID) {
+		return &LoginResponse{
+			Success: false,
+			Message: "Invalid or expired MFA token",
+		}, nil
+	}
+	if err != nil {
+		return &LoginResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	if err := s.checkLoginRate(ctx, user.Email); err != nil {
+		return &LoginResponse{
+			Success: false,
+			Message: "Too many login attempts. Please try again later.",
+		}, err
+	}
+
+	if now := time.Now(); user.IsLocked(now) {
+		return &LoginResponse{
+			Success: false,
+			Message: "Account is temporarily locked. Please try again later.",
+		}, rateLimitError(ctx, "Account is temporarily locked. Please try again later.", user.LockedUntil.Sub(now))
+	}
+
+	if err := s.consumeMFAChallenge(ctx, claims, mfaToken); err != nil {
+		return mfaChallengeFailure(err)
+	}
+
+	wu, err := s.passkeyUser(ctx, user)
+	if err != nil {
+		return &LoginResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	credential, err := s.webAuthn.ValidateLogin(wu, data, parsed)
+	if err != nil {
+		log.Printf("Passkey verification failed for user %s: %v", user.Email, err)
+		s.recordFailedLogin(ctx, user)
+		return &LoginResponse{
+			Success: false,
+			Message: "Passkey verification failed",
+		}, nil
+	}
+
+	if err := s.recordPasskeyUse(ctx, user, credential); err != nil {
+		return &LoginResponse{
+			Success: false,
+			Message: "Passkey verification failed",
+		}, nil
+	}
+
+	return s.completeMFALogin(ctx, user, claims, []string{amrPassword, amrHardwareKey, amrMFA})
+}
+
+// finishPasswordlessLogin - login ด้วย passkey อย่างเดียว (user มาจาก user handle ของ credential)
+func (s *Service) finishPasswordlessLogin(ctx context.Context, ceremony *passkey.Ceremony, data webauthn.SessionData, parsed *protocol.ParsedCredentialAssertionData) (*LoginResponse, error) {
+	if err := s.checkLoginRate(ctx, ""); err != nil {
+		return &LoginResponse{
+			Success: false,
+			Message: "Too many login attempts. Please try again later.",
+		}, err
+	}
+
+	// credential ของ user ใน tenant อื่นจะหา user ไม่พบ
+	ctx, err := s.tenantContext(ctx, ceremony.TenantID)
+	if err == tenant.ErrTenantNotFound {
+		return &LoginResponse{
+			Success: false,
+			Message: "Passkey verification failed",
+		}, nil
+	}
+	if err != nil {
+		return &LoginResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	handler := func(rawID, userHandle []byte) (webauthn.User, error) {
+		userID, ok := passkey.UserIDFromHandle(userHandle)
+		if !ok {
+			return nil, protocol.ErrBadRequest.WithDetails("Invalid user handle")
+		}
+		user, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		return s.passkeyUser(ctx, user)
+	}
+
+	wu, credential, err := s.webAuthn.ValidatePasskeyLogin(handler, data, parsed)
+	if err != nil {
+		log.Printf("Passwordless passkey login failed: %v", err)
+		return &LoginResponse{
+			Success: false,
+			Message: "Passkey verification failed",
+		}, nil
+	}
+	user := wu.(*passkey.User).Model()
+
+	if now := time.Now(); user.IsLocked(now) {
+		return &LoginResponse{
+			Success: false,
+			Message: "Account is temporarily locked. Please try again later.",
+		}, rateLimitError(ctx, "Account is temporarily locked. Please try again later.", user.LockedUntil.Sub(now))
+	}
+
+	if err := s.recordPasskeyUse(ctx, user, credential); err != nil {
+		return &LoginResponse{
+			Success: false,
+			Message: "Passkey verification failed",
+		}, nil
+	}
+
+	if s.opts.RequireVerifiedEmail && !user.EmailVerified {
+		return &LoginResponse{
+			Success: false,
+			Message: "Email address has not been verified",
+		}, nil
+	}
+
+	// authenticator ยืนยันตัวผู้ใช้แล้ว (user verification) = ครอบครอง key + PIN/biometric
+	tokens, err := s.issueTokens(ctx, user, "", ceremony.Audience, []string{amrHardwareKey, amrMFA})
+	if err != nil {
+		log.Printf("Failed to generate token for user %s: %v", user.Email, err)
+		return &LoginResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	log.Printf("Passkey login successful for user: %s", user.Email)
+
+	return &LoginResponse{
+		Success:      true,
+		Message:      "Login successful",
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		User:         user.ToSafeUser(),
+	}, nil
+}
+
+// recordPasskeyUse - ตรวจ signature counter (authenticator ที่ถูก clone) และบันทึกค่าล่าสุด
+func (s *Service) recordPasskeyUse(ctx context.Context, user *models.User, credential *webauthn.Credential) error {
+	if credential.Authenticator.CloneWarning {
+		log.Printf("⚠️  Passkey signature counter did not increase - possible cloned authenticator for user %s", user.Email)
+		return errPasskeyCloned
+	}
+
+	if err := s.passkeys.RecordUse(ctx, credential.ID, credential); err != nil {
+		log.Printf("Failed to update passkey for user %s: %v", user.Email, err)
+		return err
+	}
+	return nil
+}
+
+// saveCeremony - เก็บ challenge และคืน options (JSON) ที่ client ส่งต่อให้ navigator.credentials
+func (s *Service) saveCeremony(ctx context.Context, ceremony *passkey.Ceremony, data *webauthn.SessionData, options interface{}) (*PasskeyCeremonyResponse, error) {
+	optionsJSON, err := json.Marshal(options)
+	if err != nil {
+		return &PasskeyCeremonyResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	ceremonyID, err := s.ceremonies.Save(ctx, ceremony, data)
+	if err != nil {
+		return &PasskeyCeremonyResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	return &PasskeyCeremonyResponse{
+		Success:     true,
+		Message:     "Ceremony started",
+		CeremonyID:  ceremonyID,
+		OptionsJSON: string(optionsJSON),
+	}, nil
+}
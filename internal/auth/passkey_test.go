@@ -0,0 +1,564 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"auth-microservice/internal/group"
+	"auth-microservice/internal/middleware"
+	"auth-microservice/internal/models"
+	"auth-microservice/internal/passkey"
+	"auth-microservice/internal/rbac"
+	"auth-microservice/internal/revocation"
+	"auth-microservice/internal/session"
+	"auth-microservice/pkg/db"
+	"auth-microservice/pkg/jwt"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// ค่า relying party ของ test
+const (
+	testRPID   = "auth.example.com"
+	testOrigin = "https://auth.example.com"
+)
+
+func TestPasskeyRegistration(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	register := func(mt *mtest.T, origin string) (*softAuthenticator, *FinishPasskeyRegistrationResponse) {
+		s := newPasskeyService(mt)
+		account := testUser(mt, s.passwords, "alice@example.com", "correct-password")
+		authenticator := newSoftAuthenticator(mt, origin)
+		ctx := middleware.WithTenant(context.Background(), testTenant)
+
+		mt.AddMockResponses(
+			cursorResponse(mt, "users", toDoc(account)),
+			cursorResponse(mt, "webauthn_credentials"),
+			mtest.CreateSuccessResponse(), // webauthn_ceremonies.insert
+		)
+		begin, err := s.BeginPasskeyRegistration(ctx, account.ID.Hex(), " Laptop ")
+		if err != nil || !begin.Success {
+			mt.Fatalf("BeginPasskeyRegistration = (%+v, %v)", begin, err)
+		}
+		ceremony := insertedDoc(mt, "webauthn_ceremonies")
+
+		mt.AddMockResponses(
+			findAndModifyResponse(ceremony),
+			cursorResponse(mt, "users", toDoc(account)),
+			cursorResponse(mt, "webauthn_credentials"),
+			mtest.CreateSuccessResponse(), // webauthn_credentials.insert
+		)
+		resp, err := s.FinishPasskeyRegistration(ctx, account.ID.Hex(), begin.CeremonyID, authenticator.create(mt, begin.OptionsJSON))
+		if err != nil {
+			mt.Fatalf("FinishPasskeyRegistration: %v", err)
+		}
+		return authenticator, resp
+	}
+
+	mt.Run("software authenticator", func(mt *mtest.T) {
+		authenticator, resp := register(mt, testOrigin)
+		if !resp.Success || resp.CredentialID != base64.RawURLEncoding.EncodeToString(authenticator.credentialID) {
+			mt.Fatalf("FinishPasskeyRegistration = %+v, want credential %x", resp, authenticator.credentialID)
+		}
+
+		var stored passkey.Credential
+		if err := bson.Unmarshal(mustMarshal(mt, insertedDoc(mt, "webauthn_credentials")), &stored); err != nil {
+			mt.Fatalf("decode stored credential: %v", err)
+		}
+		if stored.Name != "Laptop" || string(stored.CredentialID) != string(authenticator.credentialID) || stored.SignCount != 0 {
+			mt.Errorf("stored credential = %+v", stored)
+		}
+	})
+
+	mt.Run("wrong origin", func(mt *mtest.T) {
+		_, resp := register(mt, "https://phishing.example.net")
+		if resp.Success || resp.Message != "Passkey verification failed" {
+			mt.Fatalf("FinishPasskeyRegistration = %+v, want verification failure", resp)
+		}
+		if hasCommand(mt, "insert", "webauthn_credentials") {
+			mt.Error("credential stored for a response from another origin")
+		}
+	})
+}
+
+func TestPasskeyLogin(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	// login - passwordless login ด้วย authenticator ที่ใช้ไปแล้ว 5 ครั้ง (assertion นี้ส่ง counter = 6)
+	// storedCount = signature counter ที่บันทึกไว้จาก login ครั้งก่อน
+	login := func(mt *mtest.T, origin string, storedCount uint32, success ...bson.D) *LoginResponse {
+		s := newPasskeyService(mt)
+		account := testUser(mt, s.passwords, "alice@example.com", "correct-password")
+		authenticator := newSoftAuthenticator(mt, origin)
+		authenticator.counter = 5
+
+		credential := authenticator.credential(account.ID.Hex())
+		credential.SignCount = storedCount
+
+		mt.AddMockResponses(
+			cursorResponse(mt, "tenants", tenantDoc(testTenant)),
+			mtest.CreateSuccessResponse(), // webauthn_ceremonies.insert
+		)
+		begin, err := s.BeginPasskeyLogin(context.Background(), testTenant, "", "")
+		if err != nil || !begin.Success {
+			mt.Fatalf("BeginPasskeyLogin = (%+v, %v)", begin, err)
+		}
+		ceremony := insertedDoc(mt, "webauthn_ceremonies")
+
+		mt.AddMockResponses(
+			findAndModifyResponse(ceremony),
+			cursorResponse(mt, "tenants", tenantDoc(testTenant)),
+			cursorResponse(mt, "users", toDoc(account)),
+			cursorResponse(mt, "webauthn_credentials", toDoc(credential)),
+		)
+		mt.AddMockResponses(success...)
+
+		resp, err := s.FinishPasskeyLogin(context.Background(), begin.CeremonyID, authenticator.get(mt, begin.OptionsJSON, account.ID[:]), "")
+		if err != nil {
+			mt.Fatalf("FinishPasskeyLogin: %v", err)
+		}
+		return resp
+	}
+
+	mt.Run("passwordless", func(mt *mtest.T) {
+		resp := login(mt, testOrigin, 5,
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}), // webauthn_credentials.update
+			mtest.CreateSuccessResponse(), // sessions.insert
+			cursorResponse(mt, "groups"),
+			cursorResponse(mt, "roles"),
+			mtest.CreateSuccessResponse(), // refresh_tokens.insert
+		)
+		if !resp.Success || resp.Token == "" || resp.RefreshToken == "" {
+			mt.Fatalf("FinishPasskeyLogin = %+v, want tokens", resp)
+		}
+		if !hasCommand(mt, "update", "webauthn_credentials") {
+			mt.Error("signature counter was not recorded")
+		}
+	})
+
+	mt.Run("signature counter regression", func(mt *mtest.T) {
+		resp := login(mt, testOrigin, 6)
+		if resp.Success || resp.Message != "Passkey verification failed" || resp.Token != "" {
+			mt.Fatalf("FinishPasskeyLogin = %+v, want rejection of a cloned authenticator", resp)
+		}
+		if hasCommand(mt, "update", "webauthn_credentials") {
+			mt.Error("signature counter of a cloned authenticator was recorded")
+		}
+	})
+
+	mt.Run("wrong origin", func(mt *mtest.T) {
+		resp := login(mt, "https://phishing.example.net", 5)
+		if resp.Success || resp.Message != "Passkey verification failed" || resp.Token != "" {
+			mt.Fatalf("FinishPasskeyLogin = %+v, want verification failure", resp)
+		}
+	})
+
+	// beginSecondFactor - login ด้วยรหัสผ่านผ่านแล้ว (challenge จาก issueMFAChallenge) และเริ่ม assertion ด้วย challenge นั้น
+	beginSecondFactor := func(mt *mtest.T, s *Service, account bson.D, credential *passkey.Credential, challenge string) *PasskeyCeremonyResponse {
+		mt.AddMockResponses(
+			cursorResponse(mt, "blacklisted_tokens"),
+			cursorResponse(mt, "tenants", tenantDoc(testTenant)),
+			cursorResponse(mt, "users", account),
+			cursorResponse(mt, "webauthn_credentials", toDoc(credential)),
+			mtest.CreateSuccessResponse(), // webauthn_ceremonies.insert
+		)
+		begin, err := s.BeginPasskeyLogin(context.Background(), "", challenge, "")
+		if err != nil || !begin.Success {
+			mt.Fatalf("BeginPasskeyLogin = (%+v, %v)", begin, err)
+		}
+		return begin
+	}
+
+	mt.Run("second factor", func(mt *mtest.T) {
+		s := newPasskeyService(mt)
+		account := testUser(mt, s.passwords, "alice@example.com", "correct-password")
+		authenticator := newSoftAuthenticator(mt, testOrigin)
+		challenge := mfaChallenge(mt, s, account)
+
+		begin := beginSecondFactor(mt, s, toDoc(account), authenticator.credential(account.ID.Hex()), challenge)
+		ceremony := insertedDoc(mt, "webauthn_ceremonies")
+		if got := ceremony.Map()["user_id"]; got != account.ID.Hex() {
+			mt.Fatalf("ceremony user_id = %v, want %s", got, account.ID.Hex())
+		}
+
+		mt.AddMockResponses(
+			findAndModifyResponse(ceremony),
+			cursorResponse(mt, "tenants", tenantDoc(testTenant)),
+			cursorResponse(mt, "users", toDoc(account)),
+			mtest.CreateSuccessResponse(), // blacklisted_tokens.insert (ใช้ challenge)
+			cursorResponse(mt, "webauthn_credentials", toDoc(authenticator.credential(account.ID.Hex()))),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}), // webauthn_credentials.update
+			mtest.CreateSuccessResponse(),                                                               // sessions.insert
+			cursorResponse(mt, "groups"),
+			cursorResponse(mt, "roles"),
+			mtest.CreateSuccessResponse(), // refresh_tokens.insert
+		)
+		resp, err := s.FinishPasskeyLogin(context.Background(), begin.CeremonyID, authenticator.get(mt, begin.OptionsJSON, nil), challenge)
+		if err != nil || !resp.Success || resp.Token == "" || resp.RefreshToken == "" {
+			mt.Fatalf("FinishPasskeyLogin = (%+v, %v), want tokens", resp, err)
+		}
+
+		claims, err := s.jwtService.ValidateToken(resp.Token)
+		if err != nil {
+			mt.Fatalf("ValidateToken: %v", err)
+		}
+		for _, method := range []string{amrPassword, amrHardwareKey, amrMFA} {
+			if !claims.HasAMR(method) {
+				mt.Errorf("amr = %v, want %s", claims.AMR, method)
+			}
+		}
+
+		consumed := insertedDoc(mt, "blacklisted_tokens")
+		if got := consumed.Map()["token_hash"]; got != revocation.HashToken(challenge) {
+			mt.Errorf("consumed token_hash = %v, want the hash of the MFA challenge", got)
+		}
+	})
+
+	mt.Run("second factor with another user's challenge", func(mt *mtest.T) {
+		s := newPasskeyService(mt)
+		account := testUser(mt, s.passwords, "alice@example.com", "correct-password")
+		authenticator := newSoftAuthenticator(mt, testOrigin)
+
+		begin := beginSecondFactor(mt, s, toDoc(account), authenticator.credential(account.ID.Hex()), mfaChallenge(mt, s, account))
+		ceremony := insertedDoc(mt, "webauthn_ceremonies")
+
+		// ceremony ของ alice แต่ challenge ของ mallory (รหัสผ่านของ mallory ถูกต้อง)
+		other := testUser(mt, s.passwords, "mallory@example.com", "mallory-password")
+		other.ID = primitive.NewObjectID()
+		otherChallenge := mfaChallenge(mt, s, other)
+
+		mt.AddMockResponses(
+			findAndModifyResponse(ceremony),
+			cursorResponse(mt, "blacklisted_tokens"),
+			cursorResponse(mt, "tenants", tenantDoc(testTenant)),
+			cursorResponse(mt, "users", toDoc(other)),
+		)
+		resp, err := s.FinishPasskeyLogin(context.Background(), begin.CeremonyID, authenticator.get(mt, begin.OptionsJSON, nil), otherChallenge)
+		if err != nil || resp.Success || resp.Message != "Invalid or expired MFA token" || resp.Token != "" {
+			mt.Fatalf("FinishPasskeyLogin = (%+v, %v), want an invalid MFA token", resp, err)
+		}
+		if hasCommand(mt, "insert", "blacklisted_tokens") {
+			mt.Error("challenge consumed for a ceremony of another user")
+		}
+	})
+
+	mt.Run("second factor without a challenge", func(mt *mtest.T) {
+		s := newPasskeyService(mt)
+		account := testUser(mt, s.passwords, "alice@example.com", "correct-password")
+		authenticator := newSoftAuthenticator(mt, testOrigin)
+
+		begin := beginSecondFactor(mt, s, toDoc(account), authenticator.credential(account.ID.Hex()), mfaChallenge(mt, s, account))
+		ceremony := insertedDoc(mt, "webauthn_ceremonies")
+
+		// access token ไม่ใช่ challenge - ข้ามขั้นรหัสผ่านไม่ได้
+		for _, token := range []string{"", "not-a-token"} {
+			mt.AddMockResponses(findAndModifyResponse(ceremony))
+			resp, err := s.FinishPasskeyLogin(context.Background(), begin.CeremonyID, authenticator.get(mt, begin.OptionsJSON, nil), token)
+			if err != nil || resp.Success || resp.Message != "Invalid or expired MFA token" {
+				mt.Errorf("FinishPasskeyLogin(mfaToken=%q) = (%+v, %v), want an invalid MFA token", token, resp, err)
+			}
+		}
+		if hasCommand(mt, "update", "webauthn_credentials") {
+			mt.Error("passkey accepted without a password challenge")
+		}
+	})
+
+	mt.Run("second factor with a used challenge", func(mt *mtest.T) {
+		s := newPasskeyService(mt)
+		account := testUser(mt, s.passwords, "alice@example.com", "correct-password")
+		authenticator := newSoftAuthenticator(mt, testOrigin)
+		challenge := mfaChallenge(mt, s, account)
+
+		begin := beginSecondFactor(mt, s, toDoc(account), authenticator.credential(account.ID.Hex()), challenge)
+		ceremony := insertedDoc(mt, "webauthn_ceremonies")
+
+		// request อื่นใช้ challenge นี้ไปแล้ว (เช่น VerifyMFA ที่ส่งมาพร้อมกัน) - insert ชน unique index
+		mt.AddMockResponses(
+			findAndModifyResponse(ceremony),
+			cursorResponse(mt, "tenants", tenantDoc(testTenant)),
+			cursorResponse(mt, "users", toDoc(account)),
+			mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "duplicate key error"}),
+		)
+		resp, err := s.FinishPasskeyLogin(context.Background(), begin.CeremonyID, authenticator.get(mt, begin.OptionsJSON, nil), challenge)
+		if err != nil || resp.Success || resp.Message != "Invalid or expired MFA token" || resp.Token != "" {
+			mt.Fatalf("FinishPasskeyLogin = (%+v, %v), want an invalid MFA token", resp, err)
+		}
+		if hasCommand(mt, "update", "webauthn_credentials") {
+			mt.Error("passkey accepted with a used challenge")
+		}
+	})
+}
+
+// mfaChallenge - challenge token ที่ Login ออกให้หลังรหัสผ่านถูกต้อง (user เปิดใช้ passkey)
+func mfaChallenge(mt *mtest.T, s *Service, user *models.User) string {
+	mt.Helper()
+
+	resp, err := s.issueMFAChallenge(user, "", []string{mfaMethodWebAuthn})
+	if err != nil || !resp.MFARequired || resp.MFAToken == "" {
+		mt.Fatalf("issueMFAChallenge(%s) = (%+v, %v)", user.Email, resp, err)
+	}
+	return resp.MFAToken
+}
+
+// newPasskeyService - auth service ที่มี WebAuthn และ stores ที่ login ด้วย passkey ใช้
+func newPasskeyService(mt *mtest.T) *Service {
+	database := &db.MongoDB{Client: mt.Client, Database: mt.DB}
+
+	webAuthn, err := webauthn.New(&webauthn.Config{
+		RPID:          testRPID,
+		RPDisplayName: "Auth Test",
+		RPOrigins:     []string{testOrigin},
+	})
+	if err != nil {
+		mt.Fatalf("webauthn.New: %v", err)
+	}
+
+	return newTestService(mt, Dependencies{
+		WebAuthn:    webAuthn,
+		Passkeys:    passkey.NewStore(database),
+		Sessions:    session.NewStore(database, time.Minute),
+		Groups:      group.NewStore(database, 0),
+		Roles:       rbac.NewStore(database, 0),
+		Revocations: revocation.NewStore(database, time.Minute),
+		JWTService:  jwt.NewJWTService(jwt.NewKeyRing(jwt.NewHMACKey("test-secret")), jwt.TokenConfig{AccessTTL: time.Minute, Issuer: "auth-test", Audience: "auth-test"}),
+	}, Options{
+		RefreshTokenTTL:     time.Hour,
+		WebAuthnCeremonyTTL: time.Minute,
+		MFAChallengeTTL:     time.Minute,
+	})
+}
+
+// insertedDoc - document แรกที่ service insert ลง collection (เช่น ceremony ที่ Begin บันทึกไว้)
+func insertedDoc(mt *mtest.T, collection string) bson.D {
+	mt.Helper()
+
+	for _, evt := range mt.GetAllStartedEvents() {
+		if evt.CommandName != "insert" || evt.Command.Lookup("insert").StringValue() != collection {
+			continue
+		}
+		docs, err := evt.Command.Lookup("documents").Array().Values()
+		if err != nil || len(docs) == 0 {
+			mt.Fatalf("insert into %s has no documents: %v", collection, err)
+		}
+		var doc bson.D
+		if err := bson.Unmarshal(docs[0].Document(), &doc); err != nil {
+			mt.Fatalf("decode document inserted into %s: %v", collection, err)
+		}
+		return doc
+	}
+	mt.Fatalf("no insert into %s", collection)
+	return nil
+}
+
+// hasCommand - service ส่ง command นี้ไปยัง collection หรือไม่
+func hasCommand(mt *mtest.T, command, collection string) bool {
+	for _, evt := range mt.GetAllStartedEvents() {
+		if evt.CommandName == command && evt.Command.Lookup(command).StringValue() == collection {
+			return true
+		}
+	}
+	return false
+}
+
+func mustMarshal(t testing.TB, v interface{}) []byte {
+	t.Helper()
+
+	raw, err := bson.Marshal(v)
+	if err != nil {
+		t.Fatalf("bson.Marshal: %v", err)
+	}
+	return raw
+}
+
+// softAuthenticator - authenticator ในหน่วยความจำ (ECDSA P-256, attestation "none")
+// ตอบ options ของ Begin* แบบเดียวกับที่ browser ส่งกลับจาก navigator.credentials
+type softAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	origin       string // origin ที่ browser ใส่ใน clientDataJSON
+	counter      uint32
+}
+
+func newSoftAuthenticator(t testing.TB, origin string) *softAuthenticator {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	credentialID := make([]byte, 16)
+	if _, err := rand.Read(credentialID); err != nil {
+		t.Fatalf("generate credential ID: %v", err)
+	}
+
+	return &softAuthenticator{
+		key:          key,
+		credentialID: credentialID,
+		origin:       origin,
+	}
+}
+
+// credential - credential ที่ลงทะเบียนไว้แล้วของ authenticator นี้
+func (a *softAuthenticator) credential(userID string) *passkey.Credential {
+	return passkey.NewCredential(userID, defaultPasskeyName, &webauthn.Credential{
+		ID:              a.credentialID,
+		PublicKey:       a.publicKey(),
+		AttestationType: "none",
+		Flags:           webauthn.CredentialFlags{UserPresent: true, UserVerified: true},
+		Authenticator:   webauthn.Authenticator{SignCount: a.counter},
+	})
+}
+
+// publicKey - public key ในรูป COSE_Key
+func (a *softAuthenticator) publicKey() []byte {
+	x := make([]byte, 32)
+	y := make([]byte, 32)
+	a.key.PublicKey.X.FillBytes(x)
+	a.key.PublicKey.Y.FillBytes(y)
+
+	encoded, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  int64(webauthncose.P256),
+		XCoord: x,
+		YCoord: y,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return encoded
+}
+
+// create - ตอบ options ของ BeginPasskeyRegistration (navigator.credentials.create)
+func (a *softAuthenticator) create(t testing.TB, optionsJSON string) string {
+	t.Helper()
+
+	var options struct {
+		PublicKey struct {
+			Challenge string `json:"challenge"`
+			RP        struct {
+				ID string `json:"id"`
+			} `json:"rp"`
+		} `json:"publicKey"`
+	}
+	if err := json.Unmarshal([]byte(optionsJSON), &options); err != nil {
+		t.Fatalf("decode creation options: %v", err)
+	}
+
+	// attested credential data = AAGUID (ศูนย์ทั้งหมด) + ความยาวของ credential ID + credential ID + public key
+	attested := make([]byte, 16, 18+len(a.credentialID))
+	attested = binary.BigEndian.AppendUint16(attested, uint16(len(a.credentialID)))
+	attested = append(attested, a.credentialID...)
+	attested = append(attested, a.publicKey()...)
+
+	flags := protocol.FlagUserPresent | protocol.FlagUserVerified | protocol.FlagAttestedCredentialData
+	attestation, err := webauthncbor.Marshal(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": a.authenticatorData(options.PublicKey.RP.ID, flags, attested),
+	})
+	if err != nil {
+		t.Fatalf("encode attestation: %v", err)
+	}
+
+	return a.response(t, map[string]interface{}{
+		"clientDataJSON":    a.clientData(t, "webauthn.create", options.PublicKey.Challenge),
+		"attestationObject": base64.RawURLEncoding.EncodeToString(attestation),
+	})
+}
+
+// get - ตอบ options ของ BeginPasskeyLogin (navigator.credentials.get) โดยเพิ่ม signature counter ทุกครั้ง
+func (a *softAuthenticator) get(t testing.TB, optionsJSON string, userHandle []byte) string {
+	t.Helper()
+
+	var options struct {
+		PublicKey struct {
+			Challenge string `json:"challenge"`
+			RPID      string `json:"rpId"`
+		} `json:"publicKey"`
+	}
+	if err := json.Unmarshal([]byte(optionsJSON), &options); err != nil {
+		t.Fatalf("decode request options: %v", err)
+	}
+
+	a.counter++
+	authData := a.authenticatorData(options.PublicKey.RPID, protocol.FlagUserPresent|protocol.FlagUserVerified, nil)
+	clientData := a.clientData(t, "webauthn.get", options.PublicKey.Challenge)
+
+	// signature ครอบ authenticatorData || SHA-256(clientDataJSON)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte(nil), authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatalf("sign assertion: %v", err)
+	}
+
+	return a.response(t, map[string]interface{}{
+		"clientDataJSON":    clientData,
+		"authenticatorData": base64.RawURLEncoding.EncodeToString(authData),
+		"signature":         base64.RawURLEncoding.EncodeToString(signature),
+		"userHandle":        base64.RawURLEncoding.EncodeToString(userHandle),
+	})
+}
+
+// authenticatorData - SHA-256(RP ID) + flags + signature counter + ข้อมูลเพิ่มเติม
+func (a *softAuthenticator) authenticatorData(rpID string, flags protocol.AuthenticatorFlags, extra []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+
+	data := append([]byte(nil), rpIDHash[:]...)
+	data = append(data, byte(flags))
+	data = binary.BigEndian.AppendUint32(data, a.counter)
+	return append(data, extra...)
+}
+
+func (a *softAuthenticator) clientData(t testing.TB, ceremonyType, challenge string) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]string{
+		"type":      ceremonyType,
+		"challenge": challenge,
+		"origin":    a.origin,
+	})
+	if err != nil {
+		t.Fatalf("encode client data: %v", err)
+	}
+	return data
+}
+
+// response - PublicKeyCredential ในรูป JSON ที่ client ส่งให้ Finish*
+func (a *softAuthenticator) response(t testing.TB, response map[string]interface{}) string {
+	t.Helper()
+
+	if clientData, ok := response["clientDataJSON"].([]byte); ok {
+		response["clientDataJSON"] = base64.RawURLEncoding.EncodeToString(clientData)
+	}
+
+	id := base64.RawURLEncoding.EncodeToString(a.credentialID)
+	encoded, err := json.Marshal(map[string]interface{}{
+		"id":       id,
+		"rawId":    id,
+		"type":     "public-key",
+		"response": response,
+	})
+	if err != nil {
+		t.Fatalf("encode credential: %v", err)
+	}
+	return string(encoded)
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// passwordViolations - ตรวจรหัสผ่านใหม่ตาม password policy แล้วแปลงเป็น field violations ของ field ที่ระบุ
+// personal คือ email และชื่อของ user ที่ห้ามใช้ในรหัสผ่าน
+func (s *Service) passwordViolations(field, newPassword string, personal ...string) []*errdetails.BadRequest_FieldViolation {
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, v := range s.opts.PasswordPolicy.Check(newPassword, personal...) {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Reason:      v.Reason,
+			Description: v.Description,
+		})
+	}
+	return violations
+}
+
+// fieldViolation - สร้าง field violation สำหรับ field ที่ไม่ใช่รหัสผ่าน
+func fieldViolation(field, reason, description string) *errdetails.BadRequest_FieldViolation {
+	return &errdetails.BadRequest_FieldViolation{
+		Field:       field,
+		Reason:      reason,
+		Description: description,
+	}
+}
+
+// invalidArgumentError - InvalidArgument พร้อม BadRequest details (message คือ violation แรก)
+func invalidArgumentError(violations []*errdetails.BadRequest_FieldViolation) error {
+	st := status.New(codes.InvalidArgument, violations[0].Description)
+	if detailed, err := st.WithDetails(&errdetails.BadRequest{
+		FieldViolations: violations,
+	}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
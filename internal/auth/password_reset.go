@@ -0,0 +1,217 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"auth-microservice/internal/mailer"
+	"auth-microservice/internal/models"
+	"auth-microservice/internal/tenant"
+)
+
+// RequestPasswordReset - ส่งลิงก์ reset รหัสผ่านทาง email
+// ตอบสำเร็จเสมอไม่ว่าจะมี email นี้หรือไม่ (ไม่เปิดเผยว่ามีบัญชีใดบ้าง)
+func (s *Service) RequestPasswordReset(ctx context.Context, tenantID, email string) (*RequestPasswordResetResponse, error) {
+	response := &RequestPasswordResetResponse{
+		Success: true,
+		Message: "If an account exists for this email, a password reset link has been sent",
+	}
+
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return &RequestPasswordResetResponse{
+			Success: false,
+			Message: "Email is required",
+		}, nil
+	}
+
+	if err := s.checkEmailRate(ctx, email); err != nil {
+		return nil, err
+	}
+
+	ctx, err := s.tenantContext(ctx, tenantID)
+	if err == tenant.ErrTenantNotFound {
+		return &RequestPasswordResetResponse{
+			Success: false,
+			Message: "Invalid tenant",
+		}, nil
+	}
+	if err != nil {
+		return &RequestPasswordResetResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		log.Printf("Password reset requested for unknown email: %s", email)
+		return response, nil
+	}
+
+	// ความผิดพลาดหลังจากนี้ตอบเหมือนกรณีไม่มีบัญชี - ไม่เช่นนั้นผลที่ต่างกันจะบอกว่า email นี้มีอยู่จริง
+	token, err := s.actionTokens.issue(ctx, purposeResetPassword, user.ID.Hex(), user.TenantID, user.Email, s.opts.PasswordResetTTL)
+	if err != nil {
+		log.Printf("Failed to issue password reset token for %s: %v", email, err)
+		return response, nil
+	}
+
+	link := s.opts.AppBaseURL + "/reset-password?token=" + url.QueryEscape(token)
+	err = s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new one:\n\n%s\n\n"+
+			"This link expires in %s and can only be used once. If you did not request a reset, you can ignore this email.\n",
+			user.FirstName, link, s.opts.PasswordResetTTL),
+	})
+	if err != nil {
+		log.Printf("Failed to send password reset email to %s: %v", email, err)
+		return response, nil
+	}
+
+	log.Printf("Password reset email sent to: %s", email)
+
+	return response, nil
+}
+
+// ResetPassword - ตั้งรหัสผ่านใหม่ด้วย token จาก email (ใช้ได้ครั้งเดียว)
+// สำเร็จแล้วจะยกเลิกทุก session ของ user และแจ้งเตือนทาง email
+func (s *Service) ResetPassword(ctx context.Context, token, newPassword string) (*ResetPasswordResponse, error) {
+	if token == "" {
+		return &ResetPasswordResponse{
+			Success: false,
+			Message: "Reset token is required",
+		}, nil
+	}
+
+	doc, err := s.actionTokens.lookup(ctx, purposeResetPassword, token)
+	if err == errInvalidActionToken {
+		return &ResetPasswordResponse{
+			Success: false,
+			Message: "Invalid or expired reset token",
+		}, nil
+	}
+	if err != nil {
+		return &ResetPasswordResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	// token ของ email เก่าใช้ไม่ได้หลังเปลี่ยน email
+	ctx, err = s.tenantContext(ctx, doc.TenantID)
+	if err != nil && err != tenant.ErrTenantNotFound {
+		return &ResetPasswordResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+	var user *models.User
+	if err == nil {
+		user, err = s.userRepo.GetByID(ctx, doc.UserID)
+	}
+	if err != nil || user.Email != doc.Email {
+		return &ResetPasswordResponse{
+			Success: false,
+			Message: "Invalid or expired reset token",
+		}, nil
+	}
+
+	// ตรวจสอบรหัสผ่านก่อนใช้ token เพื่อให้ user แก้แล้วส่งใหม่ได้
+	if violations := s.passwordViolations("new_password", newPassword, user.Email, user.FirstName, user.LastName); len(violations) > 0 {
+		return &ResetPasswordResponse{
+			Success: false,
+			Message: violations[0].Description,
+		}, invalidArgumentError(violations)
+	}
+
+	// hash ก่อนใช้ token - ถ้าระบบยุ่ง user ยังใช้ลิงก์เดิมลองใหม่ได้
+	if err := user.HashPassword(ctx, s.passwords, newPassword); err != nil {
+		log.Printf("Failed to hash password: %v", err)
+		message, err := hashingFailure(ctx, err)
+		return &ResetPasswordResponse{
+			Success: false,
+			Message: message,
+		}, err
+	}
+
+	// ใช้ token (ถ้าถูกใช้ไปแล้วระหว่างนี้จะไม่ผ่าน)
+	if _, err := s.actionTokens.consume(ctx, purposeResetPassword, token); err != nil {
+		if err == errInvalidActionToken {
+			return &ResetPasswordResponse{
+				Success: false,
+				Message: "Invalid or expired reset token",
+			}, nil
+		}
+		return &ResetPasswordResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	if err := s.replacePassword(ctx, user); err != nil {
+		log.Printf("Failed to reset password for user %s: %v", user.Email, err)
+		return &ResetPasswordResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	// ลิงก์ถูกส่งไปที่ email นี้ จึงถือว่ายืนยัน email แล้ว และปลดล็อคบัญชี
+	if !user.EmailVerified {
+		if err := s.userRepo.MarkEmailVerified(ctx, user.ID.Hex(), user.Email); err != nil {
+			log.Printf("Failed to mark email verified for user %s: %v", user.Email, err)
+		}
+	}
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		if err := s.userRepo.ResetFailedLogins(ctx, user.ID.Hex()); err != nil {
+			log.Printf("Failed to reset failed logins for user %s: %v", user.Email, err)
+		}
+	}
+
+	s.notifyPasswordChanged(ctx, user, true)
+
+	log.Printf("Password reset for user: %s", user.Email)
+
+	return &ResetPasswordResponse{
+		Success: true,
+		Message: "Password has been reset. Please log in with your new password.",
+	}, nil
+}
+
+// replacePassword - บันทึก user.PasswordHash ใหม่และยกเลิกทุก session ของ user
+func (s *Service) replacePassword(ctx context.Context, user *models.User) error {
+	userID := user.ID.Hex()
+	if err := s.userRepo.UpdatePassword(ctx, userID, user.PasswordHash); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.IncrementTokenVersion(ctx, userID); err != nil {
+		return err
+	}
+
+	return s.revokeAllSessions(ctx, userID)
+}
+
+// notifyPasswordChanged - แจ้ง user ทาง email ว่ารหัสผ่านถูกเปลี่ยน
+func (s *Service) notifyPasswordChanged(ctx context.Context, user *models.User, signedOut bool) {
+	var signOutNote string
+	if signedOut {
+		signOutNote = " Other devices have been signed out."
+	}
+
+	err := s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Your password was changed",
+		Body: fmt.Sprintf("Hi %s,\n\nThe password for your account was changed on %s.%s\n\n"+
+			"If you did not make this change, reset your password immediately and contact support.\n",
+			user.FirstName, time.Now().UTC().Format(time.RFC1123), signOutNote),
+	})
+	if err != nil {
+		log.Printf("Failed to send password change notification to %s: %v", user.Email, err)
+	}
+}
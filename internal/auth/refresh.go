@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"auth-microservice/pkg/db"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	errInvalidRefreshToken = errors.New("invalid refresh token")
+	errRefreshTokenReused  = errors.New("refresh token reuse detected")
+)
+
+// refreshToken - refresh token ที่เก็บในฐานข้อมูล (เก็บเฉพาะ hash)
+//
+// ทุกครั้งที่ใช้ refresh token จะได้ token ใหม่ใน family เดิม และตัวเก่าจะถูก mark ว่าใช้แล้ว
+// ถ้ามีการนำตัวที่ใช้แล้วกลับมาใช้ซ้ำ แปลว่า token อาจถูกขโมย จึงยกเลิกทั้ง family
+type refreshToken struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	TokenHash string             `bson:"token_hash"`
+	FamilyID  string             `bson:"family_id"`
+	UserID    string             `bson:"user_id"`
+	TenantID  string             `bson:"tenant_id,omitempty"` // ว่าง = ออกก่อนรองรับ tenant (ใช้ tenant เริ่มต้น)
+	Audience  string             `bson:"audience,omitempty"`
+	AMR       []string           `bson:"amr,omitempty"`
+	CreatedAt time.Time          `bson:"created_at"`
+	ExpiresAt time.Time          `bson:"expires_at"`
+	UsedAt    *time.Time         `bson:"used_at,omitempty"`
+	RevokedAt *time.Time         `bson:"revoked_at,omitempty"`
+}
+
+type refreshTokenStore struct {
+	db  *db.MongoDB
+	ttl time.Duration
+}
+
+func newRefreshTokenStore(database *db.MongoDB, ttl time.Duration) *refreshTokenStore {
+	return &refreshTokenStore{
+		db:  database,
+		ttl: ttl,
+	}
+}
+
+// issue - ออก refresh token ใหม่ (ถ้า familyID ว่าง จะเริ่ม family ใหม่)
+// audience ที่ขอตอน login และวิธียืนยันตัวตน (amr) จะถูกส่งต่อไปยัง access token ที่ได้จากการ refresh
+func (r *refreshTokenStore) issue(ctx context.Context, userID, tenantID, familyID, audience string, amr []string) (string, error) {
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	if familyID == "" {
+		familyID = primitive.NewObjectID().Hex()
+	}
+
+	now := time.Now()
+	doc := refreshToken{
+		ID:        primitive.NewObjectID(),
+		TokenHash: hashOpaqueToken(token),
+		FamilyID:  familyID,
+		UserID:    userID,
+		TenantID:  tenantID,
+		Audience:  audience,
+		AMR:       amr,
+		CreatedAt: now,
+		ExpiresAt: now.Add(r.ttl),
+	}
+
+	if _, err := r.db.RefreshTokens().InsertOne(ctx, doc); err != nil {
+		return "", fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return token, nil
+}
+
+// consume - mark refresh token ว่าใช้แล้ว (atomic) และคืนข้อมูลของ token นั้น
+func (r *refreshTokenStore) consume(ctx context.Context, token string) (*refreshToken, error) {
+	tokenHash := hashOpaqueToken(token)
+	now := time.Now()
+
+	filter := bson.M{
+		"token_hash": tokenHash,
+		"used_at":    bson.M{"$exists": false},
+		"revoked_at": bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": now},
+	}
+	update := bson.M{"$set": bson.M{"used_at": now}}
+
+	var current refreshToken
+	err := r.db.RefreshTokens().FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&current)
+	if err == nil {
+		return &current, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	// ไม่ผ่านเงื่อนไข - ตรวจสอบว่าเป็นการใช้ซ้ำหรือไม่
+	var existing refreshToken
+	err = r.db.RefreshTokens().FindOne(ctx, bson.M{"token_hash": tokenHash}).Decode(&existing)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if existing.UsedAt != nil && existing.RevokedAt == nil {
+		if err := r.revokeFamily(ctx, existing.FamilyID); err != nil {
+			return nil, err
+		}
+		return &existing, errRefreshTokenReused
+	}
+
+	return nil, errInvalidRefreshToken
+}
+
+// revokeFamily - ยกเลิก refresh token ทุกตัวใน family
+func (r *refreshTokenStore) revokeFamily(ctx context.Context, familyID string) error {
+	filter := bson.M{
+		"family_id":  familyID,
+		"revoked_at": bson.M{"$exists": false},
+	}
+	update := bson.M{"$set": bson.M{"revoked_at": time.Now()}}
+
+	if _, err := r.db.RefreshTokens().UpdateMany(ctx, filter, update); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+	return nil
+}
+
+// revokeAllForUser - ยกเลิก refresh token ทุกตัวของ user
+func (r *refreshTokenStore) revokeAllForUser(ctx context.Context, userID string) error {
+	filter := bson.M{
+		"user_id":    userID,
+		"revoked_at": bson.M{"$exists": false},
+	}
+	update := bson.M{"$set": bson.M{"revoked_at": time.Now()}}
+
+	if _, err := r.db.RefreshTokens().UpdateMany(ctx, filter, update); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	return nil
+}
+
+// revokeByToken - ยกเลิก family ของ refresh token ที่ระบุ (ใช้ตอน logout)
+func (r *refreshTokenStore) revokeByToken(ctx context.Context, userID, token string) error {
+	filter := bson.M{
+		"token_hash": hashOpaqueToken(token),
+		"user_id":    userID,
+	}
+
+	var existing refreshToken
+	err := r.db.RefreshTokens().FindOne(ctx, filter).Decode(&existing)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		return fmt.Errorf("database error: %w", err)
+	}
+	return r.revokeFamily(ctx, existing.FamilyID)
+}
@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	"auth-microservice/internal/audit"
+	"auth-microservice/internal/rbac"
+)
+
+// roleNamePattern - ชื่อ role ที่ใช้ได้
+var roleNamePattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// rolesDefaultTenantOnlyMessage - roles ใช้ร่วมกันทุก tenant จึงแก้ไขได้จาก tenant เริ่มต้นเท่านั้น
+const rolesDefaultTenantOnlyMessage = "Roles can only be managed from the default tenant"
+
+// ListRoles - role ทั้งหมดพร้อมสิทธิ์
+func (s *Service) ListRoles(ctx context.Context) ([]rbac.Role, error) {
+	return s.roles.List(ctx)
+}
+
+// CreateRole - สร้าง role ใหม่
+func (s *Service) CreateRole(ctx context.Context, actorID, name, description string, permissions []string) (*RoleResponse, error) {
+	if !s.fromDefaultTenant(ctx) {
+		return &RoleResponse{
+			Success: false,
+			Message: rolesDefaultTenantOnlyMessage,
+		}, nil
+	}
+
+	name = strings.TrimSpace(name)
+	if !roleNamePattern.MatchString(name) {
+		return &RoleResponse{
+			Success: false,
+			Message: "Role name must be 1-32 characters of a-z, 0-9, _ or -",
+		}, nil
+	}
+
+	permissions, err := normalizePermissions(permissions)
+	if err != nil {
+		return &RoleResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	role, err := s.roles.Create(ctx, name, strings.TrimSpace(description), permissions)
+	if err == rbac.ErrRoleExists {
+		return &RoleResponse{
+			Success: false,
+			Message: "Role already exists",
+		}, nil
+	}
+	if err != nil {
+		return &RoleResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	s.recordAudit(ctx, audit.Event{
+		Type:    audit.EventRoleCreated,
+		ActorID: actorID,
+		Details: map[string]string{
+			"role":        role.Name,
+			"permissions": strings.Join(role.Permissions, " "),
+		},
+	})
+	log.Printf("Role created: %s (%s)", role.Name, strings.Join(role.Permissions, " "))
+
+	return &RoleResponse{
+		Success: true,
+		Message: "Role created successfully",
+		Role:    role,
+	}, nil
+}
+
+// UpdateRole - แทนที่คำอธิบายและสิทธิ์ของ role (role admin แก้ไขไม่ได้ เพื่อไม่ให้ล็อคตัวเองออกจากระบบ)
+func (s *Service) UpdateRole(ctx context.Context, actorID, name, description string, permissions []string) (*RoleResponse, error) {
+	if !s.fromDefaultTenant(ctx) {
+		return &RoleResponse{
+			Success: false,
+			Message: rolesDefaultTenantOnlyMessage,
+		}, nil
+	}
+
+	if name == rbac.RoleAdmin {
+		return &RoleResponse{
+			Success: false,
+			Message: "The admin role cannot be modified",
+		}, nil
+	}
+
+	permissions, err := normalizePermissions(permissions)
+	if err != nil {
+		return &RoleResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	role, err := s.roles.Update(ctx, name, strings.TrimSpace(description), permissions)
+	if err == rbac.ErrRoleNotFound {
+		return &RoleResponse{
+			Success: false,
+			Message: "Role not found",
+		}, nil
+	}
+	if err != nil {
+		return &RoleResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	s.recordAudit(ctx, audit.Event{
+		Type:    audit.EventRoleUpdated,
+		ActorID: actorID,
+		Details: map[string]string{
+			"role":        role.Name,
+			"permissions": strings.Join(role.Permissions, " "),
+		},
+	})
+	log.Printf("Role updated: %s (%s)", role.Name, strings.Join(role.Permissions, " "))
+
+	return &RoleResponse{
+		Success: true,
+		Message: "Role updated successfully",
+		Role:    role,
+	}, nil
+}
+
+// DeleteRole - ลบ role ที่ไม่มี user ใช้อยู่
+func (s *Service) DeleteRole(ctx context.Context, actorID, name string) (*DeleteRoleResponse, error) {
+	if !s.fromDefaultTenant(ctx) {
+		return &DeleteRoleResponse{
+			Success: false,
+			Message: rolesDefaultTenantOnlyMessage,
+		}, nil
+	}
+
+	err := s.roles.Delete(ctx, name)
+	switch err {
+	case nil:
+	case rbac.ErrRoleNotFound:
+		return &DeleteRoleResponse{
+			Success: false,
+			Message: "Role not found",
+		}, nil
+	case rbac.ErrBuiltinRole:
+		return &DeleteRoleResponse{
+			Success: false,
+			Message: "Built-in roles cannot be deleted",
+		}, nil
+	case rbac.ErrRoleInUse:
+		return &DeleteRoleResponse{
+			Success: false,
+			Message: "Role is still assigned to users or groups",
+		}, nil
+	default:
+		return &DeleteRoleResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	s.recordAudit(ctx, audit.Event{
+		Type:    audit.EventRoleDeleted,
+		ActorID: actorID,
+		Details: map[string]string{"role": name},
+	})
+	log.Printf("Role deleted: %s", name)
+
+	return &DeleteRoleResponse{
+		Success: true,
+		Message: "Role deleted successfully",
+	}, nil
+}
+
+// normalizePermissions - ตรวจว่าเป็นสิทธิ์ที่รู้จัก ตัดตัวซ้ำ และเรียงลำดับ
+func normalizePermissions(permissions []string) ([]string, error) {
+	seen := make(map[string]bool, len(permissions))
+	result := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		p = strings.TrimSpace(p)
+		if !rbac.IsKnownPermission(p) {
+			return nil, fmt.Errorf("unknown permission %q", p)
+		}
+		if !seen[p] {
+			seen[p] = true
+			result = append(result, p)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
@@ -2,47 +2,161 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
+	"auth-microservice/internal/audit"
+	"auth-microservice/internal/group"
+	"auth-microservice/internal/mailer"
+	"auth-microservice/internal/middleware"
 	"auth-microservice/internal/models"
+	"auth-microservice/internal/passkey"
+	"auth-microservice/internal/password"
+	"auth-microservice/internal/ratelimit"
+	"auth-microservice/internal/rbac"
+	"auth-microservice/internal/revocation"
+	"auth-microservice/internal/session"
+	"auth-microservice/internal/tenant"
 	"auth-microservice/internal/user"
 	"auth-microservice/pkg/db"
 	"auth-microservice/pkg/jwt"
 
-	"go.mongodb.org/mongo-driver/bson"
-	"golang.org/x/time/rate"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type Service struct {
-	userRepo   *user.Repository
-	jwtService *jwt.JWTService
-	db         *db.MongoDB
-	limiter    *rate.Limiter
+	userRepo      *user.Repository
+	jwtService    *jwt.JWTService
+	revocations   *revocation.Store
+	refreshTokens *refreshTokenStore
+	sessions      *session.Store
+	keyStore      *jwt.KeyStore
+	rateLimiter   *ratelimit.Limiter
+	webAuthn      *webauthn.WebAuthn
+	passkeys      *passkey.Store
+	ceremonies    *passkey.CeremonyStore
+	actionTokens  *actionTokenStore
+	mailer        mailer.Mailer
+	audit         *audit.Logger
+	roles         *rbac.Store
+	tenants       *tenant.Store
+	groups        *group.Store
+	passwords     *password.Pool
+	dummyHash     string // hash สำหรับเทียบเมื่อไม่พบ user (EnumerationProtection)
+	opts          Options
 }
 
-func NewService(userRepo *user.Repository, jwtService *jwt.JWTService, database *db.MongoDB) *Service {
-	// Rate limiter: 5 attempts per minute
-	limiter := rate.NewLimiter(rate.Every(12*time.Second), 5)
+// Dependencies - components ที่ auth service ใช้งาน
+type Dependencies struct {
+	UserRepo    *user.Repository
+	JWTService  *jwt.JWTService
+	KeyStore    *jwt.KeyStore
+	Revocations *revocation.Store
+	Sessions    *session.Store
+	RateLimiter *ratelimit.Limiter
+	WebAuthn    *webauthn.WebAuthn
+	Passkeys    *passkey.Store
+	Mailer      mailer.Mailer
+	Audit       *audit.Logger
+	Roles       *rbac.Store
+	Tenants     *tenant.Store
+	Groups      *group.Store
+	Passwords   *password.Pool // nil = pool ของ password.DefaultHasher
+	DB          *db.MongoDB
+}
+
+// Options - ค่าที่ปรับได้ของ auth service
+type Options struct {
+	// RefreshTokenTTL - อายุของ refresh token และ session
+	RefreshTokenTTL time.Duration
+	// LoginLimits - rate limits ของ Login ต่อ IP, ต่อ email และต่อ IP+email
+	LoginLimits LoginLimits
+	// Lockout - ล็อคบัญชีหลัง login ผิดติดต่อกัน
+	Lockout LockoutPolicy
+	// MFAChallengeTTL - อายุของ challenge token ระหว่างรอ VerifyMFA
+	MFAChallengeTTL time.Duration
+	// TOTPIssuer - ชื่อที่แสดงใน authenticator app
+	TOTPIssuer string
+	// WebAuthnCeremonyTTL - เวลาที่ client มีในการตอบ challenge ของ passkey
+	WebAuthnCeremonyTTL time.Duration
+	// RequireVerifiedEmail - ไม่ให้ user ที่ยังไม่ยืนยัน email login
+	RequireVerifiedEmail bool
+	// VerificationTokenTTL - อายุของลิงก์ยืนยัน email
+	VerificationTokenTTL time.Duration
+	// AppBaseURL - URL ของหน้าเว็บที่ใช้สร้างลิงก์ใน email
+	AppBaseURL string
+	// PasswordResetTTL - อายุของลิงก์ reset รหัสผ่าน
+	PasswordResetTTL time.Duration
+	// EmailLimit - จำนวน email สูงสุดที่ส่งไปยังที่อยู่เดียวกันต่อช่วงเวลา
+	EmailLimit ratelimit.Rule
+
+	// PasswordPolicy - กฎของรหัสผ่านใหม่ (nil = password.DefaultPolicy)
+	PasswordPolicy *password.Policy
+
+	// EnumerationProtection - ไม่ให้ Login และ Register เปิดเผยว่ามี email ใดในระบบ
+	// (เวลาตอบและข้อความเหมือนกันทุกกรณี, email ซ้ำจะแจ้งเจ้าของทาง email แทน)
+	EnumerationProtection bool
+
+	// DefaultTenant - tenant ที่ใช้เมื่อ client ไม่ระบุ tenant (และเป็น tenant เดียวที่จัดการ tenants ได้)
+	DefaultTenant string
+}
+
+func NewService(deps Dependencies, opts Options) *Service {
+	if opts.PasswordPolicy == nil {
+		opts.PasswordPolicy = password.DefaultPolicy()
+	}
+	passwords := deps.Passwords
+	if passwords == nil {
+		passwords = password.NewPool(password.DefaultHasher(), password.PoolConfig{})
+	}
+
+	// hash ด้วย algorithm ปัจจุบัน เพื่อให้ใช้เวลาเท่ากับการตรวจรหัสผ่านจริง
+	var dummyHash string
+	if opts.EnumerationProtection {
+		hashed, err := passwords.Hasher().Hash(dummyPassword)
+		if err != nil {
+			log.Printf("Failed to create dummy password hash: %v", err)
+		}
+		dummyHash = hashed
+	}
 
 	return &Service{
-		userRepo:   userRepo,
-		jwtService: jwtService,
-		db:         database,
-		limiter:    limiter,
+		userRepo:      deps.UserRepo,
+		jwtService:    deps.JWTService,
+		revocations:   deps.Revocations,
+		refreshTokens: newRefreshTokenStore(deps.DB, opts.RefreshTokenTTL),
+		sessions:      deps.Sessions,
+		keyStore:      deps.KeyStore,
+		rateLimiter:   deps.RateLimiter,
+		webAuthn:      deps.WebAuthn,
+		passkeys:      deps.Passkeys,
+		ceremonies:    passkey.NewCeremonyStore(deps.DB, opts.WebAuthnCeremonyTTL),
+		actionTokens:  newActionTokenStore(deps.DB),
+		mailer:        deps.Mailer,
+		audit:         deps.Audit,
+		roles:         deps.Roles,
+		tenants:       deps.Tenants,
+		groups:        deps.Groups,
+		passwords:     passwords,
+		dummyHash:     dummyHash,
+		opts:          opts,
 	}
 }
 
-// Login - เข้าสู่ระบบ
-func (s *Service) Login(ctx context.Context, email, password string) (*LoginResponse, error) {
-	// Rate limiting check
-	if !s.limiter.Allow() {
-		log.Printf("Rate limit exceeded for login attempt")
+// Login - เข้าสู่ระบบ (tenantID ว่าง = tenant เริ่มต้น)
+func (s *Service) Login(ctx context.Context, tenantID, email, password, audience string) (*LoginResponse, error) {
+	// Rate limiting check (ต่อ IP, ต่อ email และต่อ IP+email)
+	if err := s.checkLoginRate(ctx, email); err != nil {
 		return &LoginResponse{
 			Success: false,
 			Message: "Too many login attempts. Please try again later.",
-		}, nil
+		}, err
 	}
 
 	// Validate input
@@ -53,47 +167,262 @@ func (s *Service) Login(ctx context.Context, email, password string) (*LoginResp
 		}, nil
 	}
 
+	if audience != "" && !s.jwtService.IsAllowedAudience(audience) {
+		return &LoginResponse{
+			Success: false,
+			Message: "Invalid audience",
+		}, nil
+	}
+
+	ctx, err := s.tenantContext(ctx, tenantID)
+	if err == tenant.ErrTenantNotFound {
+		return &LoginResponse{
+			Success: false,
+			Message: "Invalid tenant",
+		}, nil
+	}
+	if err != nil {
+		return &LoginResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
 	// Find user by email
 	user, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil {
 		log.Printf("Login failed - user not found: %s", email)
+		return s.invalidCredentials(ctx, password)
+	}
+
+	// บัญชีที่ถูกล็อคอยู่จะไม่ตรวจสอบรหัสผ่านเลย
+	if now := time.Now(); user.IsLocked(now) {
+		log.Printf("Login failed - account locked: %s", email)
+		if s.opts.EnumerationProtection {
+			return s.invalidCredentials(ctx, password)
+		}
 		return &LoginResponse{
 			Success: false,
-			Message: "Invalid email or password",
-		}, nil
+			Message: "Account is temporarily locked. Please try again later.",
+		}, rateLimitError(ctx, "Account is temporarily locked. Please try again later.", user.LockedUntil.Sub(now))
 	}
 
 	// Check password
-	if !user.CheckPassword(password) {
+	ok, err := user.CheckPassword(ctx, s.passwords, password)
+	if err != nil {
+		log.Printf("Login failed - could not check password for %s: %v", email, err)
+		message, err := hashingFailure(ctx, err)
+		return &LoginResponse{
+			Success: false,
+			Message: message,
+		}, err
+	}
+	if !ok {
 		log.Printf("Login failed - invalid password: %s", email)
+		s.recordFailedLogin(ctx, user)
 		return &LoginResponse{
 			Success: false,
 			Message: "Invalid email or password",
 		}, nil
 	}
 
-	// Generate JWT token
-	token, err := s.jwtService.GenerateToken(user.ID.Hex(), user.Email, user.Role)
+	// login สำเร็จ - ล้างจำนวน login ผิด
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		if err := s.userRepo.ResetFailedLogins(ctx, user.ID.Hex()); err != nil {
+			log.Printf("Failed to reset failed logins for user %s: %v", email, err)
+		}
+	}
+
+	// hash ยังเป็น algorithm หรือ parameters เก่า - hash ใหม่ขณะที่มีรหัสผ่านจริงอยู่
+	s.rehashPassword(ctx, user, password)
+
+	// ยังไม่ยืนยัน email (ตรวจหลังรหัสผ่านถูกต้องเท่านั้น เพื่อไม่เปิดเผยสถานะของบัญชี)
+	if s.opts.RequireVerifiedEmail && !user.EmailVerified {
+		log.Printf("Login failed - email not verified: %s", email)
+		return &LoginResponse{
+			Success: false,
+			Message: "Email address has not been verified",
+		}, nil
+	}
+
+	// เปิดใช้ MFA - ออก challenge token แทน access token (ต้องเรียก VerifyMFA หรือ passkey ต่อ)
+	methods, err := s.mfaMethods(ctx, user)
+	if err != nil {
+		log.Printf("Failed to load MFA methods for user %s: %v", email, err)
+		return &LoginResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+	if len(methods) > 0 {
+		return s.issueMFAChallenge(user, audience, methods)
+	}
+
+	// Generate access token + refresh token (family ใหม่)
+	tokens, err := s.issueTokens(ctx, user, "", audience, []string{amrPassword})
 	if err != nil {
 		log.Printf("Failed to generate token for user %s: %v", email, err)
 		return &LoginResponse{
 			Success: false,
 			Message: "Internal server error",
-		}, fmt.Errorf("failed to generate token: %w", err)
+		}, err
 	}
 
 	log.Printf("Login successful for user: %s", email)
 
 	return &LoginResponse{
-		Success: true,
-		Message: "Login successful",
-		Token:   token,
-		User:    user.ToSafeUser(),
+		Success:      true,
+		Message:      "Login successful",
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		User:         user.ToSafeUser(),
+	}, nil
+}
+
+// Refresh - แลก refresh token เป็น access token ใหม่ (rotate refresh token ทุกครั้ง)
+func (s *Service) Refresh(ctx context.Context, refreshToken string) (*RefreshResponse, error) {
+	if refreshToken == "" {
+		return &RefreshResponse{
+			Success: false,
+			Message: "Refresh token is required",
+		}, nil
+	}
+
+	current, err := s.refreshTokens.consume(ctx, refreshToken)
+	if err != nil {
+		switch err {
+		case errRefreshTokenReused:
+			log.Printf("⚠️  Refresh token reuse detected - revoked family %s of user %s", current.FamilyID, current.UserID)
+			// family = session - ยกเลิก session ด้วย เพื่อให้ access token ที่ออกจาก session นี้ใช้ไม่ได้ทันที
+			if err := s.endSession(ctx, current.UserID, current.FamilyID); err != nil && err != session.ErrNotFound {
+				log.Printf("Failed to revoke session %s after refresh token reuse: %v", current.FamilyID, err)
+			}
+			return &RefreshResponse{
+				Success: false,
+				Message: "Refresh token has already been used. Please log in again.",
+			}, nil
+		case errInvalidRefreshToken:
+			return &RefreshResponse{
+				Success: false,
+				Message: "Invalid or expired refresh token",
+			}, nil
+		default:
+			log.Printf("Failed to consume refresh token: %v", err)
+			return &RefreshResponse{
+				Success: false,
+				Message: "Internal server error",
+			}, err
+		}
+	}
+
+	// ดึงข้อมูล user ล่าสุด (user ที่ถูกลบ/ปิดใช้งาน หรือ tenant ถูกปิดใช้งานจะ refresh ไม่ได้)
+	ctx, err = s.tenantContext(ctx, current.TenantID)
+	if err != nil && err != tenant.ErrTenantNotFound {
+		return &RefreshResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+	var user *models.User
+	if err == nil {
+		user, err = s.userRepo.GetByID(ctx, current.UserID)
+	}
+	if err != nil {
+		log.Printf("Refresh failed - user not found: %s", current.UserID)
+		if revokeErr := s.refreshTokens.revokeFamily(ctx, current.FamilyID); revokeErr != nil {
+			log.Printf("Failed to revoke refresh token family: %v", revokeErr)
+		}
+		return &RefreshResponse{
+			Success: false,
+			Message: "Invalid or expired refresh token",
+		}, nil
+	}
+
+	tokens, err := s.issueTokens(ctx, user, current.FamilyID, current.Audience, current.AMR)
+	if err == session.ErrNotFound {
+		// session ถูกยกเลิกหรือหมดอายุไปแล้ว
+		return &RefreshResponse{
+			Success: false,
+			Message: "Invalid or expired refresh token",
+		}, nil
+	}
+	if err != nil {
+		log.Printf("Failed to refresh token for user %s: %v", user.Email, err)
+		return &RefreshResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	log.Printf("Token refreshed for user: %s", user.Email)
+
+	return &RefreshResponse{
+		Success:      true,
+		Message:      "Token refreshed successfully",
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	}, nil
+}
+
+// issueTokens - ออก access token และ refresh token ให้ user
+// sessionID ว่าง = login ใหม่ (สร้าง session ใหม่), ไม่ว่าง = refresh ภายใน session เดิม
+// audience (ถ้ามี) จะถูกเพิ่มใน aud ของ access token นอกเหนือจาก audience ของ service นี้
+// amr คือวิธีที่ใช้ยืนยันตัวตนตอน login (ส่งต่อไปยัง token ที่ได้จากการ refresh)
+func (s *Service) issueTokens(ctx context.Context, user *models.User, sessionID, audience string, amr []string) (*tokenPair, error) {
+	expiresAt := time.Now().Add(s.opts.RefreshTokenTTL)
+
+	if sessionID == "" {
+		client := middleware.ClientInfoFromContext(ctx)
+		newSession, err := s.sessions.Create(ctx, user.ID.Hex(), client.IP, client.UserAgent, expiresAt)
+		if err != nil {
+			return nil, err
+		}
+		sessionID = newSession.ID.Hex()
+	} else if err := s.sessions.Extend(ctx, sessionID, expiresAt); err != nil {
+		return nil, err
+	}
+
+	membership, err := s.groups.Memberships(ctx, user.TenantID, user.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	// scope = สิทธิ์จริงของ role และ groups ตอนออก token (ชุดเดียวกับที่ AuthInterceptor ตรวจ)
+	permissions, err := s.roles.PermissionsOf(ctx, append([]string{user.Role}, membership.Roles...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := s.jwtService.GenerateToken(user.ID.Hex(), user.Email, user.Role,
+		jwt.WithTenantID(user.TenantID),
+		jwt.WithGroups(membership.Groups...),
+		jwt.WithScope(strings.Join(permissions.List(), " ")),
+		jwt.WithTokenVersion(user.TokenVersion),
+		jwt.WithSessionID(sessionID),
+		jwt.WithAudience(audience),
+		jwt.WithAMR(amr...),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	// refresh token family = session ID
+	refreshToken, err := s.refreshTokens.issue(ctx, user.ID.Hex(), user.TenantID, sessionID, audience, amr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.jwtService.AccessTTL().Seconds()),
 	}, nil
 }
 
 // Logout - ออกจากระบบ
-func (s *Service) Logout(ctx context.Context, token string) (*LogoutResponse, error) {
+func (s *Service) Logout(ctx context.Context, token, refreshToken string) (*LogoutResponse, error) {
 	// Validate token first
 	claims, err := s.jwtService.ValidateToken(token)
 	if err != nil {
@@ -104,13 +433,7 @@ func (s *Service) Logout(ctx context.Context, token string) (*LogoutResponse, er
 	}
 
 	// Add token to blacklist
-	blacklistedToken := bson.M{
-		"token":      token,
-		"expires_at": claims.ExpiresAt.Time,
-		"created_at": time.Now(),
-	}
-
-	_, err = s.db.BlacklistedTokens().InsertOne(ctx, blacklistedToken)
+	err = s.revocations.Revoke(ctx, token, claims.ExpiresAt.Time)
 	if err != nil {
 		log.Printf("Failed to blacklist token: %v", err)
 		return &LogoutResponse{
@@ -119,6 +442,26 @@ func (s *Service) Logout(ctx context.Context, token string) (*LogoutResponse, er
 		}, fmt.Errorf("failed to blacklist token: %w", err)
 	}
 
+	// ยกเลิก session และ refresh token ของ session นี้
+	if claims.SessionID != "" {
+		if err := s.endSession(ctx, claims.UserID, claims.SessionID); err != nil && err != session.ErrNotFound {
+			log.Printf("Failed to revoke session: %v", err)
+			return &LogoutResponse{
+				Success: false,
+				Message: "Internal server error",
+			}, err
+		}
+	}
+	if refreshToken != "" {
+		if err := s.refreshTokens.revokeByToken(ctx, claims.UserID, refreshToken); err != nil {
+			log.Printf("Failed to revoke refresh token: %v", err)
+			return &LogoutResponse{
+				Success: false,
+				Message: "Internal server error",
+			}, err
+		}
+	}
+
 	log.Printf("User logged out successfully: %s", claims.Email)
 
 	return &LogoutResponse{
@@ -127,27 +470,238 @@ func (s *Service) Logout(ctx context.Context, token string) (*LogoutResponse, er
 	}, nil
 }
 
+// JWKS - public keys ที่ใช้ตรวจสอบ access token
+func (s *Service) JWKS() jwt.JWKS {
+	return s.jwtService.JWKS()
+}
+
+// RevokeAllSessions - ยกเลิกทุก session ของ user (access token และ refresh token ทั้งหมด)
+func (s *Service) RevokeAllSessions(ctx context.Context, userID string) (*RevokeAllSessionsResponse, error) {
+	if err := s.userRepo.IncrementTokenVersion(ctx, userID); err != nil {
+		log.Printf("Failed to revoke sessions for user %s: %v", userID, err)
+		return &RevokeAllSessionsResponse{
+			Success: false,
+			Message: "User not found",
+		}, nil
+	}
+
+	if err := s.revokeAllSessions(ctx, userID); err != nil {
+		log.Printf("Failed to revoke sessions for user %s: %v", userID, err)
+		return &RevokeAllSessionsResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	log.Printf("All sessions revoked for user: %s", userID)
+
+	return &RevokeAllSessionsResponse{
+		Success: true,
+		Message: "All sessions revoked successfully",
+	}, nil
+}
+
+// revokeAllSessions - ยกเลิก refresh token และ session ทั้งหมดของ user
+// (ผู้เรียกต้องเพิ่ม token_version ก่อน เพื่อให้ access token ที่ออกไปแล้วใช้ไม่ได้)
+func (s *Service) revokeAllSessions(ctx context.Context, userID string) error {
+	s.revocations.ForgetUser(userID)
+
+	if err := s.refreshTokens.revokeAllForUser(ctx, userID); err != nil {
+		return err
+	}
+
+	return s.sessions.RevokeAllForUser(ctx, userID)
+}
+
+// ListSessions - รายการ session ที่ยังใช้งานได้ของ user
+func (s *Service) ListSessions(ctx context.Context, userID string) ([]*session.Session, error) {
+	sessions, err := s.sessions.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// RevokeSession - ยกเลิก session เดียว (เช่น อุปกรณ์ที่หาย)
+func (s *Service) RevokeSession(ctx context.Context, userID, sessionID string) (*RevokeSessionResponse, error) {
+	err := s.endSession(ctx, userID, sessionID)
+	if err == session.ErrNotFound {
+		return &RevokeSessionResponse{
+			Success: false,
+			Message: "Session not found",
+		}, nil
+	}
+	if err != nil {
+		log.Printf("Failed to revoke session %s: %v", sessionID, err)
+		return &RevokeSessionResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	log.Printf("Session %s revoked for user: %s", sessionID, userID)
+
+	return &RevokeSessionResponse{
+		Success: true,
+		Message: "Session revoked successfully",
+	}, nil
+}
+
+// endSession - ยกเลิก session, refresh token family ของ session และแจ้ง revocation cache
+func (s *Service) endSession(ctx context.Context, userID, sessionID string) error {
+	if err := s.sessions.Revoke(ctx, userID, sessionID); err != nil {
+		return err
+	}
+	s.revocations.MarkSessionRevoked(sessionID)
+
+	return s.refreshTokens.revokeFamily(ctx, sessionID)
+}
+
+// Introspect - ตรวจสอบสถานะของ access token สำหรับ service อื่น (RFC 7662)
+// token ที่ถูก logout หรือเป็นของ user ที่ถูกปิดใช้งาน/ลบแล้ว จะถือว่า inactive
+func (s *Service) Introspect(ctx context.Context, token string) (*IntrospectResponse, error) {
+	inactive := &IntrospectResponse{Active: false}
+
+	if token == "" {
+		return inactive, nil
+	}
+
+	claims, err := s.jwtService.ValidateToken(token)
+	if err != nil {
+		return inactive, nil
+	}
+
+	revoked, err := s.revocations.IsRevoked(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check revocation: %w", err)
+	}
+	if revoked {
+		return inactive, nil
+	}
+
+	// user ที่ถูกปิดใช้งาน/ลบ หรือ revoke ทุก session ไปแล้ว
+	userRevoked, err := s.revocations.IsUserRevoked(ctx, claims.UserID, claims.TokenVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check user status: %w", err)
+	}
+	if userRevoked {
+		return inactive, nil
+	}
+
+	if claims.SessionID != "" {
+		sessionRevoked, err := s.revocations.IsSessionRevoked(ctx, claims.SessionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check session: %w", err)
+		}
+		if sessionRevoked {
+			return inactive, nil
+		}
+	}
+
+	response := &IntrospectResponse{
+		Active:    true,
+		Sub:       claims.UserID,
+		TenantID:  claims.TenantID,
+		Email:     claims.Email,
+		Role:      claims.Role,
+		Scope:     claims.Scope,
+		Iss:       claims.Issuer,
+		Aud:       claims.Audience,
+		TokenType: "Bearer",
+	}
+	if claims.ExpiresAt != nil {
+		response.Exp = claims.ExpiresAt.Unix()
+	}
+	if claims.IssuedAt != nil {
+		response.Iat = claims.IssuedAt.Unix()
+	}
+	if claims.NotBefore != nil {
+		response.Nbf = claims.NotBefore.Unix()
+	}
+
+	return response, nil
+}
+
+// RotateSigningKeys - rotate signing keys ทันที และโหลด key ring ใหม่
+// keys ใช้ร่วมกันทุก tenant จึง rotate ได้จาก tenant เริ่มต้นเท่านั้น
+func (s *Service) RotateSigningKeys(ctx context.Context) ([]jwt.KeyInfo, error) {
+	if !s.fromDefaultTenant(ctx) {
+		return nil, status.Errorf(codes.PermissionDenied, "Signing keys can only be rotated from the default tenant")
+	}
+
+	if err := s.keyStore.Rotate(ctx); err != nil {
+		return nil, fmt.Errorf("failed to rotate signing keys: %w", err)
+	}
+
+	if err := s.keyStore.Load(ctx, s.jwtService.KeyRing()); err != nil {
+		return nil, fmt.Errorf("failed to reload signing keys: %w", err)
+	}
+
+	log.Printf("Signing keys rotated - active kid: %s", s.jwtService.KeyRing().Active().KID)
+
+	return s.keyStore.List(ctx)
+}
+
 // Register - สมัครสมาชิก
 func (s *Service) Register(ctx context.Context, req *RegisterRequest) (*RegisterResponse, error) {
 	// Validate input
-	if err := s.validateRegisterRequest(req); err != nil {
+	if violations := s.validateRegisterRequest(req); len(violations) > 0 {
 		return &RegisterResponse{
 			Success: false,
-			Message: err.Error(),
+			Message: violations[0].Description,
+		}, invalidArgumentError(violations)
+	}
+
+	ctx, err := s.tenantContext(ctx, req.TenantID)
+	if err == tenant.ErrTenantNotFound {
+		return &RegisterResponse{
+			Success: false,
+			Message: "Invalid tenant",
 		}, nil
 	}
+	if err != nil {
+		return &RegisterResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	// ทั้งสองกรณีส่ง email 1 ฉบับ จึงใช้ rate limit เดียวกันเพื่อไม่ให้ถูกใช้ spam ผู้อื่น
+	if s.opts.EnumerationProtection {
+		if err := s.checkEmailRate(ctx, req.Email); err != nil {
+			return nil, err
+		}
+	}
 
 	// Check if email already exists
 	existingUser, _ := s.userRepo.GetByEmail(ctx, req.Email)
 	if existingUser != nil {
+		if !s.opts.EnumerationProtection {
+			return &RegisterResponse{
+				Success: false,
+				Message: "Email already registered",
+			}, nil
+		}
+
+		// hash รหัสผ่านเหมือนการสมัครจริงเพื่อให้เวลาตอบเท่ากัน
+		if _, err := s.passwords.Hash(ctx, req.Password); err != nil {
+			message, err := hashingFailure(ctx, err)
+			return &RegisterResponse{
+				Success: false,
+				Message: message,
+			}, err
+		}
+
+		log.Printf("Registration attempt for existing email: %s", req.Email)
+		s.notifyRegistrationAttempt(ctx, existingUser)
 		return &RegisterResponse{
-			Success: false,
-			Message: "Email already registered",
+			Success: true,
+			Message: registrationAcceptedMessage,
 		}, nil
 	}
 
 	// Create new user
-	user := &models.User{
+	newUser := &models.User{
 		Email:     req.Email,
 		FirstName: req.FirstName,
 		LastName:  req.LastName,
@@ -155,16 +709,25 @@ func (s *Service) Register(ctx context.Context, req *RegisterRequest) (*Register
 	}
 
 	// Hash password
-	if err := user.HashPassword(req.Password); err != nil {
+	if err := newUser.HashPassword(ctx, s.passwords, req.Password); err != nil {
 		log.Printf("Failed to hash password: %v", err)
+		message, err := hashingFailure(ctx, err)
 		return &RegisterResponse{
 			Success: false,
-			Message: "Internal server error",
-		}, fmt.Errorf("failed to hash password: %w", err)
+			Message: message,
+		}, err
 	}
 
 	// Save user
-	if err := s.userRepo.Create(ctx, user); err != nil {
+	if err := s.userRepo.Create(ctx, newUser); err != nil {
+		// email ของบัญชีที่ถูกปิดหรือลบยังซ้ำกับ unique index - ตอบเหมือนสมัครสำเร็จ
+		if s.opts.EnumerationProtection && errors.Is(err, user.ErrEmailExists) {
+			log.Printf("Registration attempt for inactive email: %s", req.Email)
+			return &RegisterResponse{
+				Success: true,
+				Message: registrationAcceptedMessage,
+			}, nil
+		}
 		log.Printf("Failed to create user: %v", err)
 		return &RegisterResponse{
 			Success: false,
@@ -174,41 +737,66 @@ func (s *Service) Register(ctx context.Context, req *RegisterRequest) (*Register
 
 	log.Printf("User registered successfully: %s", req.Email)
 
+	// ส่งลิงก์ยืนยัน email (ถ้าส่งไม่สำเร็จ user ขอใหม่ได้ด้วย ResendVerification)
+	if err := s.sendVerificationEmail(ctx, newUser); err != nil {
+		log.Printf("Failed to send verification email to %s: %v", req.Email, err)
+	}
+
+	if s.opts.EnumerationProtection {
+		return &RegisterResponse{
+			Success: true,
+			Message: registrationAcceptedMessage,
+		}, nil
+	}
+
 	return &RegisterResponse{
 		Success: true,
-		Message: "Account created successfully",
-		UserID:  user.ID.Hex(),
+		Message: "Account created successfully. Please check your email to verify your address.",
+		UserID:  newUser.ID.Hex(),
 	}, nil
 }
 
-// validateRegisterRequest - ตรวจสอบข้อมูลการสมัคร
-func (s *Service) validateRegisterRequest(req *RegisterRequest) error {
-	if req.Email == "" {
-		return fmt.Errorf("email is required")
+// invalidCredentials - ตอบ login ไม่สำเร็จแบบเดียวกับรหัสผ่านผิด
+// โหมด EnumerationProtection จะตรวจรหัสผ่านกับ hash หลอกก่อน เพื่อให้ใช้เวลาเท่ากัน
+func (s *Service) invalidCredentials(ctx context.Context, password string) (*LoginResponse, error) {
+	if s.opts.EnumerationProtection {
+		if err := s.dummyPasswordCheck(ctx, password); err != nil {
+			message, err := hashingFailure(ctx, err)
+			return &LoginResponse{
+				Success: false,
+				Message: message,
+			}, err
+		}
 	}
 
-	if req.Password == "" {
-		return fmt.Errorf("password is required")
-	}
+	return &LoginResponse{
+		Success: false,
+		Message: "Invalid email or password",
+	}, nil
+}
+
+// validateRegisterRequest - ตรวจสอบข้อมูลการสมัคร คืนทุก field ที่ไม่ผ่าน
+func (s *Service) validateRegisterRequest(req *RegisterRequest) []*errdetails.BadRequest_FieldViolation {
+	var violations []*errdetails.BadRequest_FieldViolation
 
-	if len(req.Password) < 6 {
-		return fmt.Errorf("password must be at least 6 characters")
+	if req.Email == "" {
+		violations = append(violations, fieldViolation("email", "REQUIRED", "email is required"))
+	} else if !isValidEmail(req.Email) {
+		// Simple email validation
+		violations = append(violations, fieldViolation("email", "INVALID_FORMAT", "invalid email format"))
 	}
 
 	if req.FirstName == "" {
-		return fmt.Errorf("first name is required")
+		violations = append(violations, fieldViolation("first_name", "REQUIRED", "first name is required"))
 	}
 
 	if req.LastName == "" {
-		return fmt.Errorf("last name is required")
+		violations = append(violations, fieldViolation("last_name", "REQUIRED", "last name is required"))
 	}
 
-	// Simple email validation
-	if !isValidEmail(req.Email) {
-		return fmt.Errorf("invalid email format")
-	}
+	violations = append(violations, s.passwordViolations("password", req.Password, req.Email, req.FirstName, req.LastName)...)
 
-	return nil
+	return violations
 }
 
 // isValidEmail - ตรวจสอบรูปแบบ email
@@ -231,10 +819,183 @@ func contains(s, substr string) bool {
 
 // Response structs
 type LoginResponse struct {
-	Success bool                   `json:"success"`
-	Message string                 `json:"message"`
-	Token   string                 `json:"token,omitempty"`
-	User    map[string]interface{} `json:"user,omitempty"`
+	Success      bool                   `json:"success"`
+	Message      string                 `json:"message"`
+	Token        string                 `json:"token,omitempty"`
+	RefreshToken string                 `json:"refresh_token,omitempty"`
+	ExpiresIn    int64                  `json:"expires_in,omitempty"`
+	User         map[string]interface{} `json:"user,omitempty"`
+	MFARequired  bool                   `json:"mfa_required,omitempty"`
+	MFAToken     string                 `json:"mfa_token,omitempty"`
+	MFAMethods   []string               `json:"mfa_methods,omitempty"`
+}
+
+type RefreshResponse struct {
+	Success      bool   `json:"success"`
+	Message      string `json:"message"`
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int64  `json:"expires_in,omitempty"`
+}
+
+type RevokeAllSessionsResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+type RevokeSessionResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+type IntrospectResponse struct {
+	Active    bool     `json:"active"`
+	Sub       string   `json:"sub,omitempty"`
+	Email     string   `json:"email,omitempty"`
+	Role      string   `json:"role,omitempty"`
+	Scope     string   `json:"scope,omitempty"`
+	Exp       int64    `json:"exp,omitempty"`
+	Iat       int64    `json:"iat,omitempty"`
+	Iss       string   `json:"iss,omitempty"`
+	Aud       []string `json:"aud,omitempty"`
+	Nbf       int64    `json:"nbf,omitempty"`
+	TokenType string   `json:"token_type,omitempty"`
+	TenantID  string   `json:"tenant_id,omitempty"`
+}
+
+type UnlockAccountResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+type EnrollTOTPResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Secret  string `json:"secret,omitempty"`
+	URI     string `json:"uri,omitempty"`
+}
+
+type ConfirmTOTPResponse struct {
+	Success       bool     `json:"success"`
+	Message       string   `json:"message"`
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+type DisableTOTPResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+type PasskeyCeremonyResponse struct {
+	Success     bool   `json:"success"`
+	Message     string `json:"message"`
+	CeremonyID  string `json:"ceremony_id,omitempty"`
+	OptionsJSON string `json:"options_json,omitempty"` // PublicKeyCredentialCreationOptions / RequestOptions
+}
+
+type FinishPasskeyRegistrationResponse struct {
+	Success      bool   `json:"success"`
+	Message      string `json:"message"`
+	CredentialID string `json:"credential_id,omitempty"`
+}
+
+type VerifyEmailResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+type ResendVerificationResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+type RequestPasswordResetResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+type ResetPasswordResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+type ChangePasswordResponse struct {
+	Success           bool   `json:"success"`
+	Message           string `json:"message"`
+	SignedOutSessions int32  `json:"signed_out_sessions"`
+}
+
+type RoleResponse struct {
+	Success bool       `json:"success"`
+	Message string     `json:"message"`
+	Role    *rbac.Role `json:"role,omitempty"`
+}
+
+type DeleteRoleResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+type TenantResponse struct {
+	Success     bool           `json:"success"`
+	Message     string         `json:"message"`
+	Tenant      *tenant.Tenant `json:"tenant,omitempty"`
+	AdminUserID string         `json:"admin_user_id,omitempty"`
+}
+
+// TenantAdmin - ผู้ดูแลคนแรกของ tenant ใหม่
+type TenantAdmin struct {
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+type GroupResponse struct {
+	Success bool         `json:"success"`
+	Message string       `json:"message"`
+	Group   *group.Group `json:"group,omitempty"`
+}
+
+type DeleteGroupResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+type GroupMemberResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+type GroupMembersResponse struct {
+	Success bool           `json:"success"`
+	Message string         `json:"message"`
+	Members []*models.User `json:"members,omitempty"`
+}
+
+type SetRoleResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+type DeactivateUserResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+type ReactivateUserResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+type RestoreUserResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+type tokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64
 }
 
 type LogoutResponse struct {
@@ -253,4 +1014,5 @@ type RegisterRequest struct {
 	Password  string `json:"password"`
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
+	TenantID  string `json:"tenant_id,omitempty"`
 }
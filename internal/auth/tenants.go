@@ -0,0 +1,207 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"auth-microservice/internal/audit"
+	"auth-microservice/internal/mailer"
+	"auth-microservice/internal/middleware"
+	"auth-microservice/internal/models"
+	"auth-microservice/internal/rbac"
+	"auth-microservice/internal/tenant"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// tenantIDPattern - tenant ID ที่ใช้ได้ (client ส่งมาตอน Login และอยู่ใน claim tenant_id)
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}$`)
+
+// tenantContext - ตรวจสอบ tenant แล้วใส่ลงใน context ให้ user.Repository ใช้ scope query
+// ใช้กับ method ที่ไม่ต้อง login (tenantID จาก request) และ flow ที่รู้ tenant จาก token ที่ออกไปแล้ว
+// tenantID ว่าง = tenant เริ่มต้น (client เดิมที่ยังไม่ส่ง tenant และ token ที่ออกก่อนรองรับ tenant)
+func (s *Service) tenantContext(ctx context.Context, tenantID string) (context.Context, error) {
+	tenantID = strings.TrimSpace(tenantID)
+	if tenantID == "" {
+		tenantID = s.opts.DefaultTenant
+	}
+
+	if _, err := s.tenants.Get(ctx, tenantID); err != nil {
+		return ctx, err
+	}
+	return middleware.WithTenant(ctx, tenantID), nil
+}
+
+// isTenantUser - userID อยู่ใน tenant ของ request หรือไม่ (ใช้ก่อนเข้าถึงข้อมูลที่ไม่ได้แยกตาม tenant เช่น sessions)
+func (s *Service) isTenantUser(ctx context.Context, userID string) bool {
+	_, err := s.userRepo.GetByIDAnyState(ctx, userID)
+	return err == nil
+}
+
+// fromDefaultTenant - ผู้เรียกอยู่ใน tenant เริ่มต้นหรือไม่
+// ข้อมูลที่ใช้ร่วมกันทุก tenant (tenants และ roles) จัดการได้จาก tenant นี้เท่านั้น
+// ไม่เช่นนั้นผู้ดูแลของ tenant หนึ่งจะกระทบ user ของทุก tenant
+func (s *Service) fromDefaultTenant(ctx context.Context) bool {
+	callerTenant, _ := middleware.TenantIDFromContext(ctx)
+	return callerTenant == s.opts.DefaultTenant
+}
+
+// globalRolesDefaultTenantOnlyMessage - role ที่มีสิทธิ์กระทบทุก tenant กำหนดให้ user ได้จาก tenant เริ่มต้นเท่านั้น
+const globalRolesDefaultTenantOnlyMessage = "Roles with permissions over all tenants can only be assigned from the default tenant"
+
+// grantsGlobalRights - roles ให้สิทธิ์ที่มีผลกับทุก tenant หรือไม่ (เช่น admin ที่มี "*")
+func (s *Service) grantsGlobalRights(ctx context.Context, roles ...string) (bool, error) {
+	permissions, err := s.roles.PermissionsOf(ctx, roles...)
+	if err != nil {
+		return false, err
+	}
+	return permissions.HasGlobal(), nil
+}
+
+// ListTenants - tenant ทั้งหมด (เฉพาะผู้เรียกจาก tenant เริ่มต้น ไม่เปิดเผยรายชื่อ tenant ให้ tenant อื่น)
+func (s *Service) ListTenants(ctx context.Context) ([]tenant.Tenant, error) {
+	if !s.fromDefaultTenant(ctx) {
+		return nil, status.Errorf(codes.PermissionDenied, "Tenants can only be managed from the default tenant")
+	}
+	return s.tenants.List(ctx)
+}
+
+// CreateTenant - สร้าง tenant ใหม่พร้อมผู้ดูแลคนแรก (เฉพาะผู้เรียกจาก tenant เริ่มต้น เพราะ roles ใช้ร่วมกันทุก tenant)
+// user และ role ถูกจัดการภายใน tenant ของผู้เรียกเท่านั้น tenant ใหม่จึงต้องได้ผู้ดูแลตอนสร้าง
+// ผู้ดูแลได้ role tenant_admin (ไม่มีสิทธิ์ที่กระทบทุก tenant) และลิงก์ตั้งรหัสผ่านทาง email (ใช้ ResetPassword)
+func (s *Service) CreateTenant(ctx context.Context, actorID, tenantID, name string, admin TenantAdmin) (*TenantResponse, error) {
+	if !s.fromDefaultTenant(ctx) {
+		return &TenantResponse{
+			Success: false,
+			Message: "Tenants can only be managed from the default tenant",
+		}, nil
+	}
+
+	tenantID = strings.TrimSpace(tenantID)
+	if !tenantIDPattern.MatchString(tenantID) {
+		return &TenantResponse{
+			Success: false,
+			Message: "Tenant ID must be 2-63 characters of a-z, 0-9 or - and start with a letter or digit",
+		}, nil
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return &TenantResponse{
+			Success: false,
+			Message: "Tenant name is required",
+		}, nil
+	}
+
+	admin.Email = strings.TrimSpace(admin.Email)
+	admin.FirstName = strings.TrimSpace(admin.FirstName)
+	admin.LastName = strings.TrimSpace(admin.LastName)
+	if !isValidEmail(admin.Email) {
+		return &TenantResponse{
+			Success: false,
+			Message: "A valid admin email is required",
+		}, nil
+	}
+	if admin.FirstName == "" || admin.LastName == "" {
+		return &TenantResponse{
+			Success: false,
+			Message: "Admin first and last name are required",
+		}, nil
+	}
+
+	// รหัสผ่านสุ่มที่ไม่มีใครรู้ - ผู้ดูแลต้องตั้งรหัสผ่านจากลิงก์ก่อน login ได้
+	placeholder, err := generateOpaqueToken()
+	if err != nil {
+		return &TenantResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+	adminUser := &models.User{
+		TenantID:  tenantID,
+		Email:     admin.Email,
+		FirstName: admin.FirstName,
+		LastName:  admin.LastName,
+		Role:      rbac.RoleTenantAdmin,
+	}
+	if err := adminUser.HashPassword(ctx, s.passwords, placeholder); err != nil {
+		message, err := hashingFailure(ctx, err)
+		return &TenantResponse{
+			Success: false,
+			Message: message,
+		}, err
+	}
+
+	created, err := s.tenants.Create(ctx, tenantID, name)
+	if err == tenant.ErrTenantExists {
+		return &TenantResponse{
+			Success: false,
+			Message: "Tenant already exists",
+		}, nil
+	}
+	if err != nil {
+		return &TenantResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	tenantCtx := middleware.WithTenant(ctx, created.ID)
+	if err := s.userRepo.Create(tenantCtx, adminUser); err != nil {
+		// ยกเลิก tenant ที่ไม่มีผู้ดูแล เพื่อให้สร้างใหม่ด้วย ID เดิมได้
+		if deleteErr := s.tenants.Delete(ctx, created.ID); deleteErr != nil {
+			log.Printf("Failed to roll back tenant %s: %v", created.ID, deleteErr)
+		}
+		return &TenantResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, fmt.Errorf("failed to create tenant admin: %w", err)
+	}
+
+	s.recordAudit(ctx, audit.Event{
+		Type:     audit.EventTenantCreated,
+		ActorID:  actorID,
+		TargetID: adminUser.ID.Hex(),
+		Details: map[string]string{
+			"tenant":      created.ID,
+			"name":        created.Name,
+			"admin_email": adminUser.Email,
+		},
+	})
+	log.Printf("Tenant created: %s (%s) with admin %s", created.ID, created.Name, adminUser.Email)
+
+	// ส่งไม่สำเร็จไม่ยกเลิก tenant - ผู้ดูแลขอลิงก์ใหม่ได้ด้วย RequestPasswordReset พร้อม tenant นี้
+	if err := s.sendTenantInvitation(tenantCtx, created, adminUser); err != nil {
+		log.Printf("Failed to send tenant invitation to %s: %v", adminUser.Email, err)
+	}
+
+	return &TenantResponse{
+		Success:     true,
+		Message:     "Tenant created successfully",
+		Tenant:      created,
+		AdminUserID: adminUser.ID.Hex(),
+	}, nil
+}
+
+// sendTenantInvitation - ส่งลิงก์ตั้งรหัสผ่านให้ผู้ดูแลคนแรกของ tenant (อายุเท่าลิงก์ยืนยัน email)
+func (s *Service) sendTenantInvitation(ctx context.Context, t *tenant.Tenant, admin *models.User) error {
+	token, err := s.actionTokens.issue(ctx, purposeResetPassword, admin.ID.Hex(), t.ID, admin.Email, s.opts.VerificationTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.opts.AppBaseURL + "/reset-password?token=" + url.QueryEscape(token)
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      admin.Email,
+		Subject: fmt.Sprintf("You are the administrator of %s", t.Name),
+		Body: fmt.Sprintf("Hi %s,\n\nAn account has been created for you as the administrator of %s (tenant: %s).\n"+
+			"Open the link below to choose your password:\n\n%s\n\n"+
+			"This link expires in %s. After that, request a new one with \"Forgot password\" using tenant %s.\n",
+			admin.FirstName, t.Name, t.ID, link, s.opts.VerificationTokenTTL, t.ID),
+	})
+}
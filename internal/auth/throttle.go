@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"context"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"auth-microservice/internal/middleware"
+	"auth-microservice/internal/ratelimit"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// LoginLimits - rate limits ของ Login
+type LoginLimits struct {
+	PerIP      ratelimit.Rule
+	PerEmail   ratelimit.Rule
+	PerIPEmail ratelimit.Rule
+}
+
+// checkLoginRate - นับการ login และคืน ResourceExhausted ถ้าเกิน limit ใด limit หนึ่ง
+func (s *Service) checkLoginRate(ctx context.Context, email string) error {
+	client := middleware.ClientInfoFromContext(ctx)
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	// email ว่าง (เช่น login ด้วย passkey) = นับเฉพาะต่อ IP
+	var ipEmail string
+	if client.IP != "" && email != "" {
+		ipEmail = client.IP + "|" + email
+	}
+
+	checks := []struct {
+		rule ratelimit.Rule
+		key  string
+	}{
+		{s.opts.LoginLimits.PerIP, client.IP},
+		{s.opts.LoginLimits.PerEmail, email},
+		{s.opts.LoginLimits.PerIPEmail, ipEmail},
+	}
+
+	var retryAfter time.Duration
+	for _, check := range checks {
+		if check.rule.Limit <= 0 || check.key == "" {
+			continue
+		}
+
+		result, err := s.rateLimiter.Allow(ctx, check.rule, check.key)
+		if err != nil {
+			log.Printf("Rate limit check failed: %v", err)
+			return status.Errorf(codes.Unavailable, "Unable to process login. Please try again later.")
+		}
+
+		if !result.Allowed && result.RetryAfter > retryAfter {
+			log.Printf("Rate limit exceeded (%s) for login attempt from %s", check.rule.Name, client.IP)
+			retryAfter = result.RetryAfter
+		}
+	}
+
+	if retryAfter > 0 {
+		return rateLimitError(ctx, "Too many login attempts. Please try again later.", retryAfter)
+	}
+	return nil
+}
+
+// checkEmailRate - จำกัดจำนวน email ที่ส่งไปยังที่อยู่เดียวกัน (ยืนยัน email, reset รหัสผ่าน)
+func (s *Service) checkEmailRate(ctx context.Context, email string) error {
+	rule := s.opts.EmailLimit
+	if rule.Limit <= 0 {
+		return nil
+	}
+
+	result, err := s.rateLimiter.Allow(ctx, rule, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		log.Printf("Rate limit check failed: %v", err)
+		return status.Errorf(codes.Unavailable, "Unable to process request. Please try again later.")
+	}
+
+	if !result.Allowed {
+		log.Printf("Rate limit exceeded (%s)", rule.Name)
+		return rateLimitError(ctx, "Too many requests. Please try again later.", result.RetryAfter)
+	}
+	return nil
+}
+
+// rateLimitError - ResourceExhausted พร้อม RetryInfo และ header retry-after (วินาที)
+func rateLimitError(ctx context.Context, message string, retryAfter time.Duration) error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(seconds)))
+
+	st := status.New(codes.ResourceExhausted, message)
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(retryAfter),
+	}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
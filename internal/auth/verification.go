@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	"auth-microservice/internal/mailer"
+	"auth-microservice/internal/models"
+	"auth-microservice/internal/tenant"
+)
+
+// sendVerificationEmail - ออก token ยืนยัน email และส่งลิงก์ให้ user
+func (s *Service) sendVerificationEmail(ctx context.Context, user *models.User) error {
+	token, err := s.actionTokens.issue(ctx, purposeVerifyEmail, user.ID.Hex(), user.TenantID, user.Email, s.opts.VerificationTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.opts.AppBaseURL + "/verify-email?token=" + url.QueryEscape(token)
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\n"+
+			"This link expires in %s. If you did not create an account, you can ignore this email.\n",
+			user.FirstName, link, s.opts.VerificationTokenTTL),
+	})
+}
+
+// VerifyEmail - ยืนยัน email ด้วย token ที่ส่งไปทาง email (ใช้ได้ครั้งเดียว)
+func (s *Service) VerifyEmail(ctx context.Context, token string) (*VerifyEmailResponse, error) {
+	if token == "" {
+		return &VerifyEmailResponse{
+			Success: false,
+			Message: "Verification token is required",
+		}, nil
+	}
+
+	doc, err := s.actionTokens.consume(ctx, purposeVerifyEmail, token)
+	if err == errInvalidActionToken {
+		return &VerifyEmailResponse{
+			Success: false,
+			Message: "Invalid or expired verification token",
+		}, nil
+	}
+	if err != nil {
+		return &VerifyEmailResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	// token ของ email เก่าใช้ไม่ได้หลังเปลี่ยน email
+	ctx, err = s.tenantContext(ctx, doc.TenantID)
+	if err == nil {
+		err = s.userRepo.MarkEmailVerified(ctx, doc.UserID, doc.Email)
+	}
+	if err != nil {
+		log.Printf("Email verification failed for user %s: %v", doc.UserID, err)
+		return &VerifyEmailResponse{
+			Success: false,
+			Message: "Invalid or expired verification token",
+		}, nil
+	}
+
+	log.Printf("Email verified: %s", doc.Email)
+
+	return &VerifyEmailResponse{
+		Success: true,
+		Message: "Email verified successfully",
+	}, nil
+}
+
+// ResendVerification - ส่งลิงก์ยืนยัน email อีกครั้ง
+// ตอบเหมือนกันเสมอไม่ว่าจะมี email นี้หรือไม่ (ไม่เปิดเผยว่ามีบัญชีใดบ้าง)
+func (s *Service) ResendVerification(ctx context.Context, tenantID, email string) (*ResendVerificationResponse, error) {
+	response := &ResendVerificationResponse{
+		Success: true,
+		Message: "If the account exists and is not yet verified, a verification email has been sent",
+	}
+
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return &ResendVerificationResponse{
+			Success: false,
+			Message: "Email is required",
+		}, nil
+	}
+
+	if err := s.checkEmailRate(ctx, email); err != nil {
+		return nil, err
+	}
+
+	ctx, err := s.tenantContext(ctx, tenantID)
+	if err == tenant.ErrTenantNotFound {
+		return &ResendVerificationResponse{
+			Success: false,
+			Message: "Invalid tenant",
+		}, nil
+	}
+	if err != nil {
+		return &ResendVerificationResponse{
+			Success: false,
+			Message: "Internal server error",
+		}, err
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil || user.EmailVerified {
+		return response, nil
+	}
+
+	// ส่งไม่สำเร็จก็ตอบเหมือนกรณีไม่มีบัญชี - ไม่เช่นนั้นผลที่ต่างกันจะบอกว่า email นี้มีอยู่จริง
+	if err := s.sendVerificationEmail(ctx, user); err != nil {
+		log.Printf("Failed to send verification email to %s: %v", email, err)
+		return response, nil
+	}
+
+	log.Printf("Verification email resent to: %s", email)
+
+	return response, nil
+}
@@ -2,6 +2,9 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -9,6 +12,135 @@ type Config struct {
 	MongoURI  string
 	DBName    string
 	JWTSecret string
+
+	// JWTAlgorithm - HS256, RS256, ES256 หรือ EdDSA
+	JWTAlgorithm string
+	// JWTPrivateKeyFile - path ของ private key (PEM) สำหรับ algorithm แบบ asymmetric
+	JWTPrivateKeyFile string
+	// KeyRotationInterval - rotate signing key อัตโนมัติทุกช่วงเวลานี้ (0 = ปิด)
+	KeyRotationInterval time.Duration
+	// KeyRefreshInterval - โหลด signing keys จาก MongoDB ใหม่ทุกช่วงเวลานี้
+	KeyRefreshInterval time.Duration
+	// HTTPPort - port ของ HTTP server (/.well-known/jwks.json)
+	HTTPPort string
+	// MetricsAddr - address ของ HTTP server ภายในสำหรับ /debug/vars (ว่าง = ปิด, ห้ามเปิดสู่ภายนอก)
+	MetricsAddr string
+
+	// AccessTokenTTL - อายุของ access token (JWT)
+	AccessTokenTTL time.Duration
+	// TokenIssuer - ค่า iss ของ token
+	TokenIssuer string
+	// TokenAudience - audience ของ service นี้ (ทุก token มีค่านี้)
+	TokenAudience string
+	// AllowedAudiences - audiences ที่ client ขอได้ตอน login (คั่นด้วย comma)
+	AllowedAudiences []string
+	// TokenLeeway - ยอมให้นาฬิกาคลาดเคลื่อนได้ตอนตรวจสอบ token
+	TokenLeeway time.Duration
+	// RefreshTokenTTL - อายุของ refresh token แต่ละตัว (ต่ออายุทุกครั้งที่ rotate)
+	RefreshTokenTTL time.Duration
+
+	// ServiceClients - client ID → secret ของ service อื่นที่เรียก Introspect ได้
+	ServiceClients map[string]string
+
+	// SessionTouchInterval - อัพเดท last_seen_at ของ session ไม่บ่อยกว่าช่วงเวลานี้
+	SessionTouchInterval time.Duration
+	// TrustProxyHeaders - ใช้ x-forwarded-for เป็น IP ของ client (เมื่ออยู่หลัง load balancer)
+	TrustProxyHeaders bool
+
+	// Login rate limits (รูปแบบ "จำนวน/ช่วงเวลา" เช่น "20/1m")
+	LoginRateLimitIP      RateLimit
+	LoginRateLimitEmail   RateLimit
+	LoginRateLimitIPEmail RateLimit
+
+	// LockoutThreshold - ล็อคบัญชีหลัง login ผิดติดต่อกันครบจำนวนนี้ (0 = ปิด)
+	LockoutThreshold int
+	// LockoutBaseDuration - ระยะเวลาล็อคครั้งแรก (เพิ่มเป็น 2 เท่าทุกครั้งที่ผิดซ้ำ)
+	LockoutBaseDuration time.Duration
+	// LockoutMaxDuration - ระยะเวลาล็อคสูงสุด (0 = ไม่จำกัด แต่ไม่เกิน 1 ปี)
+	LockoutMaxDuration time.Duration
+
+	// MFAChallengeTTL - อายุของ challenge token ระหว่าง Login กับ VerifyMFA
+	MFAChallengeTTL time.Duration
+	// TOTPIssuer - ชื่อ issuer ที่แสดงใน authenticator app
+	TOTPIssuer string
+
+	// WebAuthnRPID - relying party ID (domain ของเว็บที่ใช้ passkey)
+	WebAuthnRPID string
+	// WebAuthnRPName - ชื่อที่แสดงตอนสร้าง passkey
+	WebAuthnRPName string
+	// WebAuthnOrigins - origins ที่อนุญาต (คั่นด้วย comma)
+	WebAuthnOrigins []string
+	// WebAuthnCeremonyTTL - เวลาที่ client มีในการตอบ challenge ของ passkey
+	WebAuthnCeremonyTTL time.Duration
+
+	// RequireVerifiedEmail - ไม่ให้ user ที่ยังไม่ยืนยัน email login
+	RequireVerifiedEmail bool
+	// VerificationTokenTTL - อายุของลิงก์ยืนยัน email
+	VerificationTokenTTL time.Duration
+	// PasswordResetTTL - อายุของลิงก์ reset รหัสผ่าน
+	PasswordResetTTL time.Duration
+	// AppBaseURL - URL ของหน้าเว็บที่ใช้สร้างลิงก์ใน email
+	AppBaseURL string
+	// EmailRateLimit - จำนวน email สูงสุดที่ส่งไปยังที่อยู่เดียวกัน (รูปแบบ "จำนวน/ช่วงเวลา")
+	EmailRateLimit RateLimit
+
+	// EnumerationProtection - Login/Register ตอบเหมือนกันไม่ว่าจะมี email ในระบบหรือไม่
+	EnumerationProtection bool
+
+	// MailDriver - log, file หรือ smtp
+	MailDriver string
+	// MailFrom - ผู้ส่ง email
+	MailFrom string
+	// MailFileDir - โฟลเดอร์ที่เขียน email (MAIL_DRIVER=file)
+	MailFileDir  string
+	SMTPAddr     string
+	SMTPUsername string
+	SMTPPassword string
+
+	// Password policy - ใช้กับ Register, ChangePassword และ ResetPassword
+	PasswordMinLength            int
+	PasswordMaxLength            int // bytes (bcrypt ใช้ได้ไม่เกิน 72)
+	PasswordRequireUpper         bool
+	PasswordRequireLower         bool
+	PasswordRequireDigit         bool
+	PasswordRequireSymbol        bool
+	PasswordDisallowPersonalInfo bool
+	// PasswordBlocklistFile - ไฟล์รหัสผ่านที่เคยรั่วไหล (ว่าง = ไม่ตรวจ)
+	PasswordBlocklistFile string
+	// PasswordBlocklistFormat - sha1 หรือ bloom
+	PasswordBlocklistFormat string
+
+	// PasswordHashAlgorithm - argon2id หรือ bcrypt (hash เดิมของอีก algorithm ยังตรวจได้และถูก rehash ตอน login)
+	PasswordHashAlgorithm string
+	// Argon2Memory - หน่วยความจำของ Argon2id (KiB)
+	Argon2Memory      int
+	Argon2Time        int
+	Argon2Parallelism int
+	BcryptCost        int
+
+	// PasswordHashWorkers - จำนวน hash พร้อมกันสูงสุด (0 = จำนวน CPU)
+	PasswordHashWorkers int
+	// PasswordHashQueue - จำนวน request ที่รอ hash ได้ (เกินนี้ตอบ ResourceExhausted)
+	PasswordHashQueue int
+	// PasswordHashTimeout - เวลาสูงสุดต่อ request รวมเวลารอคิว (เกินนี้ตอบ Unavailable)
+	PasswordHashTimeout time.Duration
+
+	// RoleCacheTTL - ระยะเวลาที่ cache สิทธิ์ของ role (การแก้ไขจาก replica อื่นมีผลภายในเวลานี้)
+	RoleCacheTTL time.Duration
+	// GroupCacheTTL - ระยะเวลาที่ cache groups ของ user (การแก้ไขจาก replica อื่นมีผลภายในเวลานี้)
+	GroupCacheTTL time.Duration
+
+	// DefaultTenant - tenant ที่ใช้เมื่อ client ไม่ระบุ tenant ตอน Login/Register (user เดิมถูกย้ายเข้า tenant นี้)
+	DefaultTenant string
+
+	// RevocationCacheTTL - ระยะเวลาที่ cache ผลว่า token ยังไม่ถูก revoke
+	RevocationCacheTTL time.Duration
+}
+
+// RateLimit - จำนวนครั้งสูงสุดต่อช่วงเวลา
+type RateLimit struct {
+	Limit  int
+	Window time.Duration
 }
 
 func New() *Config {
@@ -17,6 +149,84 @@ func New() *Config {
 		MongoURI:  getEnv("MONGO_URI", "mongodb://localhost:27017"),
 		DBName:    getEnv("DB_NAME", "auth_microservice"),
 		JWTSecret: getEnv("JWT_SECRET", "your-super-secret-key-change-in-production"),
+
+		JWTAlgorithm:      getEnv("JWT_ALGORITHM", "HS256"),
+		JWTPrivateKeyFile: getEnv("JWT_PRIVATE_KEY_FILE", ""),
+		HTTPPort:          getEnv("HTTP_PORT", "8080"),
+		MetricsAddr:       getEnv("METRICS_ADDR", "127.0.0.1:9090"),
+
+		KeyRotationInterval: getEnvDuration("KEY_ROTATION_INTERVAL", 0),
+		KeyRefreshInterval:  getEnvDuration("KEY_REFRESH_INTERVAL", time.Minute),
+
+		AccessTokenTTL:   getEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		TokenIssuer:      getEnv("TOKEN_ISSUER", "auth-microservice"),
+		TokenAudience:    getEnv("TOKEN_AUDIENCE", "auth-microservice"),
+		AllowedAudiences: getEnvList("ALLOWED_AUDIENCES", nil),
+		TokenLeeway:      getEnvDuration("TOKEN_LEEWAY", 30*time.Second),
+		RefreshTokenTTL:  getEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+
+		ServiceClients: parseServiceClients(getEnv("SERVICE_CLIENTS", "")),
+
+		SessionTouchInterval: getEnvDuration("SESSION_TOUCH_INTERVAL", time.Minute),
+		TrustProxyHeaders:    getEnvBool("TRUST_PROXY_HEADERS", false),
+
+		LoginRateLimitIP:      getEnvRateLimit("LOGIN_RATE_LIMIT_IP", RateLimit{Limit: 30, Window: time.Minute}),
+		LoginRateLimitEmail:   getEnvRateLimit("LOGIN_RATE_LIMIT_EMAIL", RateLimit{Limit: 10, Window: 15 * time.Minute}),
+		LoginRateLimitIPEmail: getEnvRateLimit("LOGIN_RATE_LIMIT_IP_EMAIL", RateLimit{Limit: 5, Window: time.Minute}),
+
+		LockoutThreshold:    getEnvInt("LOCKOUT_THRESHOLD", 5),
+		LockoutBaseDuration: getEnvDuration("LOCKOUT_BASE_DURATION", time.Minute),
+		LockoutMaxDuration:  getEnvDuration("LOCKOUT_MAX_DURATION", 24*time.Hour),
+
+		MFAChallengeTTL: getEnvDuration("MFA_CHALLENGE_TTL", 5*time.Minute),
+		TOTPIssuer:      getEnv("TOTP_ISSUER", "auth-microservice"),
+
+		WebAuthnRPID:        getEnv("WEBAUTHN_RP_ID", "localhost"),
+		WebAuthnRPName:      getEnv("WEBAUTHN_RP_NAME", "Auth Microservice"),
+		WebAuthnOrigins:     getEnvList("WEBAUTHN_ORIGINS", []string{"http://localhost:3000"}),
+		WebAuthnCeremonyTTL: getEnvDuration("WEBAUTHN_CEREMONY_TTL", 5*time.Minute),
+
+		RequireVerifiedEmail: getEnvBool("REQUIRE_VERIFIED_EMAIL", false),
+		VerificationTokenTTL: getEnvDuration("VERIFICATION_TOKEN_TTL", 24*time.Hour),
+		PasswordResetTTL:     getEnvDuration("PASSWORD_RESET_TTL", time.Hour),
+		AppBaseURL:           strings.TrimRight(getEnv("APP_BASE_URL", "http://localhost:3000"), "/"),
+		EmailRateLimit:       getEnvRateLimit("EMAIL_RATE_LIMIT", RateLimit{Limit: 3, Window: 15 * time.Minute}),
+
+		EnumerationProtection: getEnvBool("ENUMERATION_PROTECTION", false),
+
+		MailDriver:   getEnv("MAIL_DRIVER", "log"),
+		MailFrom:     getEnv("MAIL_FROM", "no-reply@localhost"),
+		MailFileDir:  getEnv("MAIL_FILE_DIR", "./tmp/mail"),
+		SMTPAddr:     getEnv("SMTP_ADDR", ""),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+
+		PasswordMinLength:            getEnvInt("PASSWORD_MIN_LENGTH", 8),
+		PasswordMaxLength:            getEnvInt("PASSWORD_MAX_LENGTH", 72),
+		PasswordRequireUpper:         getEnvBool("PASSWORD_REQUIRE_UPPER", false),
+		PasswordRequireLower:         getEnvBool("PASSWORD_REQUIRE_LOWER", false),
+		PasswordRequireDigit:         getEnvBool("PASSWORD_REQUIRE_DIGIT", false),
+		PasswordRequireSymbol:        getEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+		PasswordDisallowPersonalInfo: getEnvBool("PASSWORD_DISALLOW_PERSONAL_INFO", true),
+		PasswordBlocklistFile:        getEnv("PASSWORD_BLOCKLIST_FILE", ""),
+		PasswordBlocklistFormat:      getEnv("PASSWORD_BLOCKLIST_FORMAT", "sha1"),
+
+		PasswordHashAlgorithm: getEnv("PASSWORD_HASH_ALGORITHM", "argon2id"),
+		Argon2Memory:          getEnvInt("ARGON2_MEMORY", 64*1024),
+		Argon2Time:            getEnvInt("ARGON2_TIME", 3),
+		Argon2Parallelism:     getEnvInt("ARGON2_PARALLELISM", 2),
+		BcryptCost:            getEnvInt("BCRYPT_COST", 10),
+
+		PasswordHashWorkers: getEnvInt("PASSWORD_HASH_WORKERS", 0),
+		PasswordHashQueue:   getEnvInt("PASSWORD_HASH_QUEUE", 128),
+		PasswordHashTimeout: getEnvDuration("PASSWORD_HASH_TIMEOUT", 5*time.Second),
+
+		RoleCacheTTL:  getEnvDuration("ROLE_CACHE_TTL", 30*time.Second),
+		GroupCacheTTL: getEnvDuration("GROUP_CACHE_TTL", 30*time.Second),
+
+		DefaultTenant: getEnv("DEFAULT_TENANT", "default"),
+
+		RevocationCacheTTL: getEnvDuration("REVOCATION_CACHE_TTL", 30*time.Second),
 	}
 }
 
@@ -26,3 +236,83 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if i, err := strconv.Atoi(value); err == nil {
+			return i
+		}
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
+
+// parseServiceClients - แปลง "id1:secret1,id2:secret2" เป็น map
+func parseServiceClients(value string) map[string]string {
+	clients := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		id, secret, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || id == "" || secret == "" {
+			continue
+		}
+		clients[id] = secret
+	}
+	return clients
+}
+
+// getEnvRateLimit - อ่านค่าในรูปแบบ "จำนวน/ช่วงเวลา" เช่น "5/1m"
+func getEnvRateLimit(key string, defaultValue RateLimit) RateLimit {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	countStr, windowStr, ok := strings.Cut(value, "/")
+	if !ok {
+		return defaultValue
+	}
+
+	limit, err := strconv.Atoi(countStr)
+	if err != nil || limit <= 0 {
+		return defaultValue
+	}
+
+	window, err := time.ParseDuration(windowStr)
+	if err != nil || window <= 0 {
+		return defaultValue
+	}
+
+	return RateLimit{Limit: limit, Window: window}
+}
@@ -0,0 +1,306 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"auth-microservice/pkg/db"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	// ErrGroupNotFound - ไม่พบ group ใน tenant นี้
+	ErrGroupNotFound = errors.New("group not found")
+	// ErrGroupExists - มี group ชื่อนี้ใน tenant แล้ว
+	ErrGroupExists = errors.New("group already exists")
+	// ErrAlreadyMember - user เป็นสมาชิกของ group อยู่แล้ว
+	ErrAlreadyMember = errors.New("user is already a member")
+	// ErrNotMember - user ไม่ได้เป็นสมาชิกของ group
+	ErrNotMember = errors.New("user is not a member")
+)
+
+// Group - กลุ่มของ user ภายใน tenant (collection groups)
+// สมาชิกได้รับสิทธิ์ของทุก role ใน Roles เพิ่มจาก role หลักของตัวเอง
+type Group struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	TenantID    string             `bson:"tenant_id"`
+	Name        string             `bson:"name"`
+	Description string             `bson:"description,omitempty"`
+	Roles       []string           `bson:"roles"`
+	Members     []string           `bson:"members"` // user IDs
+	CreatedAt   time.Time          `bson:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at"`
+}
+
+// Membership - groups ของ user และ roles ที่ได้จาก groups เหล่านั้น
+type Membership struct {
+	Groups []string // ชื่อ group เรียงตามตัวอักษร
+	Roles  []string // roles จากทุก group (ไม่ซ้ำ)
+}
+
+type cachedMembership struct {
+	membership Membership
+	expiresAt  time.Time
+}
+
+// Store - groups ใน MongoDB (ทุก method ระบุ tenant เสมอ) พร้อม cache membership ที่ใช้ตรวจสิทธิ์
+// การแก้ไขจาก replica อื่นมีผลภายใน cacheTTL
+type Store struct {
+	db       *db.MongoDB
+	cacheTTL time.Duration
+
+	mu        sync.RWMutex
+	cache     map[string]cachedMembership // key: tenant_id + "/" + user_id
+	lastSweep time.Time
+}
+
+func NewStore(database *db.MongoDB, cacheTTL time.Duration) *Store {
+	return &Store{
+		db:       database,
+		cacheTTL: cacheTTL,
+		cache:    make(map[string]cachedMembership),
+
+		lastSweep: time.Now(),
+	}
+}
+
+// Memberships - groups และ roles ที่ user ได้จาก groups (ใช้ใน AuthInterceptor และตอนออก token)
+func (s *Store) Memberships(ctx context.Context, tenantID, userID string) (Membership, error) {
+	key := tenantID + "/" + userID
+
+	s.mu.RLock()
+	cached, ok := s.cache[key]
+	s.mu.RUnlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.membership, nil
+	}
+
+	groups, err := s.ListByMember(ctx, tenantID, userID)
+	if err != nil {
+		return Membership{}, err
+	}
+
+	var membership Membership
+	seen := make(map[string]bool)
+	for _, g := range groups {
+		membership.Groups = append(membership.Groups, g.Name)
+		for _, role := range g.Roles {
+			if !seen[role] {
+				seen[role] = true
+				membership.Roles = append(membership.Roles, role)
+			}
+		}
+	}
+	sort.Strings(membership.Roles)
+
+	now := time.Now()
+	s.mu.Lock()
+	s.cache[key] = cachedMembership{membership: membership, expiresAt: now.Add(s.cacheTTL)}
+	// ล้าง entry ที่หมดอายุเป็นระยะ เพื่อไม่ให้ map โตไม่สิ้นสุด
+	if now.Sub(s.lastSweep) > time.Minute {
+		for k, e := range s.cache {
+			if now.After(e.expiresAt) {
+				delete(s.cache, k)
+			}
+		}
+		s.lastSweep = now
+	}
+	s.mu.Unlock()
+	return membership, nil
+}
+
+// List - groups ทั้งหมดของ tenant เรียงตามชื่อ
+func (s *Store) List(ctx context.Context, tenantID string) ([]Group, error) {
+	return s.find(ctx, bson.M{"tenant_id": tenantID})
+}
+
+// ListByMember - groups ที่ user เป็นสมาชิก เรียงตามชื่อ
+func (s *Store) ListByMember(ctx context.Context, tenantID, userID string) ([]Group, error) {
+	return s.find(ctx, bson.M{"tenant_id": tenantID, "members": userID})
+}
+
+// Get - ดึง group ตาม ID
+func (s *Store) Get(ctx context.Context, tenantID, id string) (*Group, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrGroupNotFound
+	}
+
+	var group Group
+	err = s.db.Groups().FindOne(ctx, bson.M{"_id": objectID, "tenant_id": tenantID}).Decode(&group)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrGroupNotFound
+		}
+		return nil, fmt.Errorf("failed to get group: %w", err)
+	}
+	return &group, nil
+}
+
+// Create - สร้าง group ใหม่ (ยังไม่มีสมาชิก)
+func (s *Store) Create(ctx context.Context, tenantID, name, description string, roles []string) (*Group, error) {
+	now := time.Now()
+	group := &Group{
+		ID:          primitive.NewObjectID(),
+		TenantID:    tenantID,
+		Name:        name,
+		Description: description,
+		Roles:       roles,
+		Members:     []string{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if _, err := s.db.Groups().InsertOne(ctx, group); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrGroupExists
+		}
+		return nil, fmt.Errorf("failed to create group: %w", err)
+	}
+	return group, nil
+}
+
+// Rename - เปลี่ยนชื่อ group
+func (s *Store) Rename(ctx context.Context, tenantID, id, name string) (*Group, error) {
+	group, err := s.update(ctx, tenantID, id, bson.M{"name": name})
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, ErrGroupExists
+	}
+	return group, err
+}
+
+// SetRoles - แทนที่ roles ทั้งหมดที่สมาชิกได้รับ
+func (s *Store) SetRoles(ctx context.Context, tenantID, id string, roles []string) (*Group, error) {
+	return s.update(ctx, tenantID, id, bson.M{"roles": roles})
+}
+
+// Delete - ลบ group (สมาชิกเสียสิทธิ์ที่ได้จาก group นี้)
+func (s *Store) Delete(ctx context.Context, tenantID, id string) (*Group, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrGroupNotFound
+	}
+
+	var group Group
+	err = s.db.Groups().FindOneAndDelete(ctx, bson.M{"_id": objectID, "tenant_id": tenantID}).Decode(&group)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrGroupNotFound
+		}
+		return nil, fmt.Errorf("failed to delete group: %w", err)
+	}
+
+	s.invalidateAll()
+	return &group, nil
+}
+
+// AddMember - เพิ่ม user เข้า group
+func (s *Store) AddMember(ctx context.Context, tenantID, id, userID string) error {
+	return s.changeMembers(ctx, tenantID, id, userID,
+		bson.M{"members": bson.M{"$ne": userID}},
+		bson.M{"$addToSet": bson.M{"members": userID}},
+		ErrAlreadyMember,
+	)
+}
+
+// RemoveMember - นำ user ออกจาก group
+func (s *Store) RemoveMember(ctx context.Context, tenantID, id, userID string) error {
+	return s.changeMembers(ctx, tenantID, id, userID,
+		bson.M{"members": userID},
+		bson.M{"$pull": bson.M{"members": userID}},
+		ErrNotMember,
+	)
+}
+
+// changeMembers - แก้สมาชิกแบบมีเงื่อนไข (ไม่ตรงเงื่อนไข = conflictErr ถ้ามี group อยู่)
+func (s *Store) changeMembers(ctx context.Context, tenantID, id, userID string, condition, change bson.M, conflictErr error) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrGroupNotFound
+	}
+
+	filter := bson.M{"_id": objectID, "tenant_id": tenantID}
+	for k, v := range condition {
+		filter[k] = v
+	}
+	change["$set"] = bson.M{"updated_at": time.Now()}
+
+	result, err := s.db.Groups().UpdateOne(ctx, filter, change)
+	if err != nil {
+		return fmt.Errorf("failed to update group members: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		if _, err := s.Get(ctx, tenantID, id); err != nil {
+			return err
+		}
+		return conflictErr
+	}
+
+	s.invalidate(tenantID, userID)
+	return nil
+}
+
+// update - $set fields ของ group แล้วคืน group หลังแก้ไข
+func (s *Store) update(ctx context.Context, tenantID, id string, fields bson.M) (*Group, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrGroupNotFound
+	}
+
+	fields["updated_at"] = time.Now()
+
+	var group Group
+	err = s.db.Groups().FindOneAndUpdate(ctx,
+		bson.M{"_id": objectID, "tenant_id": tenantID},
+		bson.M{"$set": fields},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&group)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrGroupNotFound
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update group: %w", err)
+	}
+
+	s.invalidateAll()
+	return &group, nil
+}
+
+func (s *Store) find(ctx context.Context, filter bson.M) ([]Group, error) {
+	cursor, err := s.db.Groups().Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list groups: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var groups []Group
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, fmt.Errorf("failed to decode groups: %w", err)
+	}
+	return groups, nil
+}
+
+// invalidate - ให้โหลด membership ของ user ใหม่ในครั้งถัดไป
+func (s *Store) invalidate(tenantID, userID string) {
+	s.mu.Lock()
+	delete(s.cache, tenantID+"/"+userID)
+	s.mu.Unlock()
+}
+
+// invalidateAll - ล้าง cache ทั้งหมด (เปลี่ยนชื่อ/roles หรือลบ group กระทบสมาชิกทุกคน)
+func (s *Store) invalidateAll() {
+	s.mu.Lock()
+	s.cache = make(map[string]cachedMembership)
+	s.mu.Unlock()
+}
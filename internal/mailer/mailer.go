@@ -0,0 +1,126 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message - email ที่จะส่ง (ข้อความธรรมดา)
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer - ช่องทางส่ง email (เปลี่ยนได้ตาม environment)
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Config - ค่าที่ใช้เลือกและตั้งค่า mailer
+type Config struct {
+	// Driver - log, file หรือ smtp
+	Driver string
+	// From - ผู้ส่ง
+	From string
+	// FileDir - โฟลเดอร์ที่เขียน email (driver file)
+	FileDir string
+	// SMTPAddr - host:port ของ SMTP server (driver smtp)
+	SMTPAddr     string
+	SMTPUsername string
+	SMTPPassword string
+}
+
+// New - สร้าง mailer ตาม driver ใน config
+func New(cfg Config) (Mailer, error) {
+	switch cfg.Driver {
+	case "", "log":
+		return &LogMailer{}, nil
+	case "file":
+		if err := os.MkdirAll(cfg.FileDir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create mail directory: %w", err)
+		}
+		return &FileMailer{dir: cfg.FileDir, from: cfg.From}, nil
+	case "smtp":
+		if cfg.SMTPAddr == "" {
+			return nil, fmt.Errorf("SMTP address is required")
+		}
+		return &SMTPMailer{cfg: cfg}, nil
+	default:
+		return nil, fmt.Errorf("unsupported mail driver: %q", cfg.Driver)
+	}
+}
+
+// LogMailer - เขียน email ลง log (สำหรับ local development)
+type LogMailer struct{}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	log.Printf("📧 [mail] To: %s | Subject: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// FileMailer - เขียน email แต่ละฉบับเป็นไฟล์ .eml (สำหรับ local development และ e2e test)
+type FileMailer struct {
+	dir  string
+	from string
+
+	mu  sync.Mutex
+	seq int
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	m.mu.Lock()
+	m.seq++
+	name := fmt.Sprintf("%s-%04d.eml", time.Now().Format("20060102T150405.000"), m.seq)
+	m.mu.Unlock()
+
+	path := filepath.Join(m.dir, name)
+	if err := os.WriteFile(path, format(m.from, msg), 0o600); err != nil {
+		return fmt.Errorf("failed to write mail: %w", err)
+	}
+	return nil
+}
+
+// SMTPMailer - ส่ง email ผ่าน SMTP server
+type SMTPMailer struct {
+	cfg Config
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	var auth smtp.Auth
+	if m.cfg.SMTPUsername != "" {
+		host, _, _ := strings.Cut(m.cfg.SMTPAddr, ":")
+		auth = smtp.PlainAuth("", m.cfg.SMTPUsername, m.cfg.SMTPPassword, host)
+	}
+
+	if err := smtp.SendMail(m.cfg.SMTPAddr, auth, m.cfg.From, []string{msg.To}, format(m.cfg.From, msg)); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
+
+// format - ข้อความ email ตาม RFC 5322
+func format(from string, msg Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", headerValue(from))
+	fmt.Fprintf(&b, "To: %s\r\n", headerValue(msg.To))
+	fmt.Fprintf(&b, "Subject: %s\r\n", headerValue(msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// headerValue - ตัดขึ้นบรรทัดใหม่ออก (ป้องกัน header injection จากข้อมูลของ user)
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
@@ -0,0 +1,40 @@
+package mfa
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// RecoveryCodeCount - จำนวน recovery codes ที่ออกให้ตอนเปิดใช้ MFA
+const RecoveryCodeCount = 10
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateRecoveryCodes - สร้าง recovery codes แบบใช้ครั้งเดียว (รูปแบบ xxxxx-xxxxx)
+// คืนค่า codes สำหรับแสดงให้ user ครั้งเดียว และ hashes สำหรับเก็บในฐานข้อมูล
+func GenerateRecoveryCodes() (codes []string, hashes []string, err error) {
+	for i := 0; i < RecoveryCodeCount; i++ {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+
+		raw := strings.ToLower(recoveryEncoding.EncodeToString(b))[:10]
+		code := raw[:5] + "-" + raw[5:]
+
+		codes = append(codes, code)
+		hashes = append(hashes, HashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode - hash ของ recovery code (ไม่สนตัวพิมพ์เล็ก/ใหญ่ ช่องว่าง และขีด)
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,63 @@
+package mfa
+
+import (
+	"regexp"
+	"testing"
+)
+
+// recoveryCodePattern - รูปแบบที่แสดงให้ user (base32 ตัวพิมพ์เล็ก 5-5)
+var recoveryCodePattern = regexp.MustCompile(`^[a-z2-7]{5}-[a-z2-7]{5}$`)
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, hashes, err := GenerateRecoveryCodes()
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodes: %v", err)
+	}
+	if len(codes) != RecoveryCodeCount || len(hashes) != RecoveryCodeCount {
+		t.Fatalf("got %d codes and %d hashes, want %d each", len(codes), len(hashes), RecoveryCodeCount)
+	}
+
+	seen := make(map[string]bool, len(codes))
+	for i, code := range codes {
+		if !recoveryCodePattern.MatchString(code) {
+			t.Errorf("code %q does not match %s", code, recoveryCodePattern)
+		}
+		if seen[code] {
+			t.Errorf("code %q generated twice", code)
+		}
+		seen[code] = true
+
+		if hashes[i] != HashRecoveryCode(code) {
+			t.Errorf("hash %d does not match HashRecoveryCode(%q)", i, code)
+		}
+	}
+}
+
+func TestHashRecoveryCodeNormalization(t *testing.T) {
+	want := HashRecoveryCode("abcde-fghij")
+
+	same := []string{
+		"abcde-fghij",
+		"ABCDE-FGHIJ",
+		"abcdefghij",
+		"abcde fghij",
+		" abcde-fghij ",
+		"ab-cde-fg hij",
+	}
+	for _, code := range same {
+		if got := HashRecoveryCode(code); got != want {
+			t.Errorf("HashRecoveryCode(%q) differs from HashRecoveryCode(%q)", code, "abcde-fghij")
+		}
+	}
+
+	different := []string{
+		"abcde-fghik",
+		"abcde-fghi",
+		"",
+	}
+	for _, code := range different {
+		if got := HashRecoveryCode(code); got == want {
+			t.Errorf("HashRecoveryCode(%q) equals HashRecoveryCode(%q)", code, "abcde-fghij")
+		}
+	}
+}
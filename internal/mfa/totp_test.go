@@ -0,0 +1,174 @@
+package mfa
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret - shared secret ของ test vectors ใน RFC 4226 และ RFC 6238 (SHA-1) ในรูป base32
+// ค่าจริงคือ ASCII "12345678901234567890"
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerateCodeRFC4226(t *testing.T) {
+	// RFC 4226 Appendix D - HOTP ของ counter 0-9
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+
+	key := []byte("12345678901234567890")
+	for counter, code := range want {
+		if got := generateCode(key, int64(counter)); got != code {
+			t.Errorf("generateCode(counter=%d) = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+func TestValidateRFC6238(t *testing.T) {
+	// RFC 6238 Appendix B (SHA-1) - code 8 หลัก ใช้ 6 หลักท้ายเพราะ Digits = 6
+	tests := []struct {
+		unix int64
+		step int64
+		code string
+	}{
+		{59, 0x1, "94287082"},
+		{1111111109, 0x23523EC, "07081804"},
+		{1111111111, 0x23523ED, "14050471"},
+		{1234567890, 0x273EF07, "89005924"},
+		{2000000000, 0x3F940AA, "69279037"},
+		{20000000000, 0x27BC86AA, "65353130"},
+	}
+
+	for _, tt := range tests {
+		code := tt.code[len(tt.code)-Digits:]
+		step, ok := Validate(rfcSecret, code, time.Unix(tt.unix, 0))
+		if !ok || step != tt.step {
+			t.Errorf("Validate(%s at %d) = (%#x, %t), want (%#x, true)", code, tt.unix, step, ok, tt.step)
+		}
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	key := []byte("12345678901234567890")
+	now := time.Unix(1111111111, 0)
+	current := now.Unix() / int64(Period.Seconds())
+
+	tests := []struct {
+		offset int64
+		valid  bool
+	}{
+		{-2, false},
+		{-1, true},
+		{0, true},
+		{1, true},
+		{2, false},
+	}
+
+	for _, tt := range tests {
+		code := generateCode(key, current+tt.offset)
+		step, ok := Validate(rfcSecret, code, now)
+		if ok != tt.valid {
+			t.Errorf("code of step %+d: valid = %t, want %t", tt.offset, ok, tt.valid)
+		}
+		if ok && step != current+tt.offset {
+			t.Errorf("code of step %+d: matched step %d, want %d", tt.offset, step, current+tt.offset)
+		}
+	}
+}
+
+func TestValidateReplay(t *testing.T) {
+	// Validate ไม่มี state - คืน step เดิมเมื่อใช้ code ซ้ำ เพื่อให้ผู้เรียกปฏิเสธ step ที่ไม่ใหม่กว่าที่เก็บไว้
+	now := time.Unix(1111111111, 0)
+	code := generateCode([]byte("12345678901234567890"), now.Unix()/int64(Period.Seconds()))
+
+	first, ok := Validate(rfcSecret, code, now)
+	if !ok {
+		t.Fatalf("Validate(%s) rejected a current code", code)
+	}
+
+	// ใช้ซ้ำภายในช่วง skew - ยังตรงกับ step เดิม
+	for _, later := range []time.Duration{time.Second, Period} {
+		again, ok := Validate(rfcSecret, code, now.Add(later))
+		if !ok || again != first {
+			t.Errorf("replay after %s = (%d, %t), want step %d so the caller can reject it", later, again, ok, first)
+		}
+	}
+
+	// พ้นช่วง skew - ใช้ไม่ได้อีก
+	if _, ok := Validate(rfcSecret, code, now.Add(time.Duration(Skew+1)*Period)); ok {
+		t.Error("code accepted after the skew window")
+	}
+}
+
+func TestValidateInput(t *testing.T) {
+	now := time.Unix(59, 0)
+
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+		valid  bool
+	}{
+		{"exact", rfcSecret, "287082", true},
+		{"surrounding spaces", rfcSecret, " 287082 ", true},
+		{"lowercase secret", strings.ToLower(rfcSecret), "287082", true},
+		{"wrong code", rfcSecret, "287083", false},
+		{"too short", rfcSecret, "28708", false},
+		{"too long", rfcSecret, "2870820", false},
+		{"empty", rfcSecret, "", false},
+		{"invalid secret", "not base32!", "287082", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := Validate(tt.secret, tt.code, now); ok != tt.valid {
+				t.Errorf("Validate(%q, %q) = %t, want %t", tt.secret, tt.code, ok, tt.valid)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+
+	key, err := secretEncoding.DecodeString(secret)
+	if err != nil || len(key) != secretSize {
+		t.Fatalf("secret %q decodes to %d bytes (%v), want %d", secret, len(key), err, secretSize)
+	}
+
+	other, _ := GenerateSecret()
+	if other == secret {
+		t.Error("two generated secrets are equal")
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("Example Corp", "alice@example.com", rfcSecret)
+
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("url.Parse(%q): %v", uri, err)
+	}
+	if parsed.Scheme != "otpauth" || parsed.Host != "totp" || parsed.Path != "/Example Corp:alice@example.com" {
+		t.Errorf("URI = %q, want otpauth://totp/Example Corp:alice@example.com", uri)
+	}
+	if strings.Contains(uri, "+") {
+		t.Errorf("URI %q encodes spaces as +", uri)
+	}
+
+	query := parsed.Query()
+	want := map[string]string{
+		"secret":    rfcSecret,
+		"issuer":    "Example Corp",
+		"algorithm": "SHA1",
+		"digits":    "6",
+		"period":    "30",
+	}
+	for key, value := range want {
+		if got := query.Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+}
@@ -5,6 +5,10 @@ import (
 	"log"
 	"strings"
 
+	"auth-microservice/internal/group"
+	"auth-microservice/internal/rbac"
+	"auth-microservice/internal/revocation"
+	"auth-microservice/internal/session"
 	"auth-microservice/pkg/jwt"
 
 	"google.golang.org/grpc"
@@ -13,21 +17,27 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-// AuthInterceptor - Middleware สำหรับตรวจสอบ JWT
-func AuthInterceptor(jwtService *jwt.JWTService) grpc.UnaryServerInterceptor {
+// amrMFA - ค่า amr ของ access token ที่ได้จากการยืนยันตัวตนหลายขั้น
+const amrMFA = "mfa"
+
+// AuthInterceptor - Middleware สำหรับตรวจสอบ JWT และเงื่อนไขของ method ตาม option (authz.authz) ใน proto
+// method ที่ไม่มี annotation ถูกปฏิเสธเสมอ
+// สิทธิ์ของผู้เรียก = สิทธิ์ของ role หลัก รวมกับสิทธิ์ของ roles จาก groups ที่เป็นสมาชิก
+// serviceClients คือ client ID → secret ของ service อื่นที่เรียก service methods (เช่น Introspect) ได้
+func AuthInterceptor(jwtService *jwt.JWTService, revocations *revocation.Store, sessions *session.Store, roles *rbac.Store, groups *group.Store, rules *MethodRules, serviceClients map[string]string) grpc.UnaryServerInterceptor {
 	return func(
 		ctx context.Context,
 		req interface{},
 		info *grpc.UnaryServerInfo,
 		handler grpc.UnaryHandler,
 	) (interface{}, error) {
-		// ข้าม auth สำหรับ APIs เหล่านี้
-		publicMethods := map[string]bool{
-			"/auth.AuthService/Login":    true,
-			"/auth.AuthService/Register": true,
+		rule, ok := rules.Lookup(info.FullMethod)
+		if !ok {
+			log.Printf("❌ No authz rule for method: %s", info.FullMethod)
+			return nil, status.Errorf(codes.PermissionDenied, "Permission denied")
 		}
 
-		if publicMethods[info.FullMethod] {
+		if rule.Public {
 			log.Printf("🟢 Public method accessed: %s", info.FullMethod)
 			return handler(ctx, req)
 		}
@@ -39,6 +49,18 @@ func AuthInterceptor(jwtService *jwt.JWTService) grpc.UnaryServerInterceptor {
 			return nil, status.Errorf(codes.Unauthenticated, "Missing metadata")
 		}
 
+		if rule.Service {
+			clientID, ok := authenticateServiceClient(md, serviceClients)
+			if !ok {
+				log.Printf("❌ Invalid client credentials for method: %s", info.FullMethod)
+				return nil, status.Errorf(codes.Unauthenticated, "Invalid client credentials")
+			}
+
+			log.Printf("🟢 Authenticated service client: %s for method: %s", clientID, info.FullMethod)
+			ctx = context.WithValue(ctx, serviceClientKey, clientID)
+			return handler(ctx, req)
+		}
+
 		authHeader := md.Get("authorization")
 		if len(authHeader) == 0 {
 			log.Printf("❌ No authorization header for method: %s", info.FullMethod)
@@ -59,10 +81,81 @@ func AuthInterceptor(jwtService *jwt.JWTService) grpc.UnaryServerInterceptor {
 			return nil, status.Errorf(codes.Unauthenticated, "Invalid token")
 		}
 
-		// เพิ่ม user info ใน context
-		ctx = context.WithValue(ctx, "user_id", claims.UserID)
-		ctx = context.WithValue(ctx, "user_email", claims.Email)
-		ctx = context.WithValue(ctx, "user_role", claims.Role)
+		// token ที่ออกก่อนรองรับ tenant ใช้ไม่ได้ (refresh เพื่อรับ token ที่มี tenant_id)
+		if claims.TenantID == "" {
+			log.Printf("❌ Token without tenant used for method: %s", info.FullMethod)
+			return nil, status.Errorf(codes.Unauthenticated, "Token has no tenant")
+		}
+
+		// ตรวจสอบว่า token ถูก logout ไปแล้วหรือไม่
+		revoked, err := revocations.IsRevoked(ctx, token)
+		if err != nil {
+			log.Printf("❌ Revocation check failed for method: %s - Error: %v", info.FullMethod, err)
+			return nil, status.Errorf(codes.Unavailable, "Unable to verify token")
+		}
+		if revoked {
+			log.Printf("❌ Revoked token used for method: %s", info.FullMethod)
+			return nil, status.Errorf(codes.Unauthenticated, "Token has been revoked")
+		}
+
+		// ตรวจสอบ token epoch ของ user (revoke ทุก session / user ถูกปิดใช้งาน)
+		userRevoked, err := revocations.IsUserRevoked(ctx, claims.UserID, claims.TokenVersion)
+		if err != nil {
+			log.Printf("❌ User status check failed for method: %s - Error: %v", info.FullMethod, err)
+			return nil, status.Errorf(codes.Unavailable, "Unable to verify token")
+		}
+		if userRevoked {
+			log.Printf("❌ Token of revoked user session used for method: %s", info.FullMethod)
+			return nil, status.Errorf(codes.Unauthenticated, "Token has been revoked")
+		}
+
+		// ตรวจสอบ session (ถูกยกเลิกจาก RevokeSession หรือไม่) และอัพเดท last seen
+		if claims.SessionID != "" {
+			sessionRevoked, err := revocations.IsSessionRevoked(ctx, claims.SessionID)
+			if err != nil {
+				log.Printf("❌ Session check failed for method: %s - Error: %v", info.FullMethod, err)
+				return nil, status.Errorf(codes.Unavailable, "Unable to verify token")
+			}
+			if sessionRevoked {
+				log.Printf("❌ Token of revoked session used for method: %s", info.FullMethod)
+				return nil, status.Errorf(codes.Unauthenticated, "Session has been revoked")
+			}
+			sessions.Touch(claims.SessionID)
+		}
+
+		// ตรวจสอบสิทธิ์ของ role และ groups (ต้องมีทุกสิทธิ์ที่ method กำหนด)
+		membership, err := groups.Memberships(ctx, claims.TenantID, claims.UserID)
+		if err != nil {
+			log.Printf("❌ Group lookup failed for method: %s - Error: %v", info.FullMethod, err)
+			return nil, status.Errorf(codes.Unavailable, "Unable to verify permissions")
+		}
+		permissions, err := roles.PermissionsOf(ctx, append([]string{claims.Role}, membership.Roles...)...)
+		if err != nil {
+			log.Printf("❌ Permission lookup failed for method: %s - Error: %v", info.FullMethod, err)
+			return nil, status.Errorf(codes.Unavailable, "Unable to verify permissions")
+		}
+		for _, required := range rule.Permissions {
+			if !permissions.Has(required) {
+				log.Printf("❌ Permission denied - %s (%s) lacks %s for method: %s", claims.Email, claims.Role, required, info.FullMethod)
+				return nil, status.Errorf(codes.PermissionDenied, "Permission denied: %s required", required)
+			}
+		}
+
+		if rule.Mfa && !claims.HasAMR(amrMFA) {
+			log.Printf("❌ MFA required - %s for method: %s", claims.Email, info.FullMethod)
+			return nil, status.Errorf(codes.PermissionDenied, "Multi-factor authentication required")
+		}
+
+		// เพิ่มข้อมูลผู้เรียกใน context
+		ctx = WithPrincipal(ctx, &Principal{
+			UserID:      claims.UserID,
+			TenantID:    claims.TenantID,
+			Email:       claims.Email,
+			Role:        claims.Role,
+			SessionID:   claims.SessionID,
+			Groups:      membership.Groups,
+			Permissions: permissions,
+		})
 
 		log.Printf("🟢 Authenticated user: %s (%s) for method: %s", claims.Email, claims.Role, info.FullMethod)
 
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"auth-microservice/internal/rbac"
+	"auth-microservice/proto/authz"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// MethodRules - เงื่อนไขการเรียกของแต่ละ gRPC method จาก option (authz.authz) ใน proto
+// สร้างก่อน server แล้วเรียก Load หลังลงทะเบียน services (ก่อน Serve)
+type MethodRules struct {
+	mu    sync.RWMutex
+	rules map[string]*authz.Rule
+}
+
+func NewMethodRules() *MethodRules {
+	return &MethodRules{
+		rules: make(map[string]*authz.Rule),
+	}
+}
+
+// Load - อ่าน rules จาก descriptors ของ services ที่ลงทะเบียนกับ server
+// คืนรายชื่อ unary method ที่ไม่มี annotation (method เหล่านี้ถูกปฏิเสธเสมอ)
+func (r *MethodRules) Load(services map[string]grpc.ServiceInfo) ([]string, error) {
+	rules := make(map[string]*authz.Rule)
+	var unannotated []string
+
+	for serviceName, info := range services {
+		descriptor, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(serviceName))
+		if err != nil {
+			return nil, fmt.Errorf("descriptor of service %s not found: %w", serviceName, err)
+		}
+		service, ok := descriptor.(protoreflect.ServiceDescriptor)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a service", serviceName)
+		}
+
+		for _, method := range info.Methods {
+			// stream methods ไม่ผ่าน unary interceptor (เช่น gRPC reflection)
+			if method.IsClientStream || method.IsServerStream {
+				continue
+			}
+
+			fullMethod := "/" + serviceName + "/" + method.Name
+			methodDescriptor := service.Methods().ByName(protoreflect.Name(method.Name))
+			if methodDescriptor == nil {
+				return nil, fmt.Errorf("descriptor of method %s not found", fullMethod)
+			}
+
+			options, _ := methodDescriptor.Options().(*descriptorpb.MethodOptions)
+			if options == nil || !proto.HasExtension(options, authz.E_Authz) {
+				unannotated = append(unannotated, fullMethod)
+				continue
+			}
+
+			rule := proto.GetExtension(options, authz.E_Authz).(*authz.Rule)
+			if err := validateRule(rule); err != nil {
+				return nil, fmt.Errorf("invalid authz option on %s: %w", fullMethod, err)
+			}
+			rules[fullMethod] = rule
+		}
+	}
+
+	r.mu.Lock()
+	r.rules = rules
+	r.mu.Unlock()
+
+	sort.Strings(unannotated)
+	return unannotated, nil
+}
+
+// Lookup - rule ของ method (ok = false ถ้าไม่มี annotation)
+func (r *MethodRules) Lookup(fullMethod string) (*authz.Rule, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rule, ok := r.rules[fullMethod]
+	return rule, ok
+}
+
+// validateRule - public/service ใช้ร่วมกับเงื่อนไขอื่นไม่ได้ และสิทธิ์ต้องเป็นสิทธิ์ที่รู้จัก
+func validateRule(rule *authz.Rule) error {
+	if rule.Public && rule.Service {
+		return fmt.Errorf("public and service are mutually exclusive")
+	}
+	if (rule.Public || rule.Service) && (len(rule.Permissions) > 0 || rule.Mfa) {
+		return fmt.Errorf("permissions and mfa apply to user tokens only")
+	}
+	for _, permission := range rule.Permissions {
+		if permission == rbac.PermAll || !rbac.IsKnownPermission(permission) {
+			return fmt.Errorf("unknown permission %q", permission)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// ClientInfo - ข้อมูลของ client ที่เรียก API
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// ClientInfoInterceptor - Middleware สำหรับเก็บ IP และ user agent ของ client ไว้ใน context
+// trustProxyHeaders = true เมื่อ service อยู่หลัง load balancer ที่ตั้ง x-forwarded-for ให้
+func ClientInfoInterceptor(trustProxyHeaders bool) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		ctx = context.WithValue(ctx, clientInfoKey, extractClientInfo(ctx, trustProxyHeaders))
+		return handler(ctx, req)
+	}
+}
+
+// ClientInfoFromContext - ดึงข้อมูล client ที่ ClientInfoInterceptor ใส่ไว้
+func ClientInfoFromContext(ctx context.Context) ClientInfo {
+	info, _ := ctx.Value(clientInfoKey).(ClientInfo)
+	return info
+}
+
+func extractClientInfo(ctx context.Context, trustProxyHeaders bool) ClientInfo {
+	var info ClientInfo
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	if ua := md.Get("user-agent"); len(ua) > 0 {
+		info.UserAgent = ua[0]
+	}
+
+	if trustProxyHeaders {
+		// ใช้ IP แรกใน x-forwarded-for (client จริง)
+		if forwarded := md.Get("x-forwarded-for"); len(forwarded) > 0 {
+			first, _, _ := strings.Cut(forwarded[0], ",")
+			info.IP = strings.TrimSpace(first)
+		} else if realIP := md.Get("x-real-ip"); len(realIP) > 0 {
+			info.IP = strings.TrimSpace(realIP[0])
+		}
+	}
+
+	if info.IP == "" {
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			host, _, err := net.SplitHostPort(p.Addr.String())
+			if err != nil {
+				host = p.Addr.String()
+			}
+			info.IP = host
+		}
+	}
+
+	return info
+}
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"context"
+
+	"auth-microservice/internal/rbac"
+)
+
+// contextKey - type ของ context keys ใน package นี้ (ไม่ชนกับ key ของ package อื่น)
+type contextKey int
+
+const (
+	principalKey contextKey = iota
+	serviceClientKey
+	clientInfoKey
+	tenantKey
+)
+
+// Principal - ผู้เรียกที่ผ่านการยืนยันตัวตนด้วย access token แล้ว (AuthInterceptor ใส่ไว้ใน context)
+type Principal struct {
+	UserID      string
+	TenantID    string
+	Email       string
+	Role        string
+	SessionID   string
+	Groups      []string           // ชื่อ groups ที่เป็นสมาชิกตอนเรียก
+	Permissions rbac.PermissionSet // สิทธิ์ของ role และ groups ตอนเรียก
+}
+
+// Can - ตรวจสอบว่ามีสิทธิ์นี้หรือไม่
+func (p *Principal) Can(permission string) bool {
+	return p != nil && p.Permissions.Has(permission)
+}
+
+// IsSelf - ตรวจสอบว่า userID เป็นของผู้เรียกเอง
+func (p *Principal) IsSelf(userID string) bool {
+	return p != nil && userID != "" && p.UserID == userID
+}
+
+// CanActOn - ผู้เรียกกระทำกับ userID ได้หรือไม่ (เป็นเจ้าของบัญชี หรือมีสิทธิ์ permission)
+func (p *Principal) CanActOn(userID, permission string) bool {
+	return p.IsSelf(userID) || p.Can(permission)
+}
+
+// WithPrincipal - ใส่ Principal ลงใน context
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+// PrincipalFromContext - ดึง Principal ของผู้เรียก (ok = false ถ้าไม่ได้ login)
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey).(*Principal)
+	return principal, ok && principal != nil && principal.UserID != ""
+}
+
+// UserIDFromContext - ดึง user ID ของผู้เรียก (ok = false ถ้าไม่ได้ login)
+func UserIDFromContext(ctx context.Context) (string, bool) {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	return principal.UserID, true
+}
+
+// RoleFromContext - ดึง role ของผู้เรียก
+func RoleFromContext(ctx context.Context) string {
+	principal, _ := PrincipalFromContext(ctx)
+	if principal == nil {
+		return ""
+	}
+	return principal.Role
+}
+
+// HasPermission - ตรวจสอบว่าผู้เรียกมีสิทธิ์นี้หรือไม่ (จาก role หรือ groups)
+func HasPermission(ctx context.Context, permission string) bool {
+	principal, _ := PrincipalFromContext(ctx)
+	return principal.Can(permission)
+}
+
+// SessionIDFromContext - ดึง session ID ของ token ที่ใช้เรียก API
+func SessionIDFromContext(ctx context.Context) (string, bool) {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	return principal.SessionID, principal.SessionID != ""
+}
+
+// WithTenant - กำหนด tenant ของ request (สำหรับ method ที่ไม่ต้อง login เช่น Login, Register
+// หรือ flow ที่รู้ tenant จาก token ที่ออกไปก่อนหน้า) - user.Repository ใช้ค่านี้ scope ทุก query
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey, tenantID)
+}
+
+// TenantIDFromContext - tenant ของ request (ที่กำหนดด้วย WithTenant หรือ tenant ของผู้เรียกที่ login แล้ว)
+func TenantIDFromContext(ctx context.Context) (string, bool) {
+	if tenantID, ok := ctx.Value(tenantKey).(string); ok && tenantID != "" {
+		return tenantID, true
+	}
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok || principal.TenantID == "" {
+		return "", false
+	}
+	return principal.TenantID, true
+}
+
+// ServiceClientFromContext - ดึง client ID ของ service ที่เรียก (เฉพาะ service methods)
+func ServiceClientFromContext(ctx context.Context) (string, bool) {
+	clientID, ok := ctx.Value(serviceClientKey).(string)
+	return clientID, ok && clientID != ""
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// authenticateServiceClient - ตรวจสอบ client credentials ของ service อื่น
+// (authorization: Basic base64(client_id:client_secret) ตาม RFC 7662)
+func authenticateServiceClient(md metadata.MD, clients map[string]string) (string, bool) {
+	authHeader := md.Get("authorization")
+	if len(authHeader) == 0 {
+		return "", false
+	}
+
+	encoded := strings.TrimPrefix(authHeader[0], "Basic ")
+	if encoded == authHeader[0] {
+		return "", false
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", false
+	}
+
+	clientID, secret, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return "", false
+	}
+
+	expected, found := clients[clientID]
+	if !found {
+		return "", false
+	}
+
+	// เทียบ hash เพื่อไม่ให้เวลาในการเทียบขึ้นกับความยาวของ secret
+	got := sha256.Sum256([]byte(secret))
+	want := sha256.Sum256([]byte(expected))
+	if subtle.ConstantTimeCompare(got[:], want[:]) != 1 {
+		return "", false
+	}
+
+	return clientID, true
+}
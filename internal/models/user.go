@@ -1,14 +1,17 @@
 package models
 
 import (
+	"context"
 	"time"
 
+	"auth-microservice/internal/password"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type User struct {
 	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TenantID     string             `bson:"tenant_id" json:"tenant_id"`
 	Email        string             `bson:"email" json:"email"`
 	PasswordHash string             `bson:"password_hash" json:"-"`
 	FirstName    string             `bson:"first_name" json:"first_name"`
@@ -18,22 +21,46 @@ type User struct {
 	UpdatedAt    time.Time          `bson:"updated_at" json:"updated_at"`
 	IsActive     bool               `bson:"is_active" json:"is_active"`
 	DeletedAt    *time.Time         `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	TokenVersion int64              `bson:"token_version" json:"-"` // เพิ่มค่าเพื่อ revoke ทุก session
+
+	// ระงับบัญชีโดย admin (AdminService.DeactivateUser)
+	DeactivatedAt      *time.Time `bson:"deactivated_at,omitempty" json:"deactivated_at,omitempty"`
+	DeactivatedBy      string     `bson:"deactivated_by,omitempty" json:"-"`
+	DeactivationReason string     `bson:"deactivation_reason,omitempty" json:"-"`
+
+	EmailVerified   bool       `bson:"email_verified" json:"email_verified"`
+	EmailVerifiedAt *time.Time `bson:"email_verified_at,omitempty" json:"email_verified_at,omitempty"`
+
+	FailedLoginAttempts int        `bson:"failed_login_attempts" json:"-"`                       // login ผิดติดต่อกัน
+	LockedUntil         *time.Time `bson:"locked_until,omitempty" json:"locked_until,omitempty"` // ล็อคชั่วคราวถึงเวลานี้
+
+	// TOTP MFA
+	TOTPEnabled       bool     `bson:"totp_enabled" json:"totp_enabled"`
+	TOTPSecret        string   `bson:"totp_secret,omitempty" json:"-"`
+	TOTPPendingSecret string   `bson:"totp_pending_secret,omitempty" json:"-"` // รอ ConfirmTOTP
+	TOTPLastStep      int64    `bson:"totp_last_step,omitempty" json:"-"`      // ป้องกันการใช้ code ซ้ำ
+	RecoveryCodes     []string `bson:"recovery_codes,omitempty" json:"-"`      // SHA-256 ของ recovery codes ที่ยังไม่ได้ใช้
 }
 
-// HashPassword - เข้ารหัสรหัสผ่าน
-func (u *User) HashPassword(password string) error {
-	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+// HashPassword - เข้ารหัสรหัสผ่านด้วย algorithm ปัจจุบัน (ผ่าน hashing pool)
+func (u *User) HashPassword(ctx context.Context, pool *password.Pool, plain string) error {
+	hashed, err := pool.Hash(ctx, plain)
 	if err != nil {
 		return err
 	}
-	u.PasswordHash = string(hashedBytes)
+	u.PasswordHash = hashed
 	return nil
 }
 
-// CheckPassword - ตรวจสอบรหัสผ่าน
-func (u *User) CheckPassword(password string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password))
-	return err == nil
+// CheckPassword - ตรวจสอบรหัสผ่าน (รองรับ hash ของ algorithm เก่า)
+// error = ตรวจไม่ได้ (เช่น pool เต็ม) ไม่ใช่รหัสผ่านผิด
+func (u *User) CheckPassword(ctx context.Context, pool *password.Pool, plain string) (bool, error) {
+	return pool.Verify(ctx, u.PasswordHash, plain)
+}
+
+// IsLocked - ตรวจสอบว่าบัญชีถูกล็อคชั่วคราวอยู่หรือไม่
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
 }
 
 // IsValidRole - ตรวจสอบ role ที่ถูกต้อง
@@ -44,13 +71,16 @@ func (u *User) IsValidRole() bool {
 // ToSafeUser - แปลงเป็น user object ที่ปลอดภัย (ไม่มี password)
 func (u *User) ToSafeUser() map[string]interface{} {
 	return map[string]interface{}{
-		"id":         u.ID.Hex(),
-		"email":      u.Email,
-		"first_name": u.FirstName,
-		"last_name":  u.LastName,
-		"role":       u.Role,
-		"is_active":  u.IsActive,
-		"created_at": u.CreatedAt,
-		"updated_at": u.UpdatedAt,
+		"id":             u.ID.Hex(),
+		"tenant_id":      u.TenantID,
+		"email":          u.Email,
+		"first_name":     u.FirstName,
+		"last_name":      u.LastName,
+		"role":           u.Role,
+		"is_active":      u.IsActive,
+		"email_verified": u.EmailVerified,
+		"mfa_enabled":    u.TOTPEnabled,
+		"created_at":     u.CreatedAt,
+		"updated_at":     u.UpdatedAt,
 	}
 }
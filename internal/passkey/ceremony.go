@@ -0,0 +1,109 @@
+package passkey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"auth-microservice/pkg/db"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrCeremonyNotFound - ceremony ไม่มีอยู่ หมดอายุ หรือถูกใช้ไปแล้ว
+var ErrCeremonyNotFound = errors.New("ceremony not found")
+
+// ชนิดของ ceremony
+const (
+	CeremonyRegistration = "registration"
+	CeremonyLogin        = "login"
+)
+
+// Ceremony - challenge ที่รอ client ตอบกลับ (ใช้ได้ครั้งเดียว เก็บใน MongoDB เพื่อใช้ร่วมกันทุก replica)
+type Ceremony struct {
+	ID        string    `bson:"_id"` // SHA-256 ของ ceremony ID ที่ส่งให้ client
+	Type      string    `bson:"type"`
+	UserID    string    `bson:"user_id,omitempty"`   // ว่าง = passwordless (discoverable credential)
+	TenantID  string    `bson:"tenant_id,omitempty"` // tenant ที่ user ต้องอยู่ (passwordless)
+	Name      string    `bson:"name,omitempty"`      // ชื่อ passkey (registration)
+	Audience  string    `bson:"audience,omitempty"`
+	Session   []byte    `bson:"session"` // webauthn.SessionData (JSON)
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+// SessionData - ข้อมูล challenge ของ library
+func (c *Ceremony) SessionData() (webauthn.SessionData, error) {
+	var data webauthn.SessionData
+	if err := json.Unmarshal(c.Session, &data); err != nil {
+		return data, fmt.Errorf("failed to decode ceremony: %w", err)
+	}
+	return data, nil
+}
+
+// CeremonyStore - ceremonies ใน collection webauthn_ceremonies
+type CeremonyStore struct {
+	db  *db.MongoDB
+	ttl time.Duration
+}
+
+func NewCeremonyStore(database *db.MongoDB, ttl time.Duration) *CeremonyStore {
+	return &CeremonyStore{
+		db:  database,
+		ttl: ttl,
+	}
+}
+
+// Save - เก็บ ceremony และคืน ID สำหรับส่งให้ client
+func (s *CeremonyStore) Save(ctx context.Context, ceremony *Ceremony, data *webauthn.SessionData) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate ceremony ID: %w", err)
+	}
+	id := base64.RawURLEncoding.EncodeToString(b)
+
+	session, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode ceremony: %w", err)
+	}
+
+	ceremony.ID = hashCeremonyID(id)
+	ceremony.Session = session
+	ceremony.ExpiresAt = time.Now().Add(s.ttl)
+
+	if _, err := s.db.WebAuthnCeremonies().InsertOne(ctx, ceremony); err != nil {
+		return "", fmt.Errorf("failed to store ceremony: %w", err)
+	}
+	return id, nil
+}
+
+// Consume - ดึงและลบ ceremony (atomic) ตรวจสอบชนิดและวันหมดอายุ
+func (s *CeremonyStore) Consume(ctx context.Context, id, ceremonyType string) (*Ceremony, error) {
+	filter := bson.M{
+		"_id":        hashCeremonyID(id),
+		"type":       ceremonyType,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}
+
+	var ceremony Ceremony
+	err := s.db.WebAuthnCeremonies().FindOneAndDelete(ctx, filter).Decode(&ceremony)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrCeremonyNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &ceremony, nil
+}
+
+func hashCeremonyID(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
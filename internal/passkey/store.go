@@ -0,0 +1,158 @@
+package passkey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"auth-microservice/pkg/db"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrNotFound - ไม่พบ credential
+var ErrNotFound = errors.New("credential not found")
+
+// Credential - WebAuthn credential (passkey) ที่ลงทะเบียนไว้ของ user
+type Credential struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty"`
+	UserID          string             `bson:"user_id"`
+	Name            string             `bson:"name"`
+	CredentialID    []byte             `bson:"credential_id"`
+	PublicKey       []byte             `bson:"public_key"`
+	AttestationType string             `bson:"attestation_type"`
+	Transports      []string           `bson:"transports,omitempty"`
+	AAGUID          []byte             `bson:"aaguid,omitempty"`
+	SignCount       uint32             `bson:"sign_count"` // signature counter ล่าสุด (ตรวจจับ authenticator ที่ถูก clone)
+	BackupEligible  bool               `bson:"backup_eligible"`
+	BackupState     bool               `bson:"backup_state"`
+	UserVerified    bool               `bson:"user_verified"`
+	CreatedAt       time.Time          `bson:"created_at"`
+	LastUsedAt      *time.Time         `bson:"last_used_at,omitempty"`
+}
+
+// NewCredential - แปลง credential จากการลงทะเบียนเป็น document
+func NewCredential(userID, name string, c *webauthn.Credential) *Credential {
+	transports := make([]string, 0, len(c.Transport))
+	for _, t := range c.Transport {
+		transports = append(transports, string(t))
+	}
+
+	return &Credential{
+		UserID:          userID,
+		Name:            name,
+		CredentialID:    c.ID,
+		PublicKey:       c.PublicKey,
+		AttestationType: c.AttestationType,
+		Transports:      transports,
+		AAGUID:          c.Authenticator.AAGUID,
+		SignCount:       c.Authenticator.SignCount,
+		BackupEligible:  c.Flags.BackupEligible,
+		BackupState:     c.Flags.BackupState,
+		UserVerified:    c.Flags.UserVerified,
+	}
+}
+
+// WebAuthn - แปลงกลับเป็น credential ของ library สำหรับตรวจสอบ assertion
+func (c *Credential) WebAuthn() webauthn.Credential {
+	transports := make([]protocol.AuthenticatorTransport, 0, len(c.Transports))
+	for _, t := range c.Transports {
+		transports = append(transports, protocol.AuthenticatorTransport(t))
+	}
+
+	return webauthn.Credential{
+		ID:              c.CredentialID,
+		PublicKey:       c.PublicKey,
+		AttestationType: c.AttestationType,
+		Transport:       transports,
+		Flags: webauthn.CredentialFlags{
+			UserPresent:    true,
+			UserVerified:   c.UserVerified,
+			BackupEligible: c.BackupEligible,
+			BackupState:    c.BackupState,
+		},
+		Authenticator: webauthn.Authenticator{
+			AAGUID:    c.AAGUID,
+			SignCount: c.SignCount,
+		},
+	}
+}
+
+// Store - credentials ใน collection webauthn_credentials
+type Store struct {
+	db *db.MongoDB
+}
+
+func NewStore(database *db.MongoDB) *Store {
+	return &Store{
+		db: database,
+	}
+}
+
+// Create - บันทึก credential ใหม่
+func (s *Store) Create(ctx context.Context, credential *Credential) error {
+	credential.ID = primitive.NewObjectID()
+	credential.CreatedAt = time.Now()
+
+	if _, err := s.db.WebAuthnCredentials().InsertOne(ctx, credential); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("credential already registered")
+		}
+		return fmt.Errorf("failed to store credential: %w", err)
+	}
+	return nil
+}
+
+// ListByUser - credentials ทั้งหมดของ user
+func (s *Store) ListByUser(ctx context.Context, userID string) ([]*Credential, error) {
+	cursor, err := s.db.WebAuthnCredentials().Find(ctx,
+		bson.M{"user_id": userID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find credentials: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var credentials []*Credential
+	if err := cursor.All(ctx, &credentials); err != nil {
+		return nil, fmt.Errorf("failed to decode credentials: %w", err)
+	}
+	return credentials, nil
+}
+
+// CountByUser - จำนวน credentials ของ user
+func (s *Store) CountByUser(ctx context.Context, userID string) (int64, error) {
+	count, err := s.db.WebAuthnCredentials().CountDocuments(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count credentials: %w", err)
+	}
+	return count, nil
+}
+
+// RecordUse - อัพเดท signature counter และ flags หลังใช้ login สำเร็จ
+func (s *Store) RecordUse(ctx context.Context, credentialID []byte, c *webauthn.Credential) error {
+	now := time.Now()
+	filter := bson.M{"credential_id": credentialID}
+	update := bson.M{"$set": bson.M{
+		"sign_count":    c.Authenticator.SignCount,
+		"backup_state":  c.Flags.BackupState,
+		"user_verified": c.Flags.UserVerified,
+		"last_used_at":  now,
+	}}
+
+	result, err := s.db.WebAuthnCredentials().UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update credential: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package passkey
+
+import (
+	"auth-microservice/internal/models"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// User - ตัวแทน user สำหรับ library (webauthn.User)
+// user handle คือ ObjectID ของ user (12 bytes) เพื่อหา user จาก discoverable credential ได้
+type User struct {
+	user        *models.User
+	credentials []webauthn.Credential
+}
+
+func NewUser(user *models.User, credentials []*Credential) *User {
+	creds := make([]webauthn.Credential, 0, len(credentials))
+	for _, c := range credentials {
+		creds = append(creds, c.WebAuthn())
+	}
+	return &User{
+		user:        user,
+		credentials: creds,
+	}
+}
+
+// Model - user ในฐานข้อมูล
+func (u *User) Model() *models.User {
+	return u.user
+}
+
+func (u *User) WebAuthnID() []byte {
+	id := u.user.ID
+	return id[:]
+}
+
+func (u *User) WebAuthnName() string {
+	return u.user.Email
+}
+
+func (u *User) WebAuthnDisplayName() string {
+	return u.user.FirstName + " " + u.user.LastName
+}
+
+func (u *User) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+// UserIDFromHandle - แปลง user handle กลับเป็น user ID (hex)
+func UserIDFromHandle(handle []byte) (string, bool) {
+	if len(handle) != 12 {
+		return "", false
+	}
+	var id primitive.ObjectID
+	copy(id[:], handle)
+	return id.Hex(), true
+}
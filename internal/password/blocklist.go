@@ -0,0 +1,208 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+)
+
+// รูปแบบไฟล์ blocklist
+const (
+	FormatSHA1  = "sha1"  // text: SHA-1 hex บรรทัดละ 1 ค่า (รองรับ "HASH:COUNT" แบบ Have I Been Pwned)
+	FormatBloom = "bloom" // binary bloom filter ที่สร้างด้วย cmd/blocklist
+)
+
+// bloomMagic - header ของไฟล์ bloom filter
+const bloomMagic = "PWBLOOM1"
+
+// ขนาดสูงสุดที่ ReadBloomFilter ยอมรับ - header ที่เสียจะไม่ทำให้จอง memory มหาศาลตอนเริ่มระบบ
+const (
+	maxBloomBits   = 1 << 35 // 4 GiB (Have I Been Pwned ทั้งหมดที่ false positive 0.1% ใช้ราว 1.6 GiB)
+	maxBloomHashes = 64      // false positive 1e-10 ใช้ราว 33
+)
+
+// Blocklist - รายการรหัสผ่านที่เคยรั่วไหล
+type Blocklist interface {
+	Contains(password string) bool
+}
+
+// LoadBlocklist - โหลด blocklist จากไฟล์ตามรูปแบบที่กำหนด
+func LoadBlocklist(path, format string) (Blocklist, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch format {
+	case FormatSHA1:
+		return ReadSHA1Prefixes(f)
+	case FormatBloom:
+		return ReadBloomFilter(f)
+	default:
+		return nil, fmt.Errorf("unsupported blocklist format %q", format)
+	}
+}
+
+// SHA1Prefixes - blocklist ที่เก็บ 8 bytes แรกของ SHA-1 แบบเรียงลำดับ
+// (ประหยัดหน่วยความจำกว่าเก็บ hash เต็ม โอกาส false positive ต่ำมาก)
+type SHA1Prefixes struct {
+	prefixes []uint64
+}
+
+// ReadSHA1Prefixes - อ่าน SHA-1 hex บรรทัดละ 1 ค่า (อย่างน้อย 16 ตัวอักษร, ข้ามบรรทัดว่างและ #)
+func ReadSHA1Prefixes(r io.Reader) (*SHA1Prefixes, error) {
+	list := &SHA1Prefixes{}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		prefix, ok, err := parseSHA1Line(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if ok {
+			list.prefixes = append(list.prefixes, prefix)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(list.prefixes, func(i, j int) bool { return list.prefixes[i] < list.prefixes[j] })
+	return list, nil
+}
+
+// parseSHA1Line - แปลงบรรทัด "HASH" หรือ "HASH:COUNT" เป็น prefix (ok = false ถ้าเป็นบรรทัดว่างหรือ comment)
+func parseSHA1Line(line string) (uint64, bool, error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return 0, false, nil
+	}
+
+	hash, _, _ := strings.Cut(line, ":")
+	if len(hash) < 16 {
+		return 0, false, errors.New("SHA-1 prefix must be at least 16 hex characters")
+	}
+
+	raw, err := hex.DecodeString(hash[:16])
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid SHA-1 hex: %w", err)
+	}
+	return binary.BigEndian.Uint64(raw), true, nil
+}
+
+func (l *SHA1Prefixes) Contains(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	prefix := binary.BigEndian.Uint64(sum[:8])
+
+	i := sort.Search(len(l.prefixes), func(i int) bool { return l.prefixes[i] >= prefix })
+	return i < len(l.prefixes) && l.prefixes[i] == prefix
+}
+
+// BloomFilter - blocklist แบบ bloom filter ของ SHA-1 (อาจ false positive ตามอัตราที่ตั้งตอนสร้าง แต่ไม่มี false negative)
+//
+// รูปแบบไฟล์: "PWBLOOM1" | k (uint32 big endian) | m bits (uint64 big endian) | bit array
+// ตำแหน่งที่ i = (h1 + i*h2) mod m โดย h1, h2 คือ 8 bytes แรกและถัดไปของ SHA-1
+type BloomFilter struct {
+	k    uint32
+	m    uint64
+	bits []byte
+}
+
+// NewBloomFilter - สร้าง bloom filter ว่างสำหรับ n รายการที่อัตรา false positive ที่ต้องการ
+func NewBloomFilter(n int, falsePositiveRate float64) *BloomFilter {
+	if n < 1 {
+		n = 1
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	if m < 8 {
+		m = 8
+	}
+	k := uint32(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+
+	return &BloomFilter{
+		k:    k,
+		m:    m,
+		bits: make([]byte, (m+7)/8),
+	}
+}
+
+// ReadBloomFilter - อ่าน bloom filter จากไฟล์ที่เขียนด้วย WriteTo
+func ReadBloomFilter(r io.Reader) (*BloomFilter, error) {
+	header := make([]byte, len(bloomMagic)+4+8)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("failed to read bloom filter header: %w", err)
+	}
+	if string(header[:len(bloomMagic)]) != bloomMagic {
+		return nil, errors.New("not a bloom filter file")
+	}
+
+	f := &BloomFilter{
+		k: binary.BigEndian.Uint32(header[len(bloomMagic):]),
+		m: binary.BigEndian.Uint64(header[len(bloomMagic)+4:]),
+	}
+	if f.k == 0 || f.m == 0 {
+		return nil, errors.New("invalid bloom filter parameters")
+	}
+	if f.k > maxBloomHashes || f.m > maxBloomBits {
+		return nil, fmt.Errorf("bloom filter too large (k=%d, m=%d bits)", f.k, f.m)
+	}
+
+	f.bits = make([]byte, (f.m+7)/8)
+	if _, err := io.ReadFull(r, f.bits); err != nil {
+		return nil, fmt.Errorf("failed to read bloom filter bits: %w", err)
+	}
+	return f, nil
+}
+
+// AddSHA1 - เพิ่ม SHA-1 ของรหัสผ่าน (ต้องมีอย่างน้อย 16 bytes)
+func (f *BloomFilter) AddSHA1(sum []byte) {
+	h1, h2 := bloomHashes(sum)
+	for i := uint64(0); i < uint64(f.k); i++ {
+		pos := (h1 + i*h2) % f.m
+		f.bits[pos/8] |= 1 << (pos % 8)
+	}
+}
+
+func (f *BloomFilter) Contains(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	h1, h2 := bloomHashes(sum[:])
+	for i := uint64(0); i < uint64(f.k); i++ {
+		pos := (h1 + i*h2) % f.m
+		if f.bits[pos/8]&(1<<(pos%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteTo - เขียน bloom filter ในรูปแบบที่ ReadBloomFilter อ่านได้
+func (f *BloomFilter) WriteTo(w io.Writer) (int64, error) {
+	header := make([]byte, len(bloomMagic)+4+8)
+	copy(header, bloomMagic)
+	binary.BigEndian.PutUint32(header[len(bloomMagic):], f.k)
+	binary.BigEndian.PutUint64(header[len(bloomMagic)+4:], f.m)
+
+	n, err := w.Write(header)
+	if err != nil {
+		return int64(n), err
+	}
+	m, err := w.Write(f.bits)
+	return int64(n + m), err
+}
+
+// bloomHashes - แยก SHA-1 เป็น 2 hash สำหรับ double hashing (h2 เป็นเลขคี่เสมอ)
+func bloomHashes(sum []byte) (uint64, uint64) {
+	return binary.BigEndian.Uint64(sum[:8]), binary.BigEndian.Uint64(sum[8:16]) | 1
+}
@@ -0,0 +1,179 @@
+package password
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// SHA-1 ของรหัสผ่านที่ใช้ทดสอบ (ตรงกับค่าใน Have I Been Pwned)
+const (
+	sha1Password = "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8" // "password"
+	sha1Numbers  = "7C4A8D09CA3762AF61E59520943DC26494F8941B" // "123456"
+)
+
+func TestReadSHA1Prefixes(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		blocked []string
+		allowed []string
+	}{
+		{
+			name:    "plain hashes",
+			input:   sha1Password + "\n" + sha1Numbers + "\n",
+			blocked: []string{"password", "123456"},
+			allowed: []string{"Password", "1234567"},
+		},
+		{
+			name:    "HASH:COUNT",
+			input:   sha1Password + ":9659365\n" + sha1Numbers + ":37359195\n",
+			blocked: []string{"password", "123456"},
+			allowed: []string{"letmein"},
+		},
+		{
+			name:    "lowercase, 16 character prefix, comments and blank lines",
+			input:   "# breached passwords\n\n  " + strings.ToLower(sha1Password[:16]) + "  \n# " + sha1Numbers + "\n",
+			blocked: []string{"password"},
+			allowed: []string{"123456"},
+		},
+		{
+			name:    "unsorted input",
+			input:   sha1Numbers + "\n" + sha1Password + "\n",
+			blocked: []string{"password", "123456"},
+		},
+		{
+			name:    "empty",
+			input:   "",
+			allowed: []string{"password"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := ReadSHA1Prefixes(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("ReadSHA1Prefixes: %v", err)
+			}
+			for _, p := range tt.blocked {
+				if !list.Contains(p) {
+					t.Errorf("Contains(%q) = false, want true", p)
+				}
+			}
+			for _, p := range tt.allowed {
+				if list.Contains(p) {
+					t.Errorf("Contains(%q) = true, want false", p)
+				}
+			}
+		})
+	}
+}
+
+func TestReadSHA1PrefixesErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"too short", "5BAA61E4C9B93F3\n", "line 1: SHA-1 prefix must be at least 16 hex characters"},
+		{"count without hash", ":9659365\n", "line 1: SHA-1 prefix must be at least 16 hex characters"},
+		{"invalid hex", sha1Password + "\nZZAA61E4C9B93F3F0682:1\n", "line 2: invalid SHA-1 hex"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadSHA1Prefixes(strings.NewReader(tt.input))
+			if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("ReadSHA1Prefixes error = %v, want prefix %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBloomFilterRoundTrip(t *testing.T) {
+	blocked := make([]string, 1000)
+	for i := range blocked {
+		blocked[i] = fmt.Sprintf("breached-%d", i)
+	}
+
+	filter := NewBloomFilter(len(blocked), 0.001)
+	for _, p := range blocked {
+		sum := sha1.Sum([]byte(p))
+		filter.AddSHA1(sum[:])
+	}
+
+	var buf bytes.Buffer
+	n, err := filter.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("WriteTo returned %d bytes, wrote %d", n, buf.Len())
+	}
+
+	read, err := ReadBloomFilter(&buf)
+	if err != nil {
+		t.Fatalf("ReadBloomFilter: %v", err)
+	}
+	if read.k != filter.k || read.m != filter.m || !bytes.Equal(read.bits, filter.bits) {
+		t.Fatalf("read filter (k=%d, m=%d) differs from written filter (k=%d, m=%d)", read.k, read.m, filter.k, filter.m)
+	}
+
+	// ไม่มี false negative
+	for _, p := range blocked {
+		if !read.Contains(p) {
+			t.Errorf("Contains(%q) = false, want true", p)
+		}
+	}
+
+	// false positive ต้องใกล้เคียงอัตราที่ตั้งไว้ (เผื่อไว้ 5 เท่า)
+	falsePositives := 0
+	const trials = 10000
+	for i := 0; i < trials; i++ {
+		if read.Contains(fmt.Sprintf("unique-%d", i)) {
+			falsePositives++
+		}
+	}
+	if falsePositives > trials*5/1000 {
+		t.Errorf("%d/%d false positives, want about 0.1%%", falsePositives, trials)
+	}
+}
+
+// bloomHeader - header ของไฟล์ bloom filter ที่มี k และ m ตามที่กำหนด
+func bloomHeader(magic string, k uint32, m uint64) []byte {
+	header := make([]byte, len(bloomMagic)+4+8)
+	copy(header, magic)
+	binary.BigEndian.PutUint32(header[len(bloomMagic):], k)
+	binary.BigEndian.PutUint64(header[len(bloomMagic)+4:], m)
+	return header
+}
+
+func TestReadBloomFilterErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+		want  string
+	}{
+		{"empty", nil, "failed to read bloom filter header"},
+		{"truncated header", []byte(bloomMagic + "\x00\x00"), "failed to read bloom filter header"},
+		{"bad magic", bloomHeader("PWBLOOM2", 7, 64), "not a bloom filter file"},
+		{"zero hashes", bloomHeader(bloomMagic, 0, 64), "invalid bloom filter parameters"},
+		{"zero bits", bloomHeader(bloomMagic, 7, 0), "invalid bloom filter parameters"},
+		{"too many hashes", bloomHeader(bloomMagic, maxBloomHashes+1, 64), "bloom filter too large"},
+		{"too many bits", bloomHeader(bloomMagic, 7, maxBloomBits+1), "bloom filter too large"},
+		{"corrupt bit count", bloomHeader(bloomMagic, 7, 1<<63), "bloom filter too large"},
+		{"truncated bits", append(bloomHeader(bloomMagic, 7, 64), 0xff, 0xff), "failed to read bloom filter bits"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadBloomFilter(bytes.NewReader(tt.input))
+			if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("ReadBloomFilter error = %v, want prefix %q", err, tt.want)
+			}
+		})
+	}
+}
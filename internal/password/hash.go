@@ -0,0 +1,239 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrUnknownHash - hash ที่เก็บไว้ไม่ตรงกับ algorithm ใดที่รู้จัก
+var ErrUnknownHash = errors.New("unrecognized password hash format")
+
+// Algorithm - algorithm สำหรับ hash รหัสผ่าน (hash ที่ได้มี prefix บอก algorithm และ parameters)
+type Algorithm interface {
+	// Name - ชื่อ algorithm (ใช้ใน config และ log)
+	Name() string
+	// Hash - hash รหัสผ่านด้วย parameters ปัจจุบัน
+	Hash(password string) (string, error)
+	// Recognizes - hash นี้สร้างด้วย algorithm นี้หรือไม่ (ดูจาก prefix)
+	Recognizes(encoded string) bool
+	// Verify - ตรวจรหัสผ่านกับ hash (ใช้ parameters ที่อยู่ใน hash)
+	Verify(encoded, password string) (bool, error)
+	// Outdated - hash ใช้ parameters ต่างจากปัจจุบันหรือไม่
+	Outdated(encoded string) bool
+}
+
+// Hasher - hash รหัสผ่านด้วย algorithm ปัจจุบัน และยังตรวจ hash ของ algorithm เก่าได้
+type Hasher struct {
+	current Algorithm
+	legacy  []Algorithm
+}
+
+// NewHasher - current ใช้ hash รหัสผ่านใหม่ legacy ใช้ตรวจ hash เดิมที่ยังไม่ถูก rehash
+func NewHasher(current Algorithm, legacy ...Algorithm) *Hasher {
+	return &Hasher{
+		current: current,
+		legacy:  legacy,
+	}
+}
+
+// DefaultHasher - Argon2id เป็นหลัก และยังตรวจ bcrypt ได้
+func DefaultHasher() *Hasher {
+	return NewHasher(NewArgon2id(DefaultArgon2idParams()), NewBcrypt(bcrypt.DefaultCost))
+}
+
+// Current - algorithm ที่ใช้ hash รหัสผ่านใหม่
+func (h *Hasher) Current() Algorithm {
+	return h.current
+}
+
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.current.Hash(password)
+}
+
+// Verify - ตรวจรหัสผ่านกับ hash ด้วย algorithm ที่สร้าง hash นั้น
+func (h *Hasher) Verify(encoded, password string) (bool, error) {
+	algorithm := h.identify(encoded)
+	if algorithm == nil {
+		return false, ErrUnknownHash
+	}
+	return algorithm.Verify(encoded, password)
+}
+
+// NeedsRehash - hash ควรถูกสร้างใหม่ (algorithm เก่าหรือ parameters ไม่ตรงกับปัจจุบัน)
+func (h *Hasher) NeedsRehash(encoded string) bool {
+	if !h.current.Recognizes(encoded) {
+		return true
+	}
+	return h.current.Outdated(encoded)
+}
+
+func (h *Hasher) identify(encoded string) Algorithm {
+	if h.current.Recognizes(encoded) {
+		return h.current
+	}
+	for _, algorithm := range h.legacy {
+		if algorithm.Recognizes(encoded) {
+			return algorithm
+		}
+	}
+	return nil
+}
+
+// Bcrypt - hash รูปแบบ $2a$/$2b$/$2y$ (cost อยู่ใน hash)
+type Bcrypt struct {
+	cost int
+}
+
+func NewBcrypt(cost int) *Bcrypt {
+	return &Bcrypt{
+		cost: cost,
+	}
+}
+
+func (b *Bcrypt) Name() string {
+	return "bcrypt"
+}
+
+func (b *Bcrypt) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), b.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+func (b *Bcrypt) Recognizes(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+func (b *Bcrypt) Verify(encoded, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if err == bcrypt.ErrMismatchedHashAndPassword {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (b *Bcrypt) Outdated(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != b.cost
+}
+
+// Argon2idParams - parameters ของ Argon2id
+type Argon2idParams struct {
+	Memory      uint32 // KiB
+	Time        uint32 // จำนวนรอบ
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2idParams - ค่าเริ่มต้นตามคำแนะนำของ OWASP (64 MiB, 3 รอบ)
+func DefaultArgon2idParams() Argon2idParams {
+	return Argon2idParams{
+		Memory:      64 * 1024,
+		Time:        3,
+		Parallelism: 2,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+// Argon2id - hash รูปแบบ PHC: $argon2id$v=19$m=<KiB>,t=<รอบ>,p=<threads>$<salt>$<key>
+type Argon2id struct {
+	params Argon2idParams
+}
+
+func NewArgon2id(params Argon2idParams) *Argon2id {
+	return &Argon2id{
+		params: params,
+	}
+}
+
+func (a *Argon2id) Name() string {
+	return "argon2id"
+}
+
+func (a *Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, a.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, a.params.Time, a.params.Memory, a.params.Parallelism, a.params.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, a.params.Memory, a.params.Time, a.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (a *Argon2id) Recognizes(encoded string) bool {
+	return strings.HasPrefix(encoded, "$argon2id$")
+}
+
+func (a *Argon2id) Verify(encoded, password string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	computed := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(computed, key) == 1, nil
+}
+
+func (a *Argon2id) Outdated(encoded string) bool {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	return params.Memory != a.params.Memory ||
+		params.Time != a.params.Time ||
+		params.Parallelism != a.params.Parallelism ||
+		uint32(len(salt)) != a.params.SaltLength ||
+		uint32(len(key)) != a.params.KeyLength
+}
+
+// decodeArgon2id - แยก parameters, salt และ key จาก hash รูปแบบ PHC
+func decodeArgon2id(encoded string) (Argon2idParams, []byte, []byte, error) {
+	var params Argon2idParams
+
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, ErrUnknownHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id version: %w", err)
+	}
+	if version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2id version %d", version)
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id parameters: %w", err)
+	}
+	if params.Time == 0 || params.Parallelism == 0 {
+		return params, nil, nil, errors.New("invalid argon2id parameters")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id salt: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, errors.New("invalid argon2id key")
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
+}
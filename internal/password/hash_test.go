@@ -0,0 +1,264 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testArgon2idParams - parameters ขนาดเล็กให้ test เร็ว (รูปแบบ hash เหมือนค่าจริงทุกอย่าง)
+func testArgon2idParams() Argon2idParams {
+	return Argon2idParams{
+		Memory:      64,
+		Time:        1,
+		Parallelism: 1,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+func TestArgon2idRoundTrip(t *testing.T) {
+	params := testArgon2idParams()
+	a := NewArgon2id(params)
+
+	encoded, err := a.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+
+	prefix := fmt.Sprintf("$argon2id$v=19$m=%d,t=%d,p=%d$", params.Memory, params.Time, params.Parallelism)
+	if !strings.HasPrefix(encoded, prefix) {
+		t.Errorf("Hash = %q, want prefix %q", encoded, prefix)
+	}
+	if !a.Recognizes(encoded) {
+		t.Errorf("Recognizes(%q) = false", encoded)
+	}
+
+	decoded, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		t.Fatalf("decodeArgon2id: %v", err)
+	}
+	if decoded != params {
+		t.Errorf("decoded parameters = %+v, want %+v", decoded, params)
+	}
+	if len(salt) != int(params.SaltLength) || len(key) != int(params.KeyLength) {
+		t.Errorf("decoded %d bytes of salt and %d bytes of key, want %d and %d", len(salt), len(key), params.SaltLength, params.KeyLength)
+	}
+
+	for _, tt := range []struct {
+		password string
+		want     bool
+	}{
+		{"correct horse", true},
+		{"Correct horse", false},
+		{"correct horse ", false},
+		{"", false},
+	} {
+		if ok, err := a.Verify(encoded, tt.password); err != nil || ok != tt.want {
+			t.Errorf("Verify(%q) = (%t, %v), want (%t, nil)", tt.password, ok, err, tt.want)
+		}
+	}
+
+	other, _ := a.Hash("correct horse")
+	if other == encoded {
+		t.Error("two hashes of the same password are equal (salt not random)")
+	}
+}
+
+func TestArgon2idVerifyUsesStoredParameters(t *testing.T) {
+	old := NewArgon2id(testArgon2idParams())
+	encoded, err := old.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+
+	// เปลี่ยน parameters แล้ว hash เดิมยังตรวจได้ (ใช้ parameters ที่อยู่ใน hash)
+	params := testArgon2idParams()
+	params.Memory, params.Time, params.KeyLength = 128, 2, 16
+	current := NewArgon2id(params)
+
+	if ok, err := current.Verify(encoded, "correct horse"); err != nil || !ok {
+		t.Errorf("Verify with changed parameters = (%t, %v), want (true, nil)", ok, err)
+	}
+}
+
+func TestDecodeArgon2idErrors(t *testing.T) {
+	valid, err := NewArgon2id(testArgon2idParams()).Hash("correct horse")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	parts := strings.Split(valid, "$")
+	salt, key := parts[4], parts[5]
+
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{"empty", ""},
+		{"bcrypt", "$2a$10$abcdefghijklmnopqrstuu"},
+		{"argon2i", "$argon2i$v=19$m=64,t=1,p=1$" + salt + "$" + key},
+		{"missing key", "$argon2id$v=19$m=64,t=1,p=1$" + salt},
+		{"bad version", "$argon2id$v=x$m=64,t=1,p=1$" + salt + "$" + key},
+		{"old version", "$argon2id$v=16$m=64,t=1,p=1$" + salt + "$" + key},
+		{"bad parameters", "$argon2id$v=19$m=64;t=1;p=1$" + salt + "$" + key},
+		{"zero time", "$argon2id$v=19$m=64,t=0,p=1$" + salt + "$" + key},
+		{"zero parallelism", "$argon2id$v=19$m=64,t=1,p=0$" + salt + "$" + key},
+		{"bad salt", "$argon2id$v=19$m=64,t=1,p=1$!!!$" + key},
+		{"bad key", "$argon2id$v=19$m=64,t=1,p=1$" + salt + "$!!!"},
+		{"empty key", "$argon2id$v=19$m=64,t=1,p=1$" + salt + "$"},
+	}
+
+	a := NewArgon2id(testArgon2idParams())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, _, err := decodeArgon2id(tt.encoded); err == nil {
+				t.Errorf("decodeArgon2id(%q) succeeded", tt.encoded)
+			}
+			if ok, err := a.Verify(tt.encoded, "correct horse"); ok || err == nil {
+				t.Errorf("Verify(%q) = (%t, %v), want an error", tt.encoded, ok, err)
+			}
+			if !a.Outdated(tt.encoded) {
+				t.Errorf("Outdated(%q) = false, want true", tt.encoded)
+			}
+		})
+	}
+}
+
+func TestArgon2idOutdated(t *testing.T) {
+	encoded, err := NewArgon2id(testArgon2idParams()).Hash("correct horse")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		change func(*Argon2idParams)
+		want   bool
+	}{
+		{"same parameters", func(*Argon2idParams) {}, false},
+		{"memory", func(p *Argon2idParams) { p.Memory *= 2 }, true},
+		{"time", func(p *Argon2idParams) { p.Time++ }, true},
+		{"parallelism", func(p *Argon2idParams) { p.Parallelism++ }, true},
+		{"salt length", func(p *Argon2idParams) { p.SaltLength = 32 }, true},
+		{"key length", func(p *Argon2idParams) { p.KeyLength = 64 }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := testArgon2idParams()
+			tt.change(&params)
+			if got := NewArgon2id(params).Outdated(encoded); got != tt.want {
+				t.Errorf("Outdated = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBcrypt(t *testing.T) {
+	b := NewBcrypt(bcrypt.MinCost)
+
+	encoded, err := b.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if !b.Recognizes(encoded) {
+		t.Errorf("Recognizes(%q) = false", encoded)
+	}
+	for _, prefix := range []string{"$2a$", "$2b$", "$2y$"} {
+		if !b.Recognizes(prefix + "10$abc") {
+			t.Errorf("Recognizes(%q) = false", prefix)
+		}
+	}
+	if b.Recognizes("$argon2id$v=19$") {
+		t.Error("bcrypt recognizes an argon2id hash")
+	}
+
+	if ok, err := b.Verify(encoded, "correct horse"); err != nil || !ok {
+		t.Errorf("Verify(correct) = (%t, %v), want (true, nil)", ok, err)
+	}
+	if ok, err := b.Verify(encoded, "wrong horse"); err != nil || ok {
+		t.Errorf("Verify(wrong) = (%t, %v), want (false, nil)", ok, err)
+	}
+	if ok, err := b.Verify("$2a$10$short", "correct horse"); err == nil || ok {
+		t.Errorf("Verify(malformed) = (%t, %v), want an error", ok, err)
+	}
+
+	if b.Outdated(encoded) {
+		t.Error("Outdated = true for a hash with the current cost")
+	}
+	if !NewBcrypt(bcrypt.MinCost + 1).Outdated(encoded) {
+		t.Error("Outdated = false after the cost changed")
+	}
+	if !b.Outdated("$2a$") {
+		t.Error("Outdated = false for a malformed hash")
+	}
+}
+
+func TestHasherBcryptFallback(t *testing.T) {
+	legacy := NewBcrypt(bcrypt.MinCost)
+	h := NewHasher(NewArgon2id(testArgon2idParams()), legacy)
+
+	old, err := legacy.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("bcrypt Hash: %v", err)
+	}
+
+	// hash เดิมแบบ bcrypt ยังตรวจได้ แต่ต้อง rehash
+	if ok, err := h.Verify(old, "correct horse"); err != nil || !ok {
+		t.Errorf("Verify(bcrypt, correct) = (%t, %v), want (true, nil)", ok, err)
+	}
+	if ok, err := h.Verify(old, "wrong horse"); err != nil || ok {
+		t.Errorf("Verify(bcrypt, wrong) = (%t, %v), want (false, nil)", ok, err)
+	}
+	if !h.NeedsRehash(old) {
+		t.Error("NeedsRehash(bcrypt) = false, want true")
+	}
+
+	// hash ใหม่ใช้ algorithm ปัจจุบันและไม่ต้อง rehash
+	fresh, err := h.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if !strings.HasPrefix(fresh, "$argon2id$") {
+		t.Errorf("Hash = %q, want an argon2id hash", fresh)
+	}
+	if ok, err := h.Verify(fresh, "correct horse"); err != nil || !ok {
+		t.Errorf("Verify(argon2id) = (%t, %v), want (true, nil)", ok, err)
+	}
+	if h.NeedsRehash(fresh) {
+		t.Error("NeedsRehash(current hash) = true, want false")
+	}
+
+	// parameters ของ algorithm ปัจจุบันเปลี่ยน - hash เดิมยังตรวจได้แต่ต้อง rehash
+	params := testArgon2idParams()
+	params.Time++
+	upgraded := NewHasher(NewArgon2id(params), legacy)
+	if ok, err := upgraded.Verify(fresh, "correct horse"); err != nil || !ok {
+		t.Errorf("Verify after parameter change = (%t, %v), want (true, nil)", ok, err)
+	}
+	if !upgraded.NeedsRehash(fresh) {
+		t.Error("NeedsRehash after parameter change = false, want true")
+	}
+}
+
+func TestHasherUnknownHash(t *testing.T) {
+	// ไม่มี legacy - hash ของ bcrypt ตรวจไม่ได้
+	h := NewHasher(NewArgon2id(testArgon2idParams()))
+
+	old, err := NewBcrypt(bcrypt.MinCost).Hash("correct horse")
+	if err != nil {
+		t.Fatalf("bcrypt Hash: %v", err)
+	}
+
+	for _, encoded := range []string{old, "", "plaintext", "$scrypt$ln=15,r=8,p=1$abc$def"} {
+		if ok, err := h.Verify(encoded, "correct horse"); ok || !errors.Is(err, ErrUnknownHash) {
+			t.Errorf("Verify(%q) = (%t, %v), want ErrUnknownHash", encoded, ok, err)
+		}
+		if !h.NeedsRehash(encoded) {
+			t.Errorf("NeedsRehash(%q) = false, want true", encoded)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package password
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// เหตุผลที่รหัสผ่านไม่ผ่าน policy (ใช้เป็น reason ของ field violation)
+const (
+	ReasonRequired         = "PASSWORD_REQUIRED"
+	ReasonTooShort         = "PASSWORD_TOO_SHORT"
+	ReasonTooLong          = "PASSWORD_TOO_LONG"
+	ReasonMissingUpper     = "PASSWORD_MISSING_UPPERCASE"
+	ReasonMissingLower     = "PASSWORD_MISSING_LOWERCASE"
+	ReasonMissingDigit     = "PASSWORD_MISSING_DIGIT"
+	ReasonMissingSymbol    = "PASSWORD_MISSING_SYMBOL"
+	ReasonContainsPersonal = "PASSWORD_CONTAINS_PERSONAL_INFO"
+	ReasonBreached         = "PASSWORD_BREACHED"
+)
+
+// minPersonalInfoLength - ข้อมูลส่วนตัวที่สั้นกว่านี้ไม่นำมาตรวจ (เช่นชื่อ 2 ตัวอักษร)
+const minPersonalInfoLength = 3
+
+// Violation - ข้อที่รหัสผ่านไม่ผ่าน policy
+type Violation struct {
+	Reason      string
+	Description string
+}
+
+// Policy - กฎของรหัสผ่านใหม่ (ใช้ร่วมกันทั้ง Register, ChangePassword และ ResetPassword)
+type Policy struct {
+	MinLength int // จำนวนตัวอักษรขั้นต่ำ
+	MaxLength int // จำนวน bytes สูงสุด (0 = ไม่จำกัด, bcrypt ใช้ได้ไม่เกิน 72 bytes)
+
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+
+	DisallowPersonalInfo bool      // ห้ามมี email หรือชื่อของ user อยู่ในรหัสผ่าน
+	Blocklist            Blocklist // รหัสผ่านที่เคยรั่วไหล (nil = ไม่ตรวจ)
+}
+
+// DefaultPolicy - policy เริ่มต้นเมื่อไม่ได้ตั้งค่า
+func DefaultPolicy() *Policy {
+	return &Policy{
+		MinLength:            8,
+		MaxLength:            72,
+		DisallowPersonalInfo: true,
+	}
+}
+
+// Check - ตรวจรหัสผ่านตาม policy คืนทุกข้อที่ไม่ผ่าน (ว่าง = ผ่าน)
+// personal คือข้อมูลของ user ที่ห้ามใช้เป็นส่วนหนึ่งของรหัสผ่าน (email, ชื่อ, นามสกุล)
+func (p *Policy) Check(password string, personal ...string) []Violation {
+	if password == "" {
+		return []Violation{{Reason: ReasonRequired, Description: "password is required"}}
+	}
+
+	var violations []Violation
+	add := func(reason, format string, args ...interface{}) {
+		violations = append(violations, Violation{Reason: reason, Description: fmt.Sprintf(format, args...)})
+	}
+
+	if utf8.RuneCountInString(password) < p.MinLength {
+		add(ReasonTooShort, "password must be at least %d characters", p.MinLength)
+	}
+	if p.MaxLength > 0 && len(password) > p.MaxLength {
+		add(ReasonTooLong, "password must be at most %d bytes", p.MaxLength)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.RequireUpper && !hasUpper {
+		add(ReasonMissingUpper, "password must contain an uppercase letter")
+	}
+	if p.RequireLower && !hasLower {
+		add(ReasonMissingLower, "password must contain a lowercase letter")
+	}
+	if p.RequireDigit && !hasDigit {
+		add(ReasonMissingDigit, "password must contain a digit")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		add(ReasonMissingSymbol, "password must contain a symbol")
+	}
+
+	if p.DisallowPersonalInfo && containsPersonalInfo(password, personal) {
+		add(ReasonContainsPersonal, "password must not contain your email or name")
+	}
+
+	if p.Blocklist != nil && p.Blocklist.Contains(password) {
+		add(ReasonBreached, "password has appeared in a data breach, please choose another")
+	}
+
+	return violations
+}
+
+// containsPersonalInfo - ตรวจว่ารหัสผ่านมี email (ทั้งหมดหรือส่วนหน้า @) หรือชื่ออยู่หรือไม่
+func containsPersonalInfo(password string, personal []string) bool {
+	lower := strings.ToLower(password)
+	for _, value := range personal {
+		value = strings.ToLower(strings.TrimSpace(value))
+		candidates := []string{value}
+		if local, _, ok := strings.Cut(value, "@"); ok {
+			candidates = append(candidates, local)
+		}
+		for _, c := range candidates {
+			if utf8.RuneCountInString(c) >= minPersonalInfoLength && strings.Contains(lower, c) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package password
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// staticBlocklist - blocklist ใน memory สำหรับทดสอบ
+type staticBlocklist map[string]bool
+
+func (b staticBlocklist) Contains(password string) bool { return b[password] }
+
+func reasons(violations []Violation) []string {
+	var out []string
+	for _, v := range violations {
+		out = append(out, v.Reason)
+	}
+	return out
+}
+
+func TestPolicyCheck(t *testing.T) {
+	strict := &Policy{
+		MinLength:     8,
+		MaxLength:     72,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+	}
+
+	tests := []struct {
+		name     string
+		policy   *Policy
+		password string
+		personal []string
+		want     []string
+	}{
+		{"empty", strict, "", nil, []string{ReasonRequired}},
+		{"valid", strict, "Correct-Horse-9", nil, nil},
+		{"too short", strict, "Ab1!", nil, []string{ReasonTooShort}},
+		{"min length counts runes", &Policy{MinLength: 4}, "ภาษา", nil, nil},
+		{"too long", strict, "Aa1!" + strings.Repeat("x", 69), nil, []string{ReasonTooLong}},
+		{"max length counts bytes", &Policy{MaxLength: 8}, "ภาษาไทย", nil, []string{ReasonTooLong}},
+		{"no max length", &Policy{}, strings.Repeat("x", 200), nil, nil},
+		{"missing upper", strict, "correct-horse-9", nil, []string{ReasonMissingUpper}},
+		{"missing lower", strict, "CORRECT-HORSE-9", nil, []string{ReasonMissingLower}},
+		{"missing digit", strict, "Correct-Horse", nil, []string{ReasonMissingDigit}},
+		{"missing symbol", strict, "CorrectHorse9", nil, []string{ReasonMissingSymbol}},
+		{"space is a symbol", strict, "Correct Horse 9", nil, nil},
+		{"several violations", strict, "abc", nil, []string{ReasonTooShort, ReasonMissingUpper, ReasonMissingDigit, ReasonMissingSymbol}},
+		{
+			"personal info",
+			&Policy{MinLength: 8, DisallowPersonalInfo: true},
+			"alice-rocks-2024",
+			[]string{"alice@example.com", "Alice", "Smith"},
+			[]string{ReasonContainsPersonal},
+		},
+		{
+			"personal info allowed",
+			&Policy{MinLength: 8},
+			"alice-rocks-2024",
+			[]string{"alice@example.com"},
+			nil,
+		},
+		{
+			"breached",
+			&Policy{MinLength: 8, Blocklist: staticBlocklist{"password1": true}},
+			"password1",
+			nil,
+			[]string{ReasonBreached},
+		},
+		{
+			"not breached",
+			&Policy{MinLength: 8, Blocklist: staticBlocklist{"password1": true}},
+			"password2",
+			nil,
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reasons(tt.policy.Check(tt.password, tt.personal...))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check(%q) = %v, want %v", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyCheckDescriptions(t *testing.T) {
+	violations := DefaultPolicy().Check("short")
+	if len(violations) != 1 || violations[0].Description != "password must be at least 8 characters" {
+		t.Errorf("Check(%q) = %+v, want a single too-short violation mentioning 8 characters", "short", violations)
+	}
+}
+
+func TestContainsPersonalInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		personal []string
+		want     bool
+	}{
+		{"no personal info", "Correct-Horse-9", nil, false},
+		{"full email", "xalice@example.comx", []string{"alice@example.com"}, true},
+		{"email local part", "Alice2024!", []string{"alice@example.com"}, true},
+		{"email domain only", "example-2024", []string{"alice@example.com"}, false},
+		{"first name", "bobby-tables", []string{"bob@example.com", "Tables"}, true},
+		{"case insensitive", "MY-NAME-IS-SOMCHAI", []string{"somchai"}, true},
+		{"thai name", "รหัสสมชายนะ", []string{"สมชาย"}, true},
+		{"surrounding spaces", "smith-family", []string{"  Smith "}, true},
+		{"short name ignored", "alpha-beta-1", []string{"Al"}, false},
+		{"short local part ignored", "jo-pass-word", []string{"jo@example.com"}, false},
+		{"empty value ignored", "anything", []string{""}, false},
+		{"unrelated", "Correct-Horse-9", []string{"alice@example.com", "Alice", "Smith"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := containsPersonalInfo(tt.password, tt.personal); got != tt.want {
+				t.Errorf("containsPersonalInfo(%q, %q) = %t, want %t", tt.password, tt.personal, got, tt.want)
+			}
+		})
+	}
+}
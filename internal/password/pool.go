@@ -0,0 +1,191 @@
+package password
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrPoolFull - คิวเต็ม (ระบบรับงาน hash เพิ่มไม่ได้ในขณะนี้)
+	ErrPoolFull = errors.New("password hashing queue is full")
+	// ErrPoolTimeout - รอคิวหรือ hash นานเกินเวลาที่กำหนด
+	ErrPoolTimeout = errors.New("password hashing timed out")
+	// ErrPoolClosed - pool ถูกปิดแล้ว
+	ErrPoolClosed = errors.New("password hashing pool is closed")
+)
+
+// PoolConfig - ขนาดของ pool สำหรับ hash รหัสผ่าน
+type PoolConfig struct {
+	Workers   int           // จำนวน goroutine ที่ hash พร้อมกัน (0 = จำนวน CPU)
+	QueueSize int           // จำนวนงานที่รอได้ (เกินนี้ถูกปฏิเสธทันที)
+	Timeout   time.Duration // เวลาสูงสุดต่อ request รวมเวลารอคิว (0 = ใช้ deadline ของ request เท่านั้น)
+}
+
+// PoolStats - สถานะของ pool (export เป็น metrics)
+type PoolStats struct {
+	Workers       int    `json:"workers"`
+	QueueCapacity int    `json:"queue_capacity"`
+	QueueDepth    int    `json:"queue_depth"` // งานที่รออยู่ในคิว
+	Active        int64  `json:"active"`      // งานที่กำลัง hash
+	Completed     uint64 `json:"completed"`
+	Rejected      uint64 `json:"rejected"` // ถูกปฏิเสธเพราะคิวเต็ม
+	Expired       uint64 `json:"expired"`  // หมดเวลาก่อนได้ทำ
+}
+
+// Pool - จำกัดจำนวนการ hash รหัสผ่านพร้อมกัน เพื่อไม่ให้ login จำนวนมากใช้ CPU จนหมด
+type Pool struct {
+	hasher  *Hasher
+	jobs    chan *job
+	workers int
+	timeout time.Duration
+
+	active    atomic.Int64
+	completed atomic.Uint64
+	rejected  atomic.Uint64
+	expired   atomic.Uint64
+
+	closeOnce sync.Once
+	closed    chan struct{}
+	wg        sync.WaitGroup
+}
+
+type job struct {
+	ctx  context.Context
+	run  func()
+	done chan struct{}
+}
+
+// NewPool - สร้าง pool และเริ่ม workers (เรียก Close เมื่อปิด server)
+func NewPool(hasher *Hasher, cfg PoolConfig) *Pool {
+	if cfg.Workers <= 0 {
+		cfg.Workers = runtime.NumCPU()
+	}
+	if cfg.QueueSize < 0 {
+		cfg.QueueSize = 0
+	}
+
+	p := &Pool{
+		hasher:  hasher,
+		jobs:    make(chan *job, cfg.QueueSize),
+		workers: cfg.Workers,
+		timeout: cfg.Timeout,
+		closed:  make(chan struct{}),
+	}
+
+	for i := 0; i < cfg.Workers; i++ {
+		p.wg.Add(1)
+		go p.work()
+	}
+
+	return p
+}
+
+// Hasher - hasher ที่ pool ใช้
+func (p *Pool) Hasher() *Hasher {
+	return p.hasher
+}
+
+// Hash - hash รหัสผ่านด้วย algorithm ปัจจุบัน
+func (p *Pool) Hash(ctx context.Context, plain string) (string, error) {
+	var hashed string
+	var err error
+	if submitErr := p.submit(ctx, func() { hashed, err = p.hasher.Hash(plain) }); submitErr != nil {
+		return "", submitErr
+	}
+	return hashed, err
+}
+
+// Verify - ตรวจรหัสผ่านกับ hash
+func (p *Pool) Verify(ctx context.Context, encoded, plain string) (bool, error) {
+	var ok bool
+	var err error
+	if submitErr := p.submit(ctx, func() { ok, err = p.hasher.Verify(encoded, plain) }); submitErr != nil {
+		return false, submitErr
+	}
+	return ok, err
+}
+
+// Stats - สถานะปัจจุบันของ pool
+func (p *Pool) Stats() PoolStats {
+	return PoolStats{
+		Workers:       p.workers,
+		QueueCapacity: cap(p.jobs),
+		QueueDepth:    len(p.jobs),
+		Active:        p.active.Load(),
+		Completed:     p.completed.Load(),
+		Rejected:      p.rejected.Load(),
+		Expired:       p.expired.Load(),
+	}
+}
+
+// Close - หยุดรับงานใหม่และรอ workers ทำงานที่กำลังทำให้เสร็จ (งานที่ยังรอคิวได้ ErrPoolClosed)
+func (p *Pool) Close() {
+	p.closeOnce.Do(func() {
+		close(p.closed)
+		p.wg.Wait()
+	})
+}
+
+// submit - ส่งงานเข้าคิวโดยไม่รอ (คิวเต็ม = ErrPoolFull) แล้วรอจนเสร็จหรือหมดเวลา
+func (p *Pool) submit(ctx context.Context, run func()) error {
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	j := &job{ctx: ctx, run: run, done: make(chan struct{})}
+
+	select {
+	case <-p.closed:
+		return ErrPoolClosed
+	default:
+	}
+
+	select {
+	case p.jobs <- j:
+	default:
+		p.rejected.Add(1)
+		return ErrPoolFull
+	}
+
+	select {
+	case <-j.done:
+		return nil
+	case <-p.closed:
+		return ErrPoolClosed
+	case <-ctx.Done():
+		// worker จะข้ามงานนี้ถ้ายังไม่เริ่ม - ถ้าเริ่มแล้วผลลัพธ์จะถูกทิ้ง
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%w: %v", ErrPoolTimeout, ctx.Err())
+		}
+		return ctx.Err()
+	}
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+
+	for {
+		select {
+		case <-p.closed:
+			return
+		case j := <-p.jobs:
+			if j.ctx.Err() != nil {
+				p.expired.Add(1)
+				continue
+			}
+
+			p.active.Add(1)
+			j.run()
+			p.active.Add(-1)
+			p.completed.Add(1)
+			close(j.done)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"auth-microservice/pkg/db"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Rule - จำนวนครั้งสูงสุดต่อช่วงเวลา
+type Rule struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+}
+
+// Result - ผลการตรวจสอบ rate limit
+type Result struct {
+	Allowed    bool
+	RetryAfter time.Duration
+}
+
+// Limiter - sliding window rate limiter ที่เก็บ counter ใน collection rate_limits
+// (ใช้ร่วมกันได้ทุก replica)
+//
+// ใช้วิธี sliding window counter: นับจำนวนใน window ปัจจุบันและ window ก่อนหน้า
+// แล้วถ่วงน้ำหนัก window ก่อนหน้าตามเวลาที่ยังทับซ้อนอยู่
+type Limiter struct {
+	db *db.MongoDB
+}
+
+func NewLimiter(database *db.MongoDB) *Limiter {
+	return &Limiter{
+		db: database,
+	}
+}
+
+// counter - document ใน rate_limits (หนึ่ง document ต่อ key ต่อ window)
+type counter struct {
+	ID        string    `bson:"_id"`
+	Count     int       `bson:"count"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+// Allow - นับ request หนึ่งครั้งสำหรับ key และตรวจสอบว่าเกิน limit หรือไม่
+func (l *Limiter) Allow(ctx context.Context, rule Rule, key string) (*Result, error) {
+	now := time.Now()
+	windowStart := now.Truncate(rule.Window)
+	prefix := rule.Name + ":" + hashKey(key) + ":"
+
+	currentID := prefix + strconv.FormatInt(windowStart.Unix(), 10)
+	previousID := prefix + strconv.FormatInt(windowStart.Add(-rule.Window).Unix(), 10)
+
+	// เพิ่ม counter ของ window ปัจจุบัน (document หมดอายุหลังจากไม่ถูกใช้เป็น window ก่อนหน้าแล้ว)
+	var current counter
+	err := l.db.RateLimits().FindOneAndUpdate(ctx,
+		bson.M{"_id": currentID},
+		bson.M{
+			"$inc":         bson.M{"count": 1},
+			"$setOnInsert": bson.M{"expires_at": windowStart.Add(2 * rule.Window)},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&current)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update rate limit counter: %w", err)
+	}
+
+	var previous counter
+	err = l.db.RateLimits().FindOne(ctx, bson.M{"_id": previousID}).Decode(&previous)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("failed to read rate limit counter: %w", err)
+	}
+
+	elapsed := now.Sub(windowStart)
+	weight := 1 - float64(elapsed)/float64(rule.Window)
+	estimate := float64(previous.Count)*weight + float64(current.Count)
+
+	if estimate <= float64(rule.Limit) {
+		return &Result{Allowed: true}, nil
+	}
+
+	return &Result{
+		Allowed:    false,
+		RetryAfter: retryAfter(rule, elapsed, previous.Count, current.Count),
+	}, nil
+}
+
+// retryAfter - ประมาณเวลาที่ต้องรอจนกว่า request ถัดไปจะผ่าน
+func retryAfter(rule Rule, elapsed time.Duration, previous, current int) time.Duration {
+	window := float64(rule.Window)
+	limit := float64(rule.Limit)
+
+	var wait float64
+	if float64(current) < limit && previous > 0 {
+		// รอให้น้ำหนักของ window ก่อนหน้าลดลงจนเหลือที่ว่าง
+		wait = window*(1-(limit-float64(current)-1)/float64(previous)) - float64(elapsed)
+	} else {
+		// window ปัจจุบันเต็มแล้ว - รอไปถึง window ถัดไปและให้น้ำหนักลดลง
+		wait = (window - float64(elapsed)) + window*(1-(limit-1)/float64(current))
+	}
+
+	d := time.Duration(math.Ceil(wait/float64(time.Second))) * time.Second
+	if d < time.Second {
+		d = time.Second
+	}
+	if d > 2*rule.Window {
+		d = 2 * rule.Window
+	}
+	return d
+}
+
+// hashKey - ไม่เก็บ IP/email ตรงๆ ใน rate_limits
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:16])
+}
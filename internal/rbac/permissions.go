@@ -0,0 +1,120 @@
+package rbac
+
+import "sort"
+
+// Permissions - สิทธิ์ที่ role ถือได้ (method กำหนดสิทธิ์ที่ต้องมีด้วย option (authz.authz) ใน proto)
+const (
+	// PermAll - ทุกสิทธิ์ (role admin)
+	PermAll = "*"
+
+	// PermAccountSelf - จัดการบัญชีของตัวเอง (sessions, MFA, passkeys, รหัสผ่าน)
+	PermAccountSelf = "account:self"
+
+	PermUsersRead  = "users:read"
+	PermUsersWrite = "users:write"
+
+	// PermUsersAdmin - เปลี่ยน role, ระงับ/เปิดใช้งาน และกู้คืนบัญชีของ user อื่น (AdminService)
+	PermUsersAdmin = "users:admin"
+
+	// PermSessionsManage - ดูและยกเลิก sessions ของ user อื่น
+	PermSessionsManage = "sessions:manage"
+	PermAccountsUnlock = "accounts:unlock"
+	PermKeysRotate     = "keys:rotate"
+
+	PermRolesRead   = "roles:read"
+	PermRolesManage = "roles:manage"
+
+	// PermGroupsManage - จัดการ groups ใน tenant (กำหนด roles ให้ group ต้องมี users:admin ด้วย)
+	PermGroupsRead   = "groups:read"
+	PermGroupsManage = "groups:manage"
+
+	// PermTenantsManage - ดูและสร้าง tenants (ใช้ได้จาก tenant เริ่มต้นเท่านั้น)
+	PermTenantsManage = "tenants:manage"
+)
+
+// KnownPermissions - สิทธิ์ทั้งหมดที่กำหนดให้ role ได้
+var KnownPermissions = []string{
+	PermAll,
+	PermAccountSelf,
+	PermUsersRead,
+	PermUsersWrite,
+	PermUsersAdmin,
+	PermSessionsManage,
+	PermAccountsUnlock,
+	PermKeysRotate,
+	PermRolesRead,
+	PermRolesManage,
+	PermGroupsRead,
+	PermGroupsManage,
+	PermTenantsManage,
+}
+
+// globalPermissions - สิทธิ์ที่มีผลกับทุก tenant (signing keys, roles และ tenants ใช้ร่วมกัน)
+var globalPermissions = []string{
+	PermAll,
+	PermKeysRotate,
+	PermRolesManage,
+	PermTenantsManage,
+}
+
+// tenantAdminPermissions - สิทธิ์ของ role tenant_admin (ทุกสิทธิ์ยกเว้น globalPermissions)
+var tenantAdminPermissions = []string{
+	PermAccountSelf,
+	PermUsersRead,
+	PermUsersWrite,
+	PermUsersAdmin,
+	PermSessionsManage,
+	PermAccountsUnlock,
+	PermRolesRead,
+	PermGroupsRead,
+	PermGroupsManage,
+}
+
+// IsKnownPermission - ตรวจสอบว่าเป็นสิทธิ์ที่รู้จักหรือไม่
+func IsKnownPermission(permission string) bool {
+	for _, p := range KnownPermissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// PermissionSet - สิทธิ์ของ role หนึ่ง
+type PermissionSet map[string]bool
+
+// NewPermissionSet - สร้างจากรายการสิทธิ์
+func NewPermissionSet(permissions []string) PermissionSet {
+	set := make(PermissionSet, len(permissions))
+	for _, p := range permissions {
+		set[p] = true
+	}
+	return set
+}
+
+// Has - มีสิทธิ์นี้หรือไม่ ("*" มีทุกสิทธิ์)
+func (s PermissionSet) Has(permission string) bool {
+	return s[PermAll] || s[permission]
+}
+
+// HasGlobal - มีสิทธิ์ที่มีผลกับทุก tenant หรือไม่ (ให้ได้เฉพาะ user ของ tenant เริ่มต้น)
+func (s PermissionSet) HasGlobal() bool {
+	for _, p := range globalPermissions {
+		if s[p] {
+			return true
+		}
+	}
+	return false
+}
+
+// List - สิทธิ์ทั้งหมดเรียงตามตัวอักษร
+func (s PermissionSet) List() []string {
+	permissions := make([]string, 0, len(s))
+	for p, ok := range s {
+		if ok {
+			permissions = append(permissions, p)
+		}
+	}
+	sort.Strings(permissions)
+	return permissions
+}
@@ -0,0 +1,261 @@
+package rbac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"auth-microservice/pkg/db"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	// ErrRoleNotFound - ไม่พบ role
+	ErrRoleNotFound = errors.New("role not found")
+	// ErrRoleExists - มี role ชื่อนี้แล้ว
+	ErrRoleExists = errors.New("role already exists")
+	// ErrBuiltinRole - role ที่ระบบสร้างไว้ลบไม่ได้
+	ErrBuiltinRole = errors.New("built-in roles cannot be deleted")
+	// ErrRoleInUse - ยังมี user หรือ group ที่ใช้ role นี้อยู่
+	ErrRoleInUse = errors.New("role is assigned to users or groups")
+)
+
+// Role names ที่ระบบสร้างไว้
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+
+	// RoleTenantAdmin - ผู้ดูแลของ tenant (ไม่มีสิทธิ์ที่กระทบทุก tenant)
+	RoleTenantAdmin = "tenant_admin"
+)
+
+// Role - role และสิทธิ์ (collection roles, _id คือชื่อ role)
+type Role struct {
+	Name        string    `bson:"_id"`
+	Description string    `bson:"description"`
+	Permissions []string  `bson:"permissions"`
+	Builtin     bool      `bson:"builtin"`
+	CreatedAt   time.Time `bson:"created_at"`
+	UpdatedAt   time.Time `bson:"updated_at"`
+}
+
+// defaultRoles - role ที่สร้างให้ตอนเริ่มระบบครั้งแรก
+var defaultRoles = []Role{
+	{Name: RoleAdmin, Description: "Full access", Permissions: []string{PermAll}, Builtin: true},
+	{Name: RoleUser, Description: "Manage own account", Permissions: []string{PermAccountSelf}, Builtin: true},
+	{Name: RoleTenantAdmin, Description: "Manage users and groups of the tenant", Permissions: tenantAdminPermissions, Builtin: true},
+}
+
+// Store - roles ใน MongoDB พร้อม cache สิทธิ์ในหน่วยความจำ
+// การแก้ไขจาก replica อื่นมีผลภายใน cacheTTL
+type Store struct {
+	db       *db.MongoDB
+	cacheTTL time.Duration
+
+	mu       sync.RWMutex
+	cache    map[string]PermissionSet
+	loadedAt time.Time
+}
+
+func NewStore(database *db.MongoDB, cacheTTL time.Duration) *Store {
+	return &Store{
+		db:       database,
+		cacheTTL: cacheTTL,
+	}
+}
+
+// EnsureDefaults - สร้าง role admin และ user ถ้ายังไม่มี (ไม่แก้ role ที่มีอยู่แล้ว)
+func (s *Store) EnsureDefaults(ctx context.Context) error {
+	now := time.Now()
+	for _, role := range defaultRoles {
+		role.CreatedAt = now
+		role.UpdatedAt = now
+
+		_, err := s.db.Roles().UpdateOne(ctx,
+			bson.M{"_id": role.Name},
+			bson.M{"$setOnInsert": role},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to ensure role %s: %w", role.Name, err)
+		}
+	}
+	return nil
+}
+
+// Permissions - สิทธิ์ของ role (role ที่ไม่มีอยู่ = ไม่มีสิทธิ์)
+func (s *Store) Permissions(ctx context.Context, role string) (PermissionSet, error) {
+	s.mu.RLock()
+	fresh := s.cache != nil && time.Since(s.loadedAt) < s.cacheTTL
+	permissions := s.cache[role]
+	s.mu.RUnlock()
+
+	if fresh {
+		return permissions, nil
+	}
+
+	if err := s.reload(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cache[role], nil
+}
+
+// PermissionsOf - สิทธิ์รวมของหลาย role (role หลักของ user และ roles ที่ได้จาก groups)
+func (s *Store) PermissionsOf(ctx context.Context, roles ...string) (PermissionSet, error) {
+	merged := make(PermissionSet)
+	for _, role := range roles {
+		permissions, err := s.Permissions(ctx, role)
+		if err != nil {
+			return nil, err
+		}
+		for p := range permissions {
+			merged[p] = true
+		}
+	}
+	return merged, nil
+}
+
+// List - role ทั้งหมดเรียงตามชื่อ
+func (s *Store) List(ctx context.Context) ([]Role, error) {
+	cursor, err := s.db.Roles().Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var roles []Role
+	if err := cursor.All(ctx, &roles); err != nil {
+		return nil, fmt.Errorf("failed to decode roles: %w", err)
+	}
+	return roles, nil
+}
+
+// Get - ดึง role ตามชื่อ
+func (s *Store) Get(ctx context.Context, name string) (*Role, error) {
+	var role Role
+	err := s.db.Roles().FindOne(ctx, bson.M{"_id": name}).Decode(&role)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrRoleNotFound
+		}
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	return &role, nil
+}
+
+// Create - สร้าง role ใหม่
+func (s *Store) Create(ctx context.Context, name, description string, permissions []string) (*Role, error) {
+	now := time.Now()
+	role := &Role{
+		Name:        name,
+		Description: description,
+		Permissions: permissions,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if _, err := s.db.Roles().InsertOne(ctx, role); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrRoleExists
+		}
+		return nil, fmt.Errorf("failed to create role: %w", err)
+	}
+
+	s.invalidate()
+	return role, nil
+}
+
+// Update - แก้ไขคำอธิบายและสิทธิ์ของ role
+func (s *Store) Update(ctx context.Context, name, description string, permissions []string) (*Role, error) {
+	var role Role
+	err := s.db.Roles().FindOneAndUpdate(ctx,
+		bson.M{"_id": name},
+		bson.M{"$set": bson.M{
+			"description": description,
+			"permissions": permissions,
+			"updated_at":  time.Now(),
+		}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&role)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrRoleNotFound
+		}
+		return nil, fmt.Errorf("failed to update role: %w", err)
+	}
+
+	s.invalidate()
+	return &role, nil
+}
+
+// Delete - ลบ role (ลบไม่ได้ถ้าเป็น built-in หรือยังมี user หรือ group ใช้อยู่)
+func (s *Store) Delete(ctx context.Context, name string) error {
+	role, err := s.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	if role.Builtin {
+		return ErrBuiltinRole
+	}
+
+	inUse, err := s.db.Users().CountDocuments(ctx, bson.M{"role": name}, options.Count().SetLimit(1))
+	if err != nil {
+		return fmt.Errorf("failed to check role usage: %w", err)
+	}
+	if inUse > 0 {
+		return ErrRoleInUse
+	}
+
+	inUse, err = s.db.Groups().CountDocuments(ctx, bson.M{"roles": name}, options.Count().SetLimit(1))
+	if err != nil {
+		return fmt.Errorf("failed to check role usage: %w", err)
+	}
+	if inUse > 0 {
+		return ErrRoleInUse
+	}
+
+	result, err := s.db.Roles().DeleteOne(ctx, bson.M{"_id": name, "builtin": bson.M{"$ne": true}})
+	if err != nil {
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return ErrRoleNotFound
+	}
+
+	s.invalidate()
+	return nil
+}
+
+// reload - โหลดสิทธิ์ของทุก role เข้า cache
+func (s *Store) reload(ctx context.Context) error {
+	roles, err := s.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	cache := make(map[string]PermissionSet, len(roles))
+	for _, role := range roles {
+		cache[role.Name] = NewPermissionSet(role.Permissions)
+	}
+
+	s.mu.Lock()
+	s.cache = cache
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// invalidate - ให้โหลดสิทธิ์ใหม่ในครั้งถัดไป
+func (s *Store) invalidate() {
+	s.mu.Lock()
+	s.cache = nil
+	s.mu.Unlock()
+}
@@ -0,0 +1,65 @@
+package revocation
+
+import (
+	"sync"
+	"time"
+)
+
+// cacheEntry - ค่าที่เก็บไว้ใน memory พร้อมเวลาหมดอายุ
+type cacheEntry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// ttlCache - cache แบบมีวันหมดอายุสำหรับผลการตรวจสอบ revocation
+type ttlCache[V any] struct {
+	mu        sync.RWMutex
+	entries   map[string]cacheEntry[V]
+	lastSweep time.Time
+}
+
+func newTTLCache[V any]() *ttlCache[V] {
+	return &ttlCache[V]{
+		entries:   make(map[string]cacheEntry[V]),
+		lastSweep: time.Now(),
+	}
+}
+
+// get - ดึงค่าจาก cache (ok = false ถ้าไม่มีหรือหมดอายุแล้ว)
+func (c *ttlCache[V]) get(key string) (value V, ok bool) {
+	c.mu.RLock()
+	entry, found := c.entries[key]
+	c.mu.RUnlock()
+
+	if !found || time.Now().After(entry.expiresAt) {
+		var zero V
+		return zero, false
+	}
+	return entry.value, true
+}
+
+// set - บันทึกค่าลง cache พร้อมเวลาหมดอายุ
+func (c *ttlCache[V]) set(key string, value V, expiresAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = cacheEntry[V]{value: value, expiresAt: expiresAt}
+
+	// ล้าง entry ที่หมดอายุเป็นระยะ เพื่อไม่ให้ map โตไม่สิ้นสุด
+	now := time.Now()
+	if now.Sub(c.lastSweep) > time.Minute {
+		for k, e := range c.entries {
+			if now.After(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		c.lastSweep = now
+	}
+}
+
+// delete - ลบค่าออกจาก cache
+func (c *ttlCache[V]) delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
@@ -0,0 +1,234 @@
+package revocation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"auth-microservice/pkg/db"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Store - ตรวจสอบและบันทึก token ที่ถูกยกเลิก (blacklisted_tokens) และ token epoch ของ user
+//
+// ผลการตรวจสอบจะถูก cache ไว้ใน memory:
+//   - token ที่ถูกยกเลิกแล้ว cache จนกว่า token จะหมดอายุ (การยกเลิกไม่มีวันย้อนกลับ)
+//   - token ที่ยังใช้ได้ cache แค่ช่วงสั้นๆ (cacheTTL) เพื่อให้ replica อื่นเห็นการ logout
+//     ภายในเวลาไม่เกิน cacheTTL แม้ MongoDB จะไม่รองรับ change streams
+//   - token_version ของ user และสถานะของ session cache ไม่เกิน cacheTTL เช่นกัน
+type Store struct {
+	db           *db.MongoDB
+	cache        *ttlCache[bool]
+	userVersions *ttlCache[int64]
+	sessions     *ttlCache[bool]
+	cacheTTL     time.Duration
+}
+
+// inactiveUserVersion - ค่าใน cache สำหรับ user ที่ถูกปิดใช้งานหรือถูกลบ
+const inactiveUserVersion = -1
+
+func NewStore(database *db.MongoDB, cacheTTL time.Duration) *Store {
+	return &Store{
+		db:           database,
+		cache:        newTTLCache[bool](),
+		userVersions: newTTLCache[int64](),
+		sessions:     newTTLCache[bool](),
+		cacheTTL:     cacheTTL,
+	}
+}
+
+// HashToken - แปลง token เป็น SHA-256 hex เพื่อไม่ต้องเก็บ token จริงในฐานข้อมูล
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Revoke - เพิ่ม token ลงใน blacklist จนกว่าจะหมดอายุ
+func (s *Store) Revoke(ctx context.Context, token string, expiresAt time.Time) error {
+	tokenHash := HashToken(token)
+
+	filter := bson.M{"token_hash": tokenHash}
+	update := bson.M{
+		"$setOnInsert": bson.M{
+			"token_hash": tokenHash,
+			"expires_at": expiresAt,
+			"created_at": time.Now(),
+		},
+	}
+
+	_, err := s.db.BlacklistedTokens().UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to blacklist token: %w", err)
+	}
+
+	s.cache.set(tokenHash, true, expiresAt)
+	return nil
+}
+
+// Consume - ยกเลิก token ที่ใช้ได้ครั้งเดียว (atomic ด้วย unique index ของ token_hash)
+// คืน false ถ้า token ถูกใช้หรือถูกยกเลิกไปก่อนแล้ว - request ที่มาพร้อมกันจะมีเพียงตัวเดียวที่ได้ true
+func (s *Store) Consume(ctx context.Context, token string, expiresAt time.Time) (bool, error) {
+	tokenHash := HashToken(token)
+
+	_, err := s.db.BlacklistedTokens().InsertOne(ctx, bson.M{
+		"token_hash": tokenHash,
+		"expires_at": expiresAt,
+		"created_at": time.Now(),
+	})
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return false, fmt.Errorf("failed to consume token: %w", err)
+	}
+
+	s.cache.set(tokenHash, true, expiresAt)
+	return err == nil, nil
+}
+
+// IsRevoked - ตรวจสอบว่า token ถูกยกเลิกแล้วหรือไม่ (ใช้ cache ก่อนเสมอ)
+func (s *Store) IsRevoked(ctx context.Context, token string) (bool, error) {
+	tokenHash := HashToken(token)
+
+	if revoked, ok := s.cache.get(tokenHash); ok {
+		return revoked, nil
+	}
+
+	var entry struct {
+		ExpiresAt time.Time `bson:"expires_at"`
+	}
+	err := s.db.BlacklistedTokens().FindOne(ctx, bson.M{"token_hash": tokenHash}).Decode(&entry)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			s.cache.set(tokenHash, false, time.Now().Add(s.cacheTTL))
+			return false, nil
+		}
+		return false, fmt.Errorf("database error: %w", err)
+	}
+
+	s.cache.set(tokenHash, true, entry.ExpiresAt)
+	return true, nil
+}
+
+// IsUserRevoked - ตรวจสอบว่า token ที่มี token_version นี้ถูกยกเลิกจากการ revoke ทุก session
+// ของ user หรือไม่ (user ที่ถูกปิดใช้งาน/ลบจะถือว่าถูกยกเลิกทั้งหมด)
+func (s *Store) IsUserRevoked(ctx context.Context, userID string, tokenVersion int64) (bool, error) {
+	current, ok := s.userVersions.get(userID)
+	if !ok {
+		var err error
+		current, err = s.loadUserVersion(ctx, userID)
+		if err != nil {
+			return false, err
+		}
+		s.userVersions.set(userID, current, time.Now().Add(s.cacheTTL))
+	}
+
+	if current == inactiveUserVersion {
+		return true, nil
+	}
+	return tokenVersion < current, nil
+}
+
+// ForgetUser - ลบ token_version ของ user ออกจาก cache (เรียกหลังเปลี่ยน token_version)
+func (s *Store) ForgetUser(userID string) {
+	s.userVersions.delete(userID)
+}
+
+func (s *Store) loadUserVersion(ctx context.Context, userID string) (int64, error) {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return inactiveUserVersion, nil
+	}
+
+	filter := bson.M{
+		"_id":        objectID,
+		"is_active":  true,
+		"deleted_at": bson.M{"$exists": false},
+	}
+	opts := options.FindOne().SetProjection(bson.M{"token_version": 1})
+
+	var user struct {
+		TokenVersion int64 `bson:"token_version"`
+	}
+	err = s.db.Users().FindOne(ctx, filter, opts).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return inactiveUserVersion, nil
+		}
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+
+	return user.TokenVersion, nil
+}
+
+// IsSessionRevoked - ตรวจสอบว่า session ถูกยกเลิกหรือหมดอายุแล้วหรือไม่
+func (s *Store) IsSessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	if revoked, ok := s.sessions.get(sessionID); ok {
+		return revoked, nil
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return true, nil
+	}
+
+	filter := bson.M{
+		"_id":        objectID,
+		"revoked_at": bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": time.Now()},
+	}
+	count, err := s.db.Sessions().CountDocuments(ctx, filter)
+	if err != nil {
+		return false, fmt.Errorf("database error: %w", err)
+	}
+
+	revoked := count == 0
+	s.sessions.set(sessionID, revoked, time.Now().Add(s.cacheTTL))
+	return revoked, nil
+}
+
+// MarkSessionRevoked - บันทึกใน cache ว่า session ถูกยกเลิก (replica อื่นจะเห็นภายใน cacheTTL)
+func (s *Store) MarkSessionRevoked(sessionID string) {
+	s.sessions.set(sessionID, true, time.Now().Add(24*time.Hour))
+}
+
+// Watch - ติดตาม blacklisted_tokens ผ่าน change stream เพื่อให้ cache ของทุก replica
+// เห็นการ logout ทันที ถ้า MongoDB ไม่ใช่ replica set จะใช้ cacheTTL แทน
+func (s *Store) Watch(ctx context.Context) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"operationType": "insert"}}},
+	}
+
+	stream, err := s.db.BlacklistedTokens().Watch(ctx, pipeline)
+	if err != nil {
+		log.Printf("⚠️  Revocation change stream unavailable, relying on cache TTL (%v): %v", s.cacheTTL, err)
+		return
+	}
+	defer stream.Close(ctx)
+
+	log.Println("👀 Watching blacklisted_tokens for revocations")
+
+	for stream.Next(ctx) {
+		var event struct {
+			FullDocument struct {
+				TokenHash string    `bson:"token_hash"`
+				ExpiresAt time.Time `bson:"expires_at"`
+			} `bson:"fullDocument"`
+		}
+		if err := stream.Decode(&event); err != nil {
+			log.Printf("⚠️  Failed to decode revocation event: %v", err)
+			continue
+		}
+		if event.FullDocument.TokenHash != "" {
+			s.cache.set(event.FullDocument.TokenHash, true, event.FullDocument.ExpiresAt)
+		}
+	}
+
+	if err := stream.Err(); err != nil && ctx.Err() == nil {
+		log.Printf("⚠️  Revocation change stream stopped: %v", err)
+	}
+}
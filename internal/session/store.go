@@ -0,0 +1,191 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"auth-microservice/pkg/db"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrNotFound - ไม่พบ session (หรือถูกยกเลิกไปแล้ว)
+var ErrNotFound = errors.New("session not found")
+
+// Session - การ login หนึ่งครั้งบนอุปกรณ์หนึ่ง (ID เดียวกับ refresh token family)
+type Session struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	UserID     string             `bson:"user_id"`
+	IPAddress  string             `bson:"ip_address"`
+	UserAgent  string             `bson:"user_agent"`
+	CreatedAt  time.Time          `bson:"created_at"`
+	LastSeenAt time.Time          `bson:"last_seen_at"`
+	ExpiresAt  time.Time          `bson:"expires_at"`
+	RevokedAt  *time.Time         `bson:"revoked_at,omitempty"`
+}
+
+// Store - session registry (collection sessions)
+type Store struct {
+	db            *db.MongoDB
+	touchInterval time.Duration
+
+	mu        sync.Mutex
+	lastTouch map[string]time.Time
+}
+
+// NewStore - touchInterval คือระยะเวลาขั้นต่ำระหว่างการอัพเดท last_seen_at ของ session เดียวกัน
+func NewStore(database *db.MongoDB, touchInterval time.Duration) *Store {
+	return &Store{
+		db:            database,
+		touchInterval: touchInterval,
+		lastTouch:     make(map[string]time.Time),
+	}
+}
+
+// Create - บันทึก session ใหม่
+func (s *Store) Create(ctx context.Context, userID, ipAddress, userAgent string, expiresAt time.Time) (*Session, error) {
+	now := time.Now()
+	session := &Session{
+		ID:         primitive.NewObjectID(),
+		UserID:     userID,
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  expiresAt,
+	}
+
+	if _, err := s.db.Sessions().InsertOne(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return session, nil
+}
+
+// Extend - ต่ออายุ session (เรียกตอน refresh token)
+func (s *Store) Extend(ctx context.Context, sessionID string, expiresAt time.Time) error {
+	objectID, err := primitive.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return fmt.Errorf("invalid session ID: %w", err)
+	}
+
+	filter := bson.M{"_id": objectID, "revoked_at": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{
+		"expires_at":   expiresAt,
+		"last_seen_at": time.Now(),
+	}}
+
+	result, err := s.db.Sessions().UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to extend session: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListByUser - รายการ session ที่ยังใช้งานได้ของ user (ใหม่สุดก่อน)
+func (s *Store) ListByUser(ctx context.Context, userID string) ([]*Session, error) {
+	filter := bson.M{
+		"user_id":    userID,
+		"revoked_at": bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": time.Now()},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "last_seen_at", Value: -1}})
+
+	cursor, err := s.db.Sessions().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find sessions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var sessions []*Session
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, fmt.Errorf("failed to decode sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// Revoke - ยกเลิก session ของ user
+func (s *Store) Revoke(ctx context.Context, userID, sessionID string) error {
+	objectID, err := primitive.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return fmt.Errorf("invalid session ID: %w", err)
+	}
+
+	filter := bson.M{
+		"_id":        objectID,
+		"user_id":    userID,
+		"revoked_at": bson.M{"$exists": false},
+	}
+	update := bson.M{"$set": bson.M{"revoked_at": time.Now()}}
+
+	result, err := s.db.Sessions().UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RevokeAllForUser - ยกเลิกทุก session ของ user
+func (s *Store) RevokeAllForUser(ctx context.Context, userID string) error {
+	filter := bson.M{
+		"user_id":    userID,
+		"revoked_at": bson.M{"$exists": false},
+	}
+	update := bson.M{"$set": bson.M{"revoked_at": time.Now()}}
+
+	if _, err := s.db.Sessions().UpdateMany(ctx, filter, update); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return nil
+}
+
+// Touch - อัพเดท last_seen_at แบบ throttle (ไม่เขียนฐานข้อมูลทุก request)
+func (s *Store) Touch(sessionID string) {
+	now := time.Now()
+
+	s.mu.Lock()
+	if last, ok := s.lastTouch[sessionID]; ok && now.Sub(last) < s.touchInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastTouch[sessionID] = now
+
+	// ล้าง entry เก่าเพื่อไม่ให้ map โตไม่สิ้นสุด
+	if len(s.lastTouch) > 10000 {
+		for id, last := range s.lastTouch {
+			if now.Sub(last) > s.touchInterval {
+				delete(s.lastTouch, id)
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	objectID, err := primitive.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return
+	}
+
+	// เขียนแบบ async เพื่อไม่ให้ request ต้องรอ
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		filter := bson.M{"_id": objectID}
+		update := bson.M{"$set": bson.M{"last_seen_at": now}}
+		if _, err := s.db.Sessions().UpdateOne(ctx, filter, update); err != nil {
+			log.Printf("⚠️  Failed to update session last seen: %v", err)
+		}
+	}()
+}
@@ -0,0 +1,126 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"auth-microservice/pkg/db"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	// ErrTenantNotFound - ไม่พบ tenant หรือ tenant ถูกปิดใช้งาน
+	ErrTenantNotFound = errors.New("tenant not found")
+	// ErrTenantExists - มี tenant ID นี้แล้ว
+	ErrTenantExists = errors.New("tenant already exists")
+)
+
+// Tenant - องค์กร/product ที่ใช้ service นี้ (collection tenants, _id คือ tenant ID ที่ client ส่งมาตอน login)
+// user ทุกคนอยู่ใน tenant เดียว และ email ไม่ซ้ำกันภายใน tenant
+type Tenant struct {
+	ID        string    `bson:"_id"`
+	Name      string    `bson:"name"`
+	IsActive  bool      `bson:"is_active"`
+	CreatedAt time.Time `bson:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+// Store - tenants ใน MongoDB
+type Store struct {
+	db *db.MongoDB
+}
+
+func NewStore(database *db.MongoDB) *Store {
+	return &Store{
+		db: database,
+	}
+}
+
+// EnsureDefault - สร้าง tenant เริ่มต้นถ้ายังไม่มี และย้าย user เดิมที่ยังไม่มี tenant เข้า tenant นี้
+func (s *Store) EnsureDefault(ctx context.Context, id, name string) (int64, error) {
+	now := time.Now()
+	_, err := s.db.Tenants().UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$setOnInsert": Tenant{
+			ID:        id,
+			Name:      name,
+			IsActive:  true,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to ensure tenant %s: %w", id, err)
+	}
+
+	result, err := s.db.Users().UpdateMany(ctx,
+		bson.M{"tenant_id": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"tenant_id": id}},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to assign users to tenant %s: %w", id, err)
+	}
+	return result.ModifiedCount, nil
+}
+
+// Get - ดึง tenant ที่เปิดใช้งานอยู่
+func (s *Store) Get(ctx context.Context, id string) (*Tenant, error) {
+	var tenant Tenant
+	err := s.db.Tenants().FindOne(ctx, bson.M{"_id": id, "is_active": true}).Decode(&tenant)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrTenantNotFound
+		}
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	return &tenant, nil
+}
+
+// List - tenant ทั้งหมดเรียงตาม ID
+func (s *Store) List(ctx context.Context) ([]Tenant, error) {
+	cursor, err := s.db.Tenants().Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var tenants []Tenant
+	if err := cursor.All(ctx, &tenants); err != nil {
+		return nil, fmt.Errorf("failed to decode tenants: %w", err)
+	}
+	return tenants, nil
+}
+
+// Create - สร้าง tenant ใหม่
+func (s *Store) Create(ctx context.Context, id, name string) (*Tenant, error) {
+	now := time.Now()
+	tenant := &Tenant{
+		ID:        id,
+		Name:      name,
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if _, err := s.db.Tenants().InsertOne(ctx, tenant); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrTenantExists
+		}
+		return nil, fmt.Errorf("failed to create tenant: %w", err)
+	}
+	return tenant, nil
+}
+
+// Delete - ลบ tenant (ใช้ยกเลิกการสร้างเมื่อสร้างผู้ดูแลคนแรกไม่สำเร็จ)
+func (s *Store) Delete(ctx context.Context, id string) error {
+	if _, err := s.db.Tenants().DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+		return fmt.Errorf("failed to delete tenant: %w", err)
+	}
+	return nil
+}
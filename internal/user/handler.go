@@ -2,21 +2,34 @@ package user
 
 import (
 	"context"
+	"errors"
 	"log"
 
+	"auth-microservice/internal/middleware"
+	"auth-microservice/internal/rbac"
 	"auth-microservice/proto/user"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// AccountDeleter - ลบบัญชีพร้อมยกเลิก sessions และ refresh tokens (auth.Service)
+type AccountDeleter interface {
+	DeleteUser(ctx context.Context, actorID, userID string) error
+}
+
 // Handler - gRPC handler สำหรับ User Management
 type Handler struct {
 	user.UnimplementedUserServiceServer
 	repository *Repository
+	accounts   AccountDeleter
 }
 
 // NewHandler - สร้าง user handler ใหม่
-func NewHandler(repository *Repository) *Handler {
+func NewHandler(repository *Repository, accounts AccountDeleter) *Handler {
 	return &Handler{
 		repository: repository,
+		accounts:   accounts,
 	}
 }
 
@@ -79,12 +92,31 @@ func (h *Handler) ListUsers(ctx context.Context, req *user.ListUsersRequest) (*u
 	return response, nil
 }
 
-// GetProfile - gRPC handler สำหรับดึงข้อมูล user profile
+// GetProfile - gRPC handler สำหรับดึงข้อมูล user profile (ตัวเอง หรือผู้มีสิทธิ์ users:read)
 func (h *Handler) GetProfile(ctx context.Context, req *user.GetProfileRequest) (*user.GetProfileResponse, error) {
 	log.Printf("👤 GetProfile request for ID: %s", req.UserId)
 
+	if err := authorizeProfileAccess(ctx, req.UserId, rbac.PermUsersRead); err != nil {
+		return nil, err
+	}
+
+	return h.getProfile(ctx, req.UserId)
+}
+
+// GetMe - gRPC handler สำหรับดึงข้อมูล profile ของผู้เรียกเอง
+func (h *Handler) GetMe(ctx context.Context, req *user.GetMeRequest) (*user.GetProfileResponse, error) {
+	principal, ok := middleware.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "Authentication required")
+	}
+	log.Printf("👤 GetMe request for ID: %s", principal.UserID)
+
+	return h.getProfile(ctx, principal.UserID)
+}
+
+func (h *Handler) getProfile(ctx context.Context, userID string) (*user.GetProfileResponse, error) {
 	// หา user ตาม ID
-	userData, err := h.repository.GetByID(ctx, req.UserId)
+	userData, err := h.repository.GetByID(ctx, userID)
 	if err != nil {
 		log.Printf("❌ GetProfile repository error: %v", err)
 		return &user.GetProfileResponse{
@@ -113,12 +145,31 @@ func (h *Handler) GetProfile(ctx context.Context, req *user.GetProfileRequest) (
 	return response, nil
 }
 
-// UpdateProfile - gRPC handler สำหรับอัพเดท user profile
+// UpdateProfile - gRPC handler สำหรับอัพเดท user profile (ตัวเอง หรือผู้มีสิทธิ์ users:write)
 func (h *Handler) UpdateProfile(ctx context.Context, req *user.UpdateProfileRequest) (*user.UpdateProfileResponse, error) {
 	log.Printf("✏️ UpdateProfile request for ID: %s", req.UserId)
 
+	if err := authorizeProfileAccess(ctx, req.UserId, rbac.PermUsersWrite); err != nil {
+		return nil, err
+	}
+
+	return h.updateProfile(ctx, req.UserId, req.FirstName, req.LastName, req.Email)
+}
+
+// UpdateMe - gRPC handler สำหรับอัพเดท profile ของผู้เรียกเอง
+func (h *Handler) UpdateMe(ctx context.Context, req *user.UpdateMeRequest) (*user.UpdateProfileResponse, error) {
+	principal, ok := middleware.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "Authentication required")
+	}
+	log.Printf("✏️ UpdateMe request for ID: %s", principal.UserID)
+
+	return h.updateProfile(ctx, principal.UserID, req.FirstName, req.LastName, req.Email)
+}
+
+func (h *Handler) updateProfile(ctx context.Context, userID, firstName, lastName, email string) (*user.UpdateProfileResponse, error) {
 	// หา user ที่จะอัพเดท
-	userData, err := h.repository.GetByID(ctx, req.UserId)
+	userData, err := h.repository.GetByID(ctx, userID)
 	if err != nil {
 		log.Printf("❌ UpdateProfile - user not found: %v", err)
 		return &user.UpdateProfileResponse{
@@ -128,18 +179,27 @@ func (h *Handler) UpdateProfile(ctx context.Context, req *user.UpdateProfileRequ
 	}
 
 	// อัพเดทข้อมูล
-	if req.FirstName != "" {
-		userData.FirstName = req.FirstName
+	if firstName != "" {
+		userData.FirstName = firstName
 	}
-	if req.LastName != "" {
-		userData.LastName = req.LastName
+	if lastName != "" {
+		userData.LastName = lastName
 	}
-	if req.Email != "" {
-		userData.Email = req.Email
+	if email != "" && email != userData.Email {
+		// email ใหม่ต้องยืนยันอีกครั้ง (ResendVerification)
+		userData.Email = email
+		userData.EmailVerified = false
 	}
 
 	// บันทึกการเปลี่ยนแปลง
 	err = h.repository.Update(ctx, userData)
+	if errors.Is(err, ErrEmailExists) {
+		log.Printf("❌ UpdateProfile - email already exists: %s", userData.Email)
+		return &user.UpdateProfileResponse{
+			Success: false,
+			Message: "Email already registered",
+		}, nil
+	}
 	if err != nil {
 		log.Printf("❌ UpdateProfile repository error: %v", err)
 		return &user.UpdateProfileResponse{
@@ -157,12 +217,17 @@ func (h *Handler) UpdateProfile(ctx context.Context, req *user.UpdateProfileRequ
 	return response, nil
 }
 
-// DeleteProfile - gRPC handler สำหรับลบ user profile (soft delete)
+// DeleteProfile - gRPC handler สำหรับลบ user profile (soft delete, ตัวเอง หรือผู้มีสิทธิ์ users:write)
 func (h *Handler) DeleteProfile(ctx context.Context, req *user.DeleteProfileRequest) (*user.DeleteProfileResponse, error) {
 	log.Printf("🗑️ DeleteProfile request for ID: %s", req.UserId)
 
-	// ลบ user (soft delete)
-	err := h.repository.SoftDelete(ctx, req.UserId)
+	if err := authorizeProfileAccess(ctx, req.UserId, rbac.PermUsersWrite); err != nil {
+		return nil, err
+	}
+
+	// ลบ user (soft delete) และยกเลิกทุก session
+	actorID, _ := middleware.UserIDFromContext(ctx)
+	err := h.accounts.DeleteUser(ctx, actorID, req.UserId)
 	if err != nil {
 		log.Printf("❌ DeleteProfile repository error: %v", err)
 		return &user.DeleteProfileResponse{
@@ -179,3 +244,17 @@ func (h *Handler) DeleteProfile(ctx context.Context, req *user.DeleteProfileRequ
 	log.Printf("✅ DeleteProfile successful for ID: %s", req.UserId)
 	return response, nil
 }
+
+// authorizeProfileAccess - อนุญาตเฉพาะเจ้าของบัญชี หรือผู้มีสิทธิ์ permission กับ user อื่น
+func authorizeProfileAccess(ctx context.Context, userID, permission string) error {
+	principal, ok := middleware.PrincipalFromContext(ctx)
+	if !ok {
+		return status.Errorf(codes.Unauthenticated, "Authentication required")
+	}
+
+	if !principal.CanActOn(userID, permission) {
+		log.Printf("❌ Access denied - %s is not allowed to access profile %s", principal.UserID, userID)
+		return status.Errorf(codes.PermissionDenied, "Permission denied: %s required", permission)
+	}
+	return nil
+}
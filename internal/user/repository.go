@@ -2,9 +2,11 @@ package user
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"auth-microservice/internal/middleware"
 	"auth-microservice/internal/models"
 	"auth-microservice/pkg/db"
 
@@ -14,6 +16,10 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// ErrEmailExists - email ซ้ำกับบัญชีที่ใช้งานอยู่ใน tenant เดียวกัน (unique index)
+var ErrEmailExists = errors.New("email already exists")
+
+// Repository - user ของ tenant ผู้เรียก (tenant จาก middleware.TenantIDFromContext)
 type Repository struct {
 	db *db.MongoDB
 }
@@ -26,6 +32,11 @@ func NewRepository(database *db.MongoDB) *Repository {
 
 // GetByEmail - หา user ตาม email
 func (r *Repository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	coll, err := r.users(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	var user models.User
 
 	filter := bson.M{
@@ -34,7 +45,7 @@ func (r *Repository) GetByEmail(ctx context.Context, email string) (*models.User
 		"deleted_at": bson.M{"$exists": false},
 	}
 
-	err := r.db.Users().FindOne(ctx, filter).Decode(&user)
+	err = coll.FindOne(ctx, filter).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, fmt.Errorf("user not found")
@@ -47,6 +58,11 @@ func (r *Repository) GetByEmail(ctx context.Context, email string) (*models.User
 
 // GetByID - หา user ตาม ID
 func (r *Repository) GetByID(ctx context.Context, id string) (*models.User, error) {
+	coll, err := r.users(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, fmt.Errorf("invalid user ID: %w", err)
@@ -59,7 +75,31 @@ func (r *Repository) GetByID(ctx context.Context, id string) (*models.User, erro
 		"deleted_at": bson.M{"$exists": false},
 	}
 
-	err = r.db.Users().FindOne(ctx, filter).Decode(&user)
+	err = coll.FindOne(ctx, filter).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return &user, nil
+}
+
+// GetByIDAnyState - หา user ตาม ID รวมถึงบัญชีที่ถูกระงับหรือถูกลบ (สำหรับ AdminService)
+func (r *Repository) GetByIDAnyState(ctx context.Context, id string) (*models.User, error) {
+	coll, err := r.users(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	var user models.User
+	err = coll.FindOne(ctx, bson.M{"_id": objectID}).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, fmt.Errorf("user not found")
@@ -72,15 +112,20 @@ func (r *Repository) GetByID(ctx context.Context, id string) (*models.User, erro
 
 // Create - สร้าง user ใหม่
 func (r *Repository) Create(ctx context.Context, user *models.User) error {
+	coll, err := r.users(ctx)
+	if err != nil {
+		return err
+	}
+
 	user.ID = primitive.NewObjectID()
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
 	user.IsActive = true
 
-	_, err := r.db.Users().InsertOne(ctx, user)
+	_, err = coll.InsertOne(ctx, user)
 	if err != nil {
 		if mongo.IsDuplicateKeyError(err) {
-			return fmt.Errorf("email already exists")
+			return ErrEmailExists
 		}
 		return fmt.Errorf("failed to create user: %w", err)
 	}
@@ -90,20 +135,30 @@ func (r *Repository) Create(ctx context.Context, user *models.User) error {
 
 // Update - อัพเดท user
 func (r *Repository) Update(ctx context.Context, user *models.User) error {
+	coll, err := r.users(ctx)
+	if err != nil {
+		return err
+	}
+
 	user.UpdatedAt = time.Now()
 
 	filter := bson.M{"_id": user.ID}
 	update := bson.M{
 		"$set": bson.M{
-			"first_name": user.FirstName,
-			"last_name":  user.LastName,
-			"email":      user.Email,
-			"updated_at": user.UpdatedAt,
+			"first_name":     user.FirstName,
+			"last_name":      user.LastName,
+			"email":          user.Email,
+			"email_verified": user.EmailVerified,
+			"updated_at":     user.UpdatedAt,
 		},
 	}
 
-	result, err := r.db.Users().UpdateOne(ctx, filter, update)
+	result, err := coll.UpdateOne(ctx, filter, update)
 	if err != nil {
+		// email ใหม่ซ้ำกับบัญชีอื่นใน tenant เดียวกัน
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrEmailExists
+		}
 		return fmt.Errorf("failed to update user: %w", err)
 	}
 
@@ -114,8 +169,393 @@ func (r *Repository) Update(ctx context.Context, user *models.User) error {
 	return nil
 }
 
+// MarkEmailVerified - ยืนยัน email (เฉพาะเมื่อ email ยังเป็นค่าเดียวกับตอนออก token)
+func (r *Repository) MarkEmailVerified(ctx context.Context, id, email string) error {
+	coll, err := r.users(ctx)
+	if err != nil {
+		return err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	now := time.Now()
+	filter := bson.M{
+		"_id":        objectID,
+		"email":      email,
+		"deleted_at": bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"email_verified":    true,
+			"email_verified_at": now,
+			"updated_at":        now,
+		},
+	}
+
+	result, err := coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// UpdatePassword - เปลี่ยน password hash
+func (r *Repository) UpdatePassword(ctx context.Context, id, passwordHash string) error {
+	coll, err := r.users(ctx)
+	if err != nil {
+		return err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	filter := bson.M{"_id": objectID}
+	update := bson.M{
+		"$set": bson.M{
+			"password_hash": passwordHash,
+			"updated_at":    time.Now(),
+		},
+	}
+
+	result, err := coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// RehashPassword - แทน hash เดิมด้วย hash ใหม่ของรหัสผ่านเดียวกัน (ไม่ทำอะไรถ้ารหัสผ่านถูกเปลี่ยนไปแล้ว)
+func (r *Repository) RehashPassword(ctx context.Context, id, oldHash, newHash string) error {
+	coll, err := r.users(ctx)
+	if err != nil {
+		return err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	filter := bson.M{"_id": objectID, "password_hash": oldHash}
+	update := bson.M{
+		"$set": bson.M{
+			"password_hash": newHash,
+		},
+	}
+
+	if _, err := coll.UpdateOne(ctx, filter, update); err != nil {
+		return fmt.Errorf("failed to rehash password: %w", err)
+	}
+
+	return nil
+}
+
+// IncrementTokenVersion - เพิ่ม token_version เพื่อยกเลิก token ทั้งหมดที่ออกไปแล้ว
+func (r *Repository) IncrementTokenVersion(ctx context.Context, id string) error {
+	coll, err := r.users(ctx)
+	if err != nil {
+		return err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	filter := bson.M{"_id": objectID}
+	update := bson.M{
+		"$inc": bson.M{"token_version": 1},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+
+	result, err := coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update token version: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// RecordFailedLogin - เพิ่มจำนวน login ผิดติดต่อกัน และคืนค่าหลังเพิ่ม
+func (r *Repository) RecordFailedLogin(ctx context.Context, id string) (int, error) {
+	coll, err := r.users(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return 0, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	var user models.User
+	err = coll.FindOneAndUpdate(ctx,
+		bson.M{"_id": objectID},
+		bson.M{"$inc": bson.M{"failed_login_attempts": 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return 0, fmt.Errorf("user not found")
+		}
+		return 0, fmt.Errorf("failed to record failed login: %w", err)
+	}
+
+	return user.FailedLoginAttempts, nil
+}
+
+// Lock - ล็อคบัญชีชั่วคราวจนถึงเวลาที่กำหนด
+func (r *Repository) Lock(ctx context.Context, id string, until time.Time) error {
+	coll, err := r.users(ctx)
+	if err != nil {
+		return err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	filter := bson.M{"_id": objectID}
+	update := bson.M{
+		"$set": bson.M{
+			"locked_until": until,
+			"updated_at":   time.Now(),
+		},
+	}
+
+	result, err := coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to lock user: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// ResetFailedLogins - ล้างจำนวน login ผิดและปลดล็อคบัญชี
+func (r *Repository) ResetFailedLogins(ctx context.Context, id string) error {
+	coll, err := r.users(ctx)
+	if err != nil {
+		return err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	filter := bson.M{"_id": objectID}
+	update := bson.M{
+		"$set":   bson.M{"failed_login_attempts": 0},
+		"$unset": bson.M{"locked_until": ""},
+	}
+
+	result, err := coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to reset failed logins: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// SetPendingTOTP - เก็บ TOTP secret ที่รอการยืนยัน
+func (r *Repository) SetPendingTOTP(ctx context.Context, id, secret string) error {
+	coll, err := r.users(ctx)
+	if err != nil {
+		return err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	filter := bson.M{"_id": objectID}
+	update := bson.M{
+		"$set": bson.M{
+			"totp_pending_secret": secret,
+			"updated_at":          time.Now(),
+		},
+	}
+
+	result, err := coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update TOTP secret: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// EnableTOTP - เปิดใช้ TOTP ด้วย secret ที่ยืนยันแล้ว พร้อม recovery codes (hash)
+func (r *Repository) EnableTOTP(ctx context.Context, id, secret string, step int64, recoveryCodeHashes []string) error {
+	coll, err := r.users(ctx)
+	if err != nil {
+		return err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	filter := bson.M{
+		"_id":                 objectID,
+		"totp_pending_secret": secret,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"totp_enabled":   true,
+			"totp_secret":    secret,
+			"totp_last_step": step,
+			"recovery_codes": recoveryCodeHashes,
+			"updated_at":     time.Now(),
+		},
+		"$unset": bson.M{"totp_pending_secret": ""},
+	}
+
+	result, err := coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to enable TOTP: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// DisableTOTP - ปิด TOTP และลบ secret กับ recovery codes
+func (r *Repository) DisableTOTP(ctx context.Context, id string) error {
+	coll, err := r.users(ctx)
+	if err != nil {
+		return err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	filter := bson.M{"_id": objectID}
+	update := bson.M{
+		"$set": bson.M{
+			"totp_enabled": false,
+			"updated_at":   time.Now(),
+		},
+		"$unset": bson.M{
+			"totp_secret":         "",
+			"totp_pending_secret": "",
+			"totp_last_step":      "",
+			"recovery_codes":      "",
+		},
+	}
+
+	result, err := coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to disable TOTP: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// UseTOTPStep - บันทึก time step ของ code ที่ใช้แล้ว (false = code นี้หรือใหม่กว่าถูกใช้ไปแล้ว)
+func (r *Repository) UseTOTPStep(ctx context.Context, id string, step int64) (bool, error) {
+	coll, err := r.users(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	filter := bson.M{
+		"_id":            objectID,
+		"totp_last_step": bson.M{"$not": bson.M{"$gte": step}},
+	}
+	update := bson.M{"$set": bson.M{"totp_last_step": step}}
+
+	result, err := coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to update TOTP step: %w", err)
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// UseRecoveryCode - ใช้ recovery code (ลบ hash ออก) คืน false ถ้าไม่มี code นี้
+func (r *Repository) UseRecoveryCode(ctx context.Context, id, codeHash string) (bool, error) {
+	coll, err := r.users(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	filter := bson.M{
+		"_id":            objectID,
+		"recovery_codes": codeHash,
+	}
+	update := bson.M{
+		"$pull": bson.M{"recovery_codes": codeHash},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+
+	result, err := coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
 // SoftDelete - ลบ user แบบ soft delete
 func (r *Repository) SoftDelete(ctx context.Context, id string) error {
+	coll, err := r.users(ctx)
+	if err != nil {
+		return err
+	}
+
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return fmt.Errorf("invalid user ID: %w", err)
@@ -129,9 +569,10 @@ func (r *Repository) SoftDelete(ctx context.Context, id string) error {
 			"is_active":  false,
 			"updated_at": now,
 		},
+		"$inc": bson.M{"token_version": 1}, // ยกเลิกทุก session ของ user ที่ถูกลบ
 	}
 
-	result, err := r.db.Users().UpdateOne(ctx, filter, update)
+	result, err := coll.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
@@ -143,8 +584,263 @@ func (r *Repository) SoftDelete(ctx context.Context, id string) error {
 	return nil
 }
 
+// SetRole - เปลี่ยน role ของ user และเพิ่ม token_version (access token ที่มี role เดิมใช้ไม่ได้อีก)
+func (r *Repository) SetRole(ctx context.Context, id, role string) error {
+	coll, err := r.users(ctx)
+	if err != nil {
+		return err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	filter := bson.M{
+		"_id":        objectID,
+		"deleted_at": bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"role":       role,
+			"updated_at": time.Now(),
+		},
+		"$inc": bson.M{"token_version": 1},
+	}
+
+	result, err := coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to set role: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// Deactivate - ระงับบัญชีที่ใช้งานอยู่ พร้อมเหตุผลและผู้ระงับ
+func (r *Repository) Deactivate(ctx context.Context, id, reason, actorID string) error {
+	coll, err := r.users(ctx)
+	if err != nil {
+		return err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	now := time.Now()
+	filter := bson.M{
+		"_id":        objectID,
+		"is_active":  true,
+		"deleted_at": bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"is_active":           false,
+			"deactivated_at":      now,
+			"deactivated_by":      actorID,
+			"deactivation_reason": reason,
+			"updated_at":          now,
+		},
+		"$inc": bson.M{"token_version": 1}, // ยกเลิกทุก session ของ user ที่ถูกระงับ
+	}
+
+	result, err := coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate user: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// Reactivate - เปิดใช้งานบัญชีที่ถูกระงับ (ไม่รวมบัญชีที่ถูกลบ)
+func (r *Repository) Reactivate(ctx context.Context, id string) error {
+	coll, err := r.users(ctx)
+	if err != nil {
+		return err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	filter := bson.M{
+		"_id":        objectID,
+		"is_active":  false,
+		"deleted_at": bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"is_active":  true,
+			"updated_at": time.Now(),
+		},
+		"$unset": bson.M{
+			"deactivated_at":      "",
+			"deactivated_by":      "",
+			"deactivation_reason": "",
+		},
+	}
+
+	result, err := coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrEmailExists
+		}
+		return fmt.Errorf("failed to reactivate user: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// Restore - กู้คืนบัญชีที่ถูก SoftDelete (ถ้าเคยถูกระงับก่อนลบ จะยังถูกระงับอยู่)
+func (r *Repository) Restore(ctx context.Context, id string) error {
+	coll, err := r.users(ctx)
+	if err != nil {
+		return err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	// email อาจถูกใช้สมัครใหม่ระหว่างที่บัญชีถูกลบ
+	var deleted models.User
+	err = coll.FindOne(ctx, bson.M{
+		"_id":        objectID,
+		"deleted_at": bson.M{"$exists": true},
+	}).Decode(&deleted)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return fmt.Errorf("user not found")
+		}
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	taken, err := coll.CountDocuments(ctx, bson.M{
+		"_id":        bson.M{"$ne": objectID},
+		"email":      deleted.Email,
+		"deleted_at": bson.M{"$exists": false},
+	})
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if taken > 0 {
+		return ErrEmailExists
+	}
+
+	filter := bson.M{
+		"_id":        objectID,
+		"deleted_at": bson.M{"$exists": true},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"is_active":  deleted.DeactivatedAt == nil,
+			"updated_at": time.Now(),
+		},
+		"$unset": bson.M{"deleted_at": ""},
+	}
+
+	result, err := coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrEmailExists
+		}
+		return fmt.Errorf("failed to restore user: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// ListDeleted - รายการ user ที่ถูกลบ (เรียงจากลบล่าสุด)
+func (r *Repository) ListDeleted(ctx context.Context, skip, limit int) ([]*models.User, int64, error) {
+	coll, err := r.users(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	filter := bson.M{"deleted_at": bson.M{"$exists": true}}
+
+	total, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	cursor, err := coll.Find(ctx, filter, options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "deleted_at", Value: -1}}))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find users: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var users []*models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode users: %w", err)
+	}
+
+	return users, total, nil
+}
+
+// ListByIDs - users ที่ยังใช้งานได้ตาม IDs (เรียงตาม email, ID ที่ไม่ถูกต้องหรือไม่พบจะถูกข้าม)
+func (r *Repository) ListByIDs(ctx context.Context, ids []string) ([]*models.User, error) {
+	coll, err := r.users(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	objectIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+			objectIDs = append(objectIDs, objectID)
+		}
+	}
+
+	filter := bson.M{
+		"_id":        bson.M{"$in": objectIDs},
+		"is_active":  true,
+		"deleted_at": bson.M{"$exists": false},
+	}
+
+	cursor, err := coll.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "email", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find users: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var users []*models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, fmt.Errorf("failed to decode users: %w", err)
+	}
+
+	return users, nil
+}
+
 // List - แสดงรายการ users พร้อม filtering และ pagination
 func (r *Repository) List(ctx context.Context, nameFilter, emailFilter string, skip, limit int) ([]*models.User, int64, error) {
+	coll, err := r.users(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	// Build filter
 	filter := bson.M{
 		"is_active":  true,
@@ -163,7 +859,7 @@ func (r *Repository) List(ctx context.Context, nameFilter, emailFilter string, s
 	}
 
 	// Count total
-	total, err := r.db.Users().CountDocuments(ctx, filter)
+	total, err := coll.CountDocuments(ctx, filter)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count users: %w", err)
 	}
@@ -171,10 +867,10 @@ func (r *Repository) List(ctx context.Context, nameFilter, emailFilter string, s
 	// Find with pagination
 	skip64 := int64(skip)
 	limit64 := int64(limit)
-	cursor, err := r.db.Users().Find(ctx, filter, &options.FindOptions{
+	cursor, err := coll.Find(ctx, filter, &options.FindOptions{
 		Skip:  &skip64,
 		Limit: &limit64,
-		Sort:  bson.D{{"created_at", -1}}, // เรียงจากใหม่ไปเก่า
+		Sort:  bson.D{{Key: "created_at", Value: -1}}, // เรียงจากใหม่ไปเก่า
 	})
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to find users: %w", err)
@@ -196,3 +892,57 @@ func (r *Repository) List(ctx context.Context, nameFilter, emailFilter string, s
 
 	return users, total, nil
 }
+
+// tenantCollection - collection users ที่ทุก query ถูกจำกัดอยู่ใน tenant เดียว
+// (Repository เข้าถึง users ผ่าน type นี้เท่านั้น จึงอ่าน/แก้ user ของ tenant อื่นไม่ได้)
+type tenantCollection struct {
+	coll     *mongo.Collection
+	tenantID string
+}
+
+// users - collection users ที่ scope ตาม tenant ของ request (ไม่มี tenant = error ไม่ query ทั้งระบบ)
+func (r *Repository) users(ctx context.Context) (*tenantCollection, error) {
+	tenantID, ok := middleware.TenantIDFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("tenant not specified")
+	}
+	return &tenantCollection{
+		coll:     r.db.Users(),
+		tenantID: tenantID,
+	}, nil
+}
+
+func (c *tenantCollection) scope(filter bson.M) bson.M {
+	scoped := make(bson.M, len(filter)+1)
+	for k, v := range filter {
+		scoped[k] = v
+	}
+	scoped["tenant_id"] = c.tenantID
+	return scoped
+}
+
+func (c *tenantCollection) FindOne(ctx context.Context, filter bson.M, opts ...*options.FindOneOptions) *mongo.SingleResult {
+	return c.coll.FindOne(ctx, c.scope(filter), opts...)
+}
+
+func (c *tenantCollection) Find(ctx context.Context, filter bson.M, opts ...*options.FindOptions) (*mongo.Cursor, error) {
+	return c.coll.Find(ctx, c.scope(filter), opts...)
+}
+
+func (c *tenantCollection) CountDocuments(ctx context.Context, filter bson.M) (int64, error) {
+	return c.coll.CountDocuments(ctx, c.scope(filter))
+}
+
+func (c *tenantCollection) UpdateOne(ctx context.Context, filter bson.M, update interface{}) (*mongo.UpdateResult, error) {
+	return c.coll.UpdateOne(ctx, c.scope(filter), update)
+}
+
+func (c *tenantCollection) FindOneAndUpdate(ctx context.Context, filter bson.M, update interface{}, opts ...*options.FindOneAndUpdateOptions) *mongo.SingleResult {
+	return c.coll.FindOneAndUpdate(ctx, c.scope(filter), update, opts...)
+}
+
+// InsertOne - เพิ่ม user ใน tenant ของ request (tenant_id ที่ตั้งไว้ใน user จะถูกแทนที่)
+func (c *tenantCollection) InsertOne(ctx context.Context, user *models.User) (*mongo.InsertOneResult, error) {
+	user.TenantID = c.tenantID
+	return c.coll.InsertOne(ctx, user)
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"time"
@@ -66,6 +67,227 @@ func (m *MongoDB) RateLimits() *mongo.Collection {
 	return m.Database.Collection("rate_limits")
 }
 
+func (m *MongoDB) RefreshTokens() *mongo.Collection {
+	return m.Database.Collection("refresh_tokens")
+}
+
+func (m *MongoDB) Sessions() *mongo.Collection {
+	return m.Database.Collection("sessions")
+}
+
+func (m *MongoDB) SigningKeys() *mongo.Collection {
+	return m.Database.Collection("signing_keys")
+}
+
+func (m *MongoDB) WebAuthnCredentials() *mongo.Collection {
+	return m.Database.Collection("webauthn_credentials")
+}
+
+func (m *MongoDB) WebAuthnCeremonies() *mongo.Collection {
+	return m.Database.Collection("webauthn_ceremonies")
+}
+
+func (m *MongoDB) ActionTokens() *mongo.Collection {
+	return m.Database.Collection("action_tokens")
+}
+
+func (m *MongoDB) AuditEvents() *mongo.Collection {
+	return m.Database.Collection("audit_events")
+}
+
+func (m *MongoDB) Roles() *mongo.Collection {
+	return m.Database.Collection("roles")
+}
+
+func (m *MongoDB) Tenants() *mongo.Collection {
+	return m.Database.Collection("tenants")
+}
+
+func (m *MongoDB) Groups() *mongo.Collection {
+	return m.Database.Collection("groups")
+}
+
+// EnsureIndexes - สร้าง indexes ที่ระบบต้องใช้ (เรียกซ้ำได้อย่างปลอดภัย)
+func (m *MongoDB) EnsureIndexes(ctx context.Context) error {
+	// users: email ไม่ซ้ำกันภายใน tenant (นับเฉพาะบัญชีที่ใช้งานอยู่ เหมือนที่ Register ตรวจสอบ)
+	// index เดิมที่บังคับ email ไม่ซ้ำทั้งระบบต้องลบออก ไม่เช่นนั้น tenant อื่นจะใช้ email เดียวกันไม่ได้
+	if _, err := m.Users().Indexes().DropOne(ctx, "email_1"); err != nil && !isIndexNotFound(err) {
+		return fmt.Errorf("failed to drop legacy users email index: %w", err)
+	}
+	_, err := m.Users().Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "email", Value: 1}},
+		Options: options.Index().
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"is_active": true}),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create users indexes: %w", err)
+	}
+
+	// blacklisted_tokens: ค้นหาด้วย token_hash และให้ MongoDB ลบ token ที่หมดอายุเอง
+	// doc แบบเดิมเก็บ token ดิบไม่มี token_hash - ไม่นับใน unique index (ไม่เช่นนั้น null ซ้ำกันจะสร้าง index ไม่ได้)
+	// และจะถูก TTL index ลบเองเมื่อหมดอายุ
+	tokenHashIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "token_hash", Value: 1}},
+		Options: options.Index().
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"token_hash": bson.M{"$exists": true}}),
+	}
+	_, err = m.BlacklistedTokens().Indexes().CreateOne(ctx, tokenHashIndex)
+	if isIndexConflict(err) {
+		// index ชื่อเดียวกันที่ไม่ใช่ partial (สร้างโดย version ก่อน) - ลบแล้วสร้างใหม่
+		if _, err := m.BlacklistedTokens().Indexes().DropOne(ctx, "token_hash_1"); err != nil && !isIndexNotFound(err) {
+			return fmt.Errorf("failed to drop legacy blacklisted_tokens index: %w", err)
+		}
+		_, err = m.BlacklistedTokens().Indexes().CreateOne(ctx, tokenHashIndex)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create blacklisted_tokens indexes: %w", err)
+	}
+	_, err = m.BlacklistedTokens().Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create blacklisted_tokens indexes: %w", err)
+	}
+
+	// rate_limits: counter ของแต่ละ window ถูกลบอัตโนมัติเมื่อหมดอายุ
+	_, err = m.RateLimits().Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create rate_limits indexes: %w", err)
+	}
+
+	// refresh_tokens: ค้นหาด้วย hash, ยกเลิกทั้ง family และลบตัวที่หมดอายุอัตโนมัติ
+	_, err = m.RefreshTokens().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "family_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create refresh_tokens indexes: %w", err)
+	}
+
+	// sessions: แสดงรายการตาม user และลบ session ที่หมดอายุอัตโนมัติ
+	_, err = m.Sessions().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "last_seen_at", Value: -1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create sessions indexes: %w", err)
+	}
+
+	// webauthn_credentials: credential ID ไม่ซ้ำกันทั้งระบบ และแสดงรายการตาม user
+	_, err = m.WebAuthnCredentials().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "credential_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create webauthn_credentials indexes: %w", err)
+	}
+
+	// webauthn_ceremonies: challenge ที่ยังไม่ได้ใช้ถูกลบอัตโนมัติเมื่อหมดอายุ
+	_, err = m.WebAuthnCeremonies().Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create webauthn_ceremonies indexes: %w", err)
+	}
+
+	// action_tokens: token ใช้ครั้งเดียว (ยืนยัน email, reset รหัสผ่าน) ลบอัตโนมัติเมื่อหมดอายุ
+	_, err = m.ActionTokens().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "purpose", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create action_tokens indexes: %w", err)
+	}
+
+	// audit_events: ค้นหาประวัติของ user แต่ละคน เรียงจากใหม่ไปเก่า
+	_, err = m.AuditEvents().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "target_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "actor_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create audit_events indexes: %w", err)
+	}
+
+	// groups: ชื่อไม่ซ้ำกันภายใน tenant, หา groups ของ user และ groups ที่ใช้ role
+	_, err = m.Groups().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "members", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "roles", Value: 1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create groups indexes: %w", err)
+	}
+
+	return nil
+}
+
+// isIndexNotFound - index หรือ collection ยังไม่มีอยู่
+func isIndexNotFound(err error) bool {
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.Code == 26 || cmdErr.Code == 27 // NamespaceNotFound, IndexNotFound
+	}
+	return false
+}
+
+// isIndexConflict - มี index ชื่อหรือ keys เดียวกันอยู่แล้วแต่ options ต่างกัน
+func isIndexConflict(err error) bool {
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.Code == 85 || cmdErr.Code == 86 // IndexOptionsConflict, IndexKeySpecsConflict
+	}
+	return false
+}
+
 // TestConnection - ทดสอบการเชื่อมต่อและข้อมูล
 func (m *MongoDB) TestConnection() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
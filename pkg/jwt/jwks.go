@@ -0,0 +1,117 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+)
+
+// JWK - public key ในรูปแบบ JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// EC / OKP
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS - ชุดของ public keys ที่ service อื่นใช้ตรวจสอบ token แบบ offline
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK - แปลง public key เป็น JWK (HMAC key จะไม่ถูกเผยแพร่)
+func (k *SigningKey) JWK() (*JWK, error) {
+	if k.IsSymmetric() {
+		return nil, fmt.Errorf("symmetric keys cannot be published")
+	}
+
+	jwk := &JWK{
+		Kid: k.KID,
+		Use: "sig",
+		Alg: k.Algorithm,
+	}
+
+	switch pub := k.PublicKey().(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = b64(pub.N.Bytes())
+		jwk.E = b64(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		point, err := pub.ECDH()
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC public key: %w", err)
+		}
+		// uncompressed point: 0x04 || X || Y
+		raw := point.Bytes()
+		size := (len(raw) - 1) / 2
+		jwk.Kty = "EC"
+		jwk.Crv = "P-256"
+		jwk.X = b64(raw[1 : 1+size])
+		jwk.Y = b64(raw[1+size:])
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = b64(pub)
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", pub)
+	}
+
+	return jwk, nil
+}
+
+// thumbprint - JWK thumbprint (RFC 7638) ใช้เป็น kid
+func (k *SigningKey) thumbprint() (string, error) {
+	jwk, err := k.JWK()
+	if err != nil {
+		return "", err
+	}
+
+	// RFC 7638: ใช้เฉพาะ required members เรียงตามตัวอักษร
+	var canonical string
+	switch jwk.Kty {
+	case "RSA":
+		canonical = fmt.Sprintf(`{"e":%q,"kty":%q,"n":%q}`, jwk.E, jwk.Kty, jwk.N)
+	case "EC":
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q,"y":%q}`, jwk.Crv, jwk.Kty, jwk.X, jwk.Y)
+	case "OKP":
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q}`, jwk.Crv, jwk.Kty, jwk.X)
+	}
+
+	sum := sha256.Sum256([]byte(canonical))
+	return b64(sum[:]), nil
+}
+
+// JWKSHandler - HTTP handler สำหรับ /.well-known/jwks.json
+func (j *JWTService) JWKSHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		if err := json.NewEncoder(w).Encode(j.JWKS()); err != nil {
+			http.Error(w, "failed to encode JWKS", http.StatusInternalServerError)
+		}
+	})
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -1,6 +1,8 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"time"
 
@@ -8,53 +10,250 @@ import (
 )
 
 type JWTService struct {
-	secret []byte
+	ring   *KeyRing
+	config TokenConfig
 }
 
+// TokenConfig - ค่าที่ใช้ออกและตรวจสอบ access token
+type TokenConfig struct {
+	// AccessTTL - อายุของ access token
+	AccessTTL time.Duration
+	// Issuer - ค่า iss ที่ใส่และบังคับตอนตรวจสอบ
+	Issuer string
+	// Audience - audience ของ service นี้เอง (ใส่ในทุก token และบังคับตอนตรวจสอบ)
+	Audience string
+	// AllowedAudiences - audiences อื่นที่ client ขอได้ตอน login
+	AllowedAudiences []string
+	// Leeway - ยอมให้นาฬิกาคลาดเคลื่อนได้เท่านี้ตอนตรวจสอบ exp/nbf/iat
+	Leeway time.Duration
+}
+
+// TokenUseMFAChallenge - token ชั่วคราวหลังตรวจสอบรหัสผ่านผ่าน รอยืนยัน MFA (ใช้เรียก API ไม่ได้)
+const TokenUseMFAChallenge = "mfa_challenge"
+
 type Claims struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID       string   `json:"user_id"`
+	TenantID     string   `json:"tenant_id,omitempty"` // tenant ของ user (ว่าง = token ที่ออกก่อนรองรับ tenant)
+	Email        string   `json:"email"`
+	Role         string   `json:"role"`
+	Groups       []string `json:"groups,omitempty"` // ชื่อ groups ของ user ตอนออก token (สำหรับ service อื่นอ่านแบบ offline)
+	Scope        string   `json:"scope,omitempty"`  // สิทธิ์ของ user ตอนออก token คั่นด้วยช่องว่าง (เช่น "account:self users:read")
+	TokenVersion int64    `json:"tv"`               // epoch ของ user ตอนออก token
+	SessionID    string   `json:"sid,omitempty"`
+	AMR          []string `json:"amr,omitempty"`       // วิธียืนยันตัวตน (RFC 8176) เช่น pwd, otp, mfa
+	TokenUse     string   `json:"token_use,omitempty"` // ว่าง = access token
 	jwt.RegisteredClaims
 }
 
-func NewJWTService(secret string) *JWTService {
+// TokenOption - กำหนด claims เพิ่มเติมตอนออก token
+type TokenOption func(*Claims)
+
+// WithTokenVersion - ใส่ token epoch ของ user ลงใน token
+func WithTokenVersion(version int64) TokenOption {
+	return func(c *Claims) {
+		c.TokenVersion = version
+	}
+}
+
+// WithTenantID - ใส่ tenant ของ user ลงใน token
+func WithTenantID(tenantID string) TokenOption {
+	return func(c *Claims) {
+		c.TenantID = tenantID
+	}
+}
+
+// WithGroups - ใส่ชื่อ groups ของ user ลงใน token
+func WithGroups(groups ...string) TokenOption {
+	return func(c *Claims) {
+		c.Groups = groups
+	}
+}
+
+// WithSessionID - ผูก token กับ session ใน session registry
+func WithSessionID(sessionID string) TokenOption {
+	return func(c *Claims) {
+		c.SessionID = sessionID
+	}
+}
+
+// WithAudience - เพิ่ม audience ที่ client ขอ (ต้องอยู่ใน AllowedAudiences)
+func WithAudience(audience string) TokenOption {
+	return func(c *Claims) {
+		if audience != "" && !c.VerifyAudience(audience) {
+			c.Audience = append(c.Audience, audience)
+		}
+	}
+}
+
+// WithScope - ใส่ scope (คั่นด้วยช่องว่าง) ลงใน token
+func WithScope(scope string) TokenOption {
+	return func(c *Claims) {
+		c.Scope = scope
+	}
+}
+
+// WithAMR - ใส่วิธีที่ใช้ยืนยันตัวตนลงใน token
+func WithAMR(methods ...string) TokenOption {
+	return func(c *Claims) {
+		c.AMR = methods
+	}
+}
+
+// WithTokenUse - ออก token สำหรับใช้งานเฉพาะทาง (เช่น MFA challenge) ที่ ValidateToken ไม่ยอมรับ
+func WithTokenUse(use string, ttl time.Duration) TokenOption {
+	return func(c *Claims) {
+		c.TokenUse = use
+		c.ExpiresAt = jwt.NewNumericDate(c.IssuedAt.Add(ttl))
+	}
+}
+
+// HasAMR - ตรวจสอบว่า token ถูกออกหลังยืนยันตัวตนด้วยวิธีที่ระบุหรือไม่
+func (c *Claims) HasAMR(method string) bool {
+	for _, m := range c.AMR {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// RequestedAudience - audience ที่ client ขอตอน login (นอกเหนือจาก audience ของ service นี้)
+func (j *JWTService) RequestedAudience(c *Claims) string {
+	for _, aud := range c.Audience {
+		if aud != j.config.Audience {
+			return aud
+		}
+	}
+	return ""
+}
+
+func NewJWTService(ring *KeyRing, config TokenConfig) *JWTService {
 	return &JWTService{
-		secret: []byte(secret),
+		ring:   ring,
+		config: config,
+	}
+}
+
+// AccessTTL - อายุของ access token ที่ออกโดย service นี้
+func (j *JWTService) AccessTTL() time.Duration {
+	return j.config.AccessTTL
+}
+
+// IsAllowedAudience - ตรวจสอบว่า client ขอ audience นี้ได้หรือไม่
+func (j *JWTService) IsAllowedAudience(audience string) bool {
+	if audience == j.config.Audience {
+		return true
+	}
+	for _, allowed := range j.config.AllowedAudiences {
+		if audience == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyAudience - ตรวจสอบว่า token มี audience ที่ระบุหรือไม่
+func (c *Claims) VerifyAudience(audience string) bool {
+	for _, aud := range c.Audience {
+		if aud == audience {
+			return true
+		}
 	}
+	return false
 }
 
-func (j *JWTService) GenerateToken(userID, email, role string) (string, error) {
+// Algorithm - algorithm ของ active key ที่ใช้เซ็น token
+func (j *JWTService) Algorithm() string {
+	return j.ring.Active().Algorithm
+}
+
+// KeyRing - key ring ที่ใช้เซ็นและตรวจสอบ token
+func (j *JWTService) KeyRing() *KeyRing {
+	return j.ring
+}
+
+func (j *JWTService) GenerateToken(userID, email, role string, opts ...TokenOption) (string, error) {
+	now := time.Now()
 	claims := Claims{
 		UserID: userID,
 		Email:  email,
 		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    "auth-microservice",
+			ExpiresAt: jwt.NewNumericDate(now.Add(j.config.AccessTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    j.config.Issuer,
+			Audience:  jwt.ClaimStrings{j.config.Audience},
+			ID:        newTokenID(),
 		},
 	}
+	for _, opt := range opts {
+		opt(&claims)
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(j.secret)
+	key := j.ring.Active()
+	token := jwt.NewWithClaims(key.method(), claims)
+	token.Header["kid"] = key.KID
+	return token.SignedString(key.signingKey())
 }
 
+// ValidateToken - ตรวจสอบ access token (token สำหรับใช้งานเฉพาะทางจะไม่ผ่าน)
 func (j *JWTService) ValidateToken(tokenString string) (*Claims, error) {
+	return j.ValidateTokenUse(tokenString, "")
+}
+
+// ValidateTokenUse - ตรวจสอบ token และบังคับ token_use ที่ต้องการ
+func (j *JWTService) ValidateTokenUse(tokenString, use string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		// เลือก key จาก kid (token เก่าที่ไม่มี kid จะใช้ legacy key)
+		kid, _ := token.Header["kid"].(string)
+		key, ok := j.ring.Lookup(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key: %q", kid)
+		}
+
+		// ยอมรับเฉพาะ algorithm ของ key นั้น (ป้องกัน algorithm confusion)
+		if token.Method.Alg() != key.Algorithm {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return j.secret, nil
-	})
+		return key.verificationKey(), nil
+	},
+		jwt.WithIssuer(j.config.Issuer),
+		jwt.WithAudience(j.config.Audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(j.config.Leeway),
+	)
 
 	if err != nil {
 		return nil, err
 	}
 
+	// nbf ถูกตรวจสอบโดย library (พร้อม leeway) เมื่อมีใน token
 	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+		if claims.TokenUse != use {
+			return nil, fmt.Errorf("unexpected token use: %q", claims.TokenUse)
+		}
 		return claims, nil
 	}
 
 	return nil, fmt.Errorf("invalid token")
 }
+
+// JWKS - public keys ที่ใช้ตรวจสอบ token (pending, active และ retired; ไม่รวม HS256)
+func (j *JWTService) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	for _, k := range j.ring.Keys() {
+		if jwk, err := k.JWK(); err == nil {
+			jwks.Keys = append(jwks.Keys, *jwk)
+		}
+	}
+	return jwks
+}
+
+// newTokenID - jti แบบสุ่ม (128 bits) สำหรับทุก token
+func newTokenID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,116 @@
+package jwt
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// KeyState - สถานะของ signing key ใน key ring
+type KeyState string
+
+const (
+	// KeyPending - เผยแพร่ใน JWKS แล้วแต่ยังไม่ใช้เซ็น (ให้ service อื่น cache ไว้ก่อน)
+	KeyPending KeyState = "pending"
+	// KeyActive - key ที่ใช้เซ็น token ใหม่ (มีได้ตัวเดียว)
+	KeyActive KeyState = "active"
+	// KeyRetired - ไม่ใช้เซ็นแล้ว แต่ยังตรวจสอบ token เก่าได้จนกว่าจะหมดอายุ
+	KeyRetired KeyState = "retired"
+)
+
+// RingKey - signing key พร้อมสถานะใน key ring
+type RingKey struct {
+	*SigningKey
+	State       KeyState
+	CreatedAt   time.Time
+	ActivatedAt *time.Time
+	ExpiresAt   *time.Time
+}
+
+// KeyRing - ชุดของ signing keys ที่ใช้อยู่ เลือก key สำหรับตรวจสอบจาก kid ใน header
+type KeyRing struct {
+	mu        sync.RWMutex
+	active    *SigningKey
+	keys      map[string]*RingKey
+	legacyKID string
+}
+
+// NewKeyRing - สร้าง key ring ที่มี key เริ่มต้นเป็น active
+func NewKeyRing(initial *SigningKey) *KeyRing {
+	ring := &KeyRing{
+		keys:      make(map[string]*RingKey),
+		legacyKID: initial.KID,
+	}
+	ring.Replace([]*RingKey{{SigningKey: initial, State: KeyActive, CreatedAt: time.Now()}})
+	return ring
+}
+
+// Replace - แทนที่ keys ทั้งหมด (ถ้าไม่มี active key จะใช้ active ตัวเดิมต่อ)
+func (r *KeyRing) Replace(keys []*RingKey) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next := make(map[string]*RingKey, len(keys))
+	var active *SigningKey
+	for _, k := range keys {
+		next[k.KID] = k
+		if k.State == KeyActive {
+			active = k.SigningKey
+		}
+	}
+
+	if active == nil && r.active != nil {
+		// ระหว่างการ rotate อาจไม่มี active key ชั่วขณะ - ใช้ตัวเดิมไปก่อน
+		active = r.active
+		if _, ok := next[active.KID]; !ok {
+			next[active.KID] = &RingKey{SigningKey: active, State: KeyRetired}
+		}
+	}
+
+	r.keys = next
+	r.active = active
+}
+
+// SetLegacyKID - key ที่ใช้ตรวจสอบ token ที่ไม่มี kid (ออกก่อนเปิดใช้ key ring)
+func (r *KeyRing) SetLegacyKID(kid string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.legacyKID = kid
+}
+
+// Active - key ที่ใช้เซ็น token ใหม่
+func (r *KeyRing) Active() *SigningKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.active
+}
+
+// Lookup - หา key สำหรับตรวจสอบ token ตาม kid (kid ว่าง = legacy key)
+func (r *KeyRing) Lookup(kid string) (*SigningKey, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if kid == "" {
+		kid = r.legacyKID
+	}
+	k, ok := r.keys[kid]
+	if !ok {
+		return nil, false
+	}
+	return k.SigningKey, true
+}
+
+// Keys - keys ทั้งหมดใน ring เรียงจากใหม่ไปเก่า
+func (r *KeyRing) Keys() []*RingKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]*RingKey, 0, len(r.keys))
+	for _, k := range r.keys {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	return keys
+}
@@ -0,0 +1,203 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgEdDSA = "EdDSA"
+)
+
+// SigningKey - key สำหรับเซ็นและตรวจสอบ JWT
+//
+// สำหรับ HS256 ใช้ secret เดียวกันทั้งเซ็นและตรวจสอบ (ไม่เปิดเผยใน JWKS)
+// สำหรับ RS256/ES256/EdDSA private key อยู่ใน service นี้เท่านั้น และเผยแพร่เฉพาะ public key
+type SigningKey struct {
+	KID       string
+	Algorithm string
+
+	secret  []byte
+	private crypto.Signer
+}
+
+// NewHMACKey - สร้าง HS256 key จาก shared secret (kid มาจาก hash ของ secret)
+func NewHMACKey(secret string) *SigningKey {
+	sum := sha256.Sum256([]byte(secret))
+	return &SigningKey{
+		KID:       "hs-" + hex.EncodeToString(sum[:8]),
+		Algorithm: AlgHS256,
+		secret:    []byte(secret),
+	}
+}
+
+// GenerateSigningKey - สร้าง key ใหม่ตาม algorithm
+func GenerateSigningKey(alg string) (*SigningKey, error) {
+	var (
+		private crypto.Signer
+		err     error
+	)
+
+	switch alg {
+	case AlgHS256:
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("failed to generate HS256 secret: %w", err)
+		}
+		return NewHMACKey(string(secret)), nil
+	case AlgRS256:
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	case AlgES256:
+		private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", alg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate %s key: %w", alg, err)
+	}
+
+	return newAsymmetricKey(alg, private)
+}
+
+// ParseSigningKey - อ่าน private key (PEM: PKCS#8, PKCS#1 หรือ SEC1) สำหรับ algorithm ที่กำหนด
+func ParseSigningKey(alg string, pemData []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found in private key")
+	}
+
+	var private crypto.Signer
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		private = signer
+	} else if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		private = key
+	} else if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		private = key
+	} else {
+		return nil, fmt.Errorf("failed to parse private key")
+	}
+
+	return newAsymmetricKey(alg, private)
+}
+
+// MarshalPrivate - แปลง key เป็น bytes สำหรับเก็บใน key store (PKCS#8 PEM หรือ raw secret)
+func (k *SigningKey) MarshalPrivate() ([]byte, error) {
+	if k.IsSymmetric() {
+		return k.secret, nil
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(k.private)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal private key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// UnmarshalSigningKey - อ่าน key ที่ได้จาก MarshalPrivate
+func UnmarshalSigningKey(alg string, data []byte) (*SigningKey, error) {
+	if alg == AlgHS256 {
+		return NewHMACKey(string(data)), nil
+	}
+	return ParseSigningKey(alg, data)
+}
+
+func newAsymmetricKey(alg string, private crypto.Signer) (*SigningKey, error) {
+	// ตรวจสอบว่าชนิดของ key ตรงกับ algorithm
+	switch key := private.(type) {
+	case *rsa.PrivateKey:
+		if alg != AlgRS256 {
+			return nil, fmt.Errorf("RSA key cannot be used with %s", alg)
+		}
+		if key.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("RSA key must be at least 2048 bits")
+		}
+	case *ecdsa.PrivateKey:
+		if alg != AlgES256 || key.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("ECDSA key must use P-256 with ES256")
+		}
+	case ed25519.PrivateKey:
+		if alg != AlgEdDSA {
+			return nil, fmt.Errorf("Ed25519 key cannot be used with %s", alg)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", private)
+	}
+
+	key := &SigningKey{
+		Algorithm: alg,
+		private:   private,
+	}
+
+	// kid = JWK thumbprint (RFC 7638) ของ public key
+	kid, err := key.thumbprint()
+	if err != nil {
+		return nil, err
+	}
+	key.KID = kid
+
+	return key, nil
+}
+
+// IsSymmetric - true ถ้าเป็น HMAC key (ไม่มี public key ให้เผยแพร่)
+func (k *SigningKey) IsSymmetric() bool {
+	return k.Algorithm == AlgHS256
+}
+
+// PublicKey - public key สำหรับตรวจสอบ token (nil สำหรับ HMAC)
+func (k *SigningKey) PublicKey() crypto.PublicKey {
+	if k.private == nil {
+		return nil
+	}
+	return k.private.Public()
+}
+
+// method - jwt signing method ตาม algorithm
+func (k *SigningKey) method() jwt.SigningMethod {
+	switch k.Algorithm {
+	case AlgRS256:
+		return jwt.SigningMethodRS256
+	case AlgES256:
+		return jwt.SigningMethodES256
+	case AlgEdDSA:
+		return jwt.SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
+
+// signingKey - key ที่ใช้เซ็น token
+func (k *SigningKey) signingKey() interface{} {
+	if k.IsSymmetric() {
+		return k.secret
+	}
+	return k.private
+}
+
+// verificationKey - key ที่ใช้ตรวจสอบ token
+func (k *SigningKey) verificationKey() interface{} {
+	if k.IsSymmetric() {
+		return k.secret
+	}
+	return k.private.Public()
+}
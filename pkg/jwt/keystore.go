@@ -0,0 +1,314 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// storedKey - signing key ที่เก็บใน collection signing_keys
+//
+// หมายเหตุ: private key ถูกเก็บในฐานข้อมูลเพื่อให้ทุก replica ใช้ key ชุดเดียวกัน
+// ผู้ที่เข้าถึง collection นี้ได้สามารถออก token เองได้ จึงต้องจำกัดสิทธิ์ของ MongoDB ให้ดี
+type storedKey struct {
+	KID         string     `bson:"_id"`
+	Algorithm   string     `bson:"algorithm"`
+	State       KeyState   `bson:"state"`
+	PrivateKey  []byte     `bson:"private_key"`
+	CreatedAt   time.Time  `bson:"created_at"`
+	ActivatedAt *time.Time `bson:"activated_at,omitempty"`
+	RetiredAt   *time.Time `bson:"retired_at,omitempty"`
+	// ExpiresAt - retired key จะถูกลบโดย TTL index หลังเวลานี้
+	ExpiresAt *time.Time `bson:"expires_at,omitempty"`
+}
+
+// KeyStore - จัดเก็บและ rotate signing keys ใน MongoDB
+type KeyStore struct {
+	coll      *mongo.Collection
+	algorithm string
+	overlap   time.Duration
+}
+
+// NewKeyStore - algorithm ใช้กับ key ใหม่, overlap คือเวลาที่ retired key ยังตรวจสอบ token ได้
+// (ควรมากกว่าหรือเท่ากับอายุของ access token)
+func NewKeyStore(coll *mongo.Collection, algorithm string, overlap time.Duration) *KeyStore {
+	return &KeyStore{
+		coll:      coll,
+		algorithm: algorithm,
+		overlap:   overlap,
+	}
+}
+
+// EnsureIndexes - TTL index สำหรับลบ retired keys ที่หมดช่วง overlap
+func (s *KeyStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "state", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create signing_keys indexes: %w", err)
+	}
+	return nil
+}
+
+// Bootstrap - ถ้ายังไม่มี active key ให้ใช้ seed (key จาก config) เป็น active
+// เพื่อให้ token ที่ออกไปก่อนหน้ายังใช้งานได้ และสร้าง pending key สำหรับ rotation ครั้งถัดไป
+func (s *KeyStore) Bootstrap(ctx context.Context, seed *SigningKey) error {
+	count, err := s.coll.CountDocuments(ctx, bson.M{"state": KeyActive})
+	if err != nil {
+		return fmt.Errorf("failed to count active keys: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	now := time.Now()
+	if err := s.insert(ctx, seed, KeyActive, &now); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			// replica อื่น bootstrap ไปแล้ว
+			return nil
+		}
+		return err
+	}
+	log.Printf("🔑 Bootstrapped signing key %s (%s)", seed.KID, seed.Algorithm)
+
+	return s.ensurePending(ctx)
+}
+
+// Load - โหลด keys ทั้งหมดจากฐานข้อมูลเข้า key ring
+func (s *KeyStore) Load(ctx context.Context, ring *KeyRing) error {
+	keys, err := s.list(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	ringKeys := make([]*RingKey, 0, len(keys))
+	for _, k := range keys {
+		// TTL index ทำงานทุก ~60 วินาที จึงต้องกรอง key ที่หมดอายุเองด้วย
+		if k.ExpiresAt != nil && now.After(*k.ExpiresAt) {
+			continue
+		}
+
+		signingKey, err := UnmarshalSigningKey(k.Algorithm, k.PrivateKey)
+		if err != nil {
+			log.Printf("⚠️  Skipping unreadable signing key %s: %v", k.KID, err)
+			continue
+		}
+		if signingKey.KID != k.KID {
+			log.Printf("⚠️  Skipping signing key %s: kid does not match key material", k.KID)
+			continue
+		}
+
+		ringKeys = append(ringKeys, &RingKey{
+			SigningKey:  signingKey,
+			State:       k.State,
+			CreatedAt:   k.CreatedAt,
+			ActivatedAt: k.ActivatedAt,
+			ExpiresAt:   k.ExpiresAt,
+		})
+	}
+
+	ring.Replace(ringKeys)
+	return nil
+}
+
+// Rotate - pending → active, active → retired และสร้าง pending key ใหม่
+func (s *KeyStore) Rotate(ctx context.Context) error {
+	var active storedKey
+	err := s.coll.FindOne(ctx, bson.M{"state": KeyActive}).Decode(&active)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return fmt.Errorf("failed to find active key: %w", err)
+	}
+
+	if err == nil {
+		// retire active key (เงื่อนไข state ป้องกันไม่ให้ replica อื่น rotate ซ้อนกัน)
+		now := time.Now()
+		expiresAt := now.Add(s.overlap)
+		result, err := s.coll.UpdateOne(ctx,
+			bson.M{"_id": active.KID, "state": KeyActive},
+			bson.M{"$set": bson.M{
+				"state":      KeyRetired,
+				"retired_at": now,
+				"expires_at": expiresAt,
+			}},
+		)
+		if err != nil {
+			return fmt.Errorf("failed to retire key %s: %w", active.KID, err)
+		}
+		if result.ModifiedCount == 0 {
+			return fmt.Errorf("key %s was rotated concurrently", active.KID)
+		}
+	}
+
+	if err := s.promotePending(ctx); err != nil {
+		return err
+	}
+
+	return s.ensurePending(ctx)
+}
+
+// RotateIfDue - rotate ถ้า active key ถูกใช้มานานกว่า interval
+func (s *KeyStore) RotateIfDue(ctx context.Context, interval time.Duration) (bool, error) {
+	var active storedKey
+	err := s.coll.FindOne(ctx, bson.M{"state": KeyActive}).Decode(&active)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to find active key: %w", err)
+	}
+
+	if active.ActivatedAt == nil || time.Since(*active.ActivatedAt) < interval {
+		return false, nil
+	}
+
+	if err := s.Rotate(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Run - sync key ring กับฐานข้อมูลเป็นระยะ และ rotate ตาม rotationInterval (0 = ไม่ rotate อัตโนมัติ)
+func (s *KeyStore) Run(ctx context.Context, ring *KeyRing, refreshInterval, rotationInterval time.Duration) {
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if rotationInterval > 0 {
+			rotated, err := s.RotateIfDue(ctx, rotationInterval)
+			if err != nil {
+				log.Printf("⚠️  Scheduled key rotation failed: %v", err)
+			} else if rotated {
+				log.Println("🔄 Signing keys rotated (scheduled)")
+			}
+		}
+
+		if err := s.Load(ctx, ring); err != nil {
+			log.Printf("⚠️  Failed to reload signing keys: %v", err)
+		}
+	}
+}
+
+// KeyInfo - ข้อมูลของ key สำหรับแสดงผล (ไม่มี private key)
+type KeyInfo struct {
+	KID         string
+	Algorithm   string
+	State       KeyState
+	CreatedAt   time.Time
+	ActivatedAt *time.Time
+	ExpiresAt   *time.Time
+}
+
+// List - รายการ keys ทั้งหมดใน store
+func (s *KeyStore) List(ctx context.Context) ([]KeyInfo, error) {
+	keys, err := s.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]KeyInfo, 0, len(keys))
+	for _, k := range keys {
+		infos = append(infos, KeyInfo{
+			KID:         k.KID,
+			Algorithm:   k.Algorithm,
+			State:       k.State,
+			CreatedAt:   k.CreatedAt,
+			ActivatedAt: k.ActivatedAt,
+			ExpiresAt:   k.ExpiresAt,
+		})
+	}
+	return infos, nil
+}
+
+func (s *KeyStore) list(ctx context.Context) ([]storedKey, error) {
+	cursor, err := s.coll.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find signing keys: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var keys []storedKey
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, fmt.Errorf("failed to decode signing keys: %w", err)
+	}
+	return keys, nil
+}
+
+// promotePending - เปลี่ยน pending key ที่เก่าที่สุดเป็น active (ถ้าไม่มีจะสร้างใหม่)
+func (s *KeyStore) promotePending(ctx context.Context) error {
+	now := time.Now()
+
+	var promoted storedKey
+	err := s.coll.FindOneAndUpdate(ctx,
+		bson.M{"state": KeyPending},
+		bson.M{"$set": bson.M{"state": KeyActive, "activated_at": now}},
+		options.FindOneAndUpdate().SetSort(bson.D{{Key: "created_at", Value: 1}}),
+	).Decode(&promoted)
+	if err == nil {
+		return nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return fmt.Errorf("failed to promote pending key: %w", err)
+	}
+
+	key, err := GenerateSigningKey(s.algorithm)
+	if err != nil {
+		return err
+	}
+	return s.insert(ctx, key, KeyActive, &now)
+}
+
+// ensurePending - สร้าง pending key ถ้ายังไม่มี
+func (s *KeyStore) ensurePending(ctx context.Context) error {
+	count, err := s.coll.CountDocuments(ctx, bson.M{"state": KeyPending})
+	if err != nil {
+		return fmt.Errorf("failed to count pending keys: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	key, err := GenerateSigningKey(s.algorithm)
+	if err != nil {
+		return err
+	}
+	return s.insert(ctx, key, KeyPending, nil)
+}
+
+func (s *KeyStore) insert(ctx context.Context, key *SigningKey, state KeyState, activatedAt *time.Time) error {
+	privateKey, err := key.MarshalPrivate()
+	if err != nil {
+		return err
+	}
+
+	doc := storedKey{
+		KID:         key.KID,
+		Algorithm:   key.Algorithm,
+		State:       state,
+		PrivateKey:  privateKey,
+		CreatedAt:   time.Now(),
+		ActivatedAt: activatedAt,
+	}
+
+	if _, err := s.coll.InsertOne(ctx, doc); err != nil {
+		return fmt.Errorf("failed to store signing key: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v6.31.1
+// source: proto/authz/authz.proto
+
+package authz
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	descriptorpb "google.golang.org/protobuf/types/descriptorpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Rule - เงื่อนไขการเรียก RPC (AuthInterceptor อ่านจาก service descriptors ตอนเริ่ม server)
+// method ที่ไม่มี option (authz) จะถูกปฏิเสธเสมอ
+type Rule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Public        bool                   `protobuf:"varint,1,opt,name=public,proto3" json:"public,omitempty"`          // เรียกได้โดยไม่ต้อง login
+	Service       bool                   `protobuf:"varint,2,opt,name=service,proto3" json:"service,omitempty"`        // service อื่นเท่านั้น (client credentials แทน JWT)
+	Permissions   []string               `protobuf:"bytes,3,rep,name=permissions,proto3" json:"permissions,omitempty"` // role ของผู้เรียกต้องมีทุกสิทธิ์ (ว่าง = แค่ login)
+	Mfa           bool                   `protobuf:"varint,4,opt,name=mfa,proto3" json:"mfa,omitempty"`                // access token ต้องได้จากการยืนยันตัวตนหลายขั้น (amr มี mfa)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Rule) Reset() {
+	*x = Rule{}
+	mi := &file_proto_authz_authz_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Rule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rule) ProtoMessage() {}
+
+func (x *Rule) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_authz_authz_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Rule.ProtoReflect.Descriptor instead.
+func (*Rule) Descriptor() ([]byte, []int) {
+	return file_proto_authz_authz_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Rule) GetPublic() bool {
+	if x != nil {
+		return x.Public
+	}
+	return false
+}
+
+func (x *Rule) GetService() bool {
+	if x != nil {
+		return x.Service
+	}
+	return false
+}
+
+func (x *Rule) GetPermissions() []string {
+	if x != nil {
+		return x.Permissions
+	}
+	return nil
+}
+
+func (x *Rule) GetMfa() bool {
+	if x != nil {
+		return x.Mfa
+	}
+	return false
+}
+
+var file_proto_authz_authz_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*Rule)(nil),
+		Field:         50100,
+		Name:          "authz.authz",
+		Tag:           "bytes,50100,opt,name=authz",
+		Filename:      "proto/authz/authz.proto",
+	},
+}
+
+// Extension fields to descriptorpb.MethodOptions.
+var (
+	// optional authz.Rule authz = 50100;
+	E_Authz = &file_proto_authz_authz_proto_extTypes[0]
+)
+
+var File_proto_authz_authz_proto protoreflect.FileDescriptor
+
+const file_proto_authz_authz_proto_rawDesc = "" +
+	"\n" +
+	"\x17proto/authz/authz.proto\x12\x05authz\x1a google/protobuf/descriptor.proto\"l\n" +
+	"\x04Rule\x12\x16\n" +
+	"\x06public\x18\x01 \x01(\bR\x06public\x12\x18\n" +
+	"\aservice\x18\x02 \x01(\bR\aservice\x12 \n" +
+	"\vpermissions\x18\x03 \x03(\tR\vpermissions\x12\x10\n" +
+	"\x03mfa\x18\x04 \x01(\bR\x03mfa:C\n" +
+	"\x05authz\x12\x1e.google.protobuf.MethodOptions\x18\xb4\x87\x03 \x01(\v2\v.authz.RuleR\x05authzB\x1fZ\x1dauth-microservice/proto/authzb\x06proto3"
+
+var (
+	file_proto_authz_authz_proto_rawDescOnce sync.Once
+	file_proto_authz_authz_proto_rawDescData []byte
+)
+
+func file_proto_authz_authz_proto_rawDescGZIP() []byte {
+	file_proto_authz_authz_proto_rawDescOnce.Do(func() {
+		file_proto_authz_authz_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_authz_authz_proto_rawDesc), len(file_proto_authz_authz_proto_rawDesc)))
+	})
+	return file_proto_authz_authz_proto_rawDescData
+}
+
+var file_proto_authz_authz_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_proto_authz_authz_proto_goTypes = []any{
+	(*Rule)(nil),                       // 0: authz.Rule
+	(*descriptorpb.MethodOptions)(nil), // 1: google.protobuf.MethodOptions
+}
+var file_proto_authz_authz_proto_depIdxs = []int32{
+	1, // 0: authz.authz:extendee -> google.protobuf.MethodOptions
+	0, // 1: authz.authz:type_name -> authz.Rule
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	1, // [1:2] is the sub-list for extension type_name
+	0, // [0:1] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_proto_authz_authz_proto_init() }
+func file_proto_authz_authz_proto_init() {
+	if File_proto_authz_authz_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_authz_authz_proto_rawDesc), len(file_proto_authz_authz_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 1,
+			NumServices:   0,
+		},
+		GoTypes:           file_proto_authz_authz_proto_goTypes,
+		DependencyIndexes: file_proto_authz_authz_proto_depIdxs,
+		MessageInfos:      file_proto_authz_authz_proto_msgTypes,
+		ExtensionInfos:    file_proto_authz_authz_proto_extTypes,
+	}.Build()
+	File_proto_authz_authz_proto = out.File
+	file_proto_authz_authz_proto_goTypes = nil
+	file_proto_authz_authz_proto_depIdxs = nil
+}
@@ -7,6 +7,7 @@
 package user
 
 import (
+	_ "auth-microservice/proto/authz"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -250,7 +251,7 @@ func (x *ListUsersResponse) GetTotalPages() int32 {
 	return 0
 }
 
-// Get Profile
+// Get Profile (ตัวเอง หรือผู้มีสิทธิ์ users:read)
 type GetProfileRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
@@ -355,7 +356,7 @@ func (x *GetProfileResponse) GetUser() *User {
 	return nil
 }
 
-// Update Profile
+// Update Profile (ตัวเอง หรือผู้มีสิทธิ์ users:write)
 type UpdateProfileRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
@@ -476,7 +477,7 @@ func (x *UpdateProfileResponse) GetMessage() string {
 	return ""
 }
 
-// Delete Profile
+// Delete Profile (ตัวเอง หรือผู้มีสิทธิ์ users:write)
 type DeleteProfileRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
@@ -573,11 +574,109 @@ func (x *DeleteProfileResponse) GetMessage() string {
 	return ""
 }
 
+// Get Me
+type GetMeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMeRequest) Reset() {
+	*x = GetMeRequest{}
+	mi := &file_proto_user_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMeRequest) ProtoMessage() {}
+
+func (x *GetMeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMeRequest.ProtoReflect.Descriptor instead.
+func (*GetMeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{9}
+}
+
+// Update Me
+type UpdateMeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FirstName     string                 `protobuf:"bytes,1,opt,name=first_name,json=firstName,proto3" json:"first_name,omitempty"`
+	LastName      string                 `protobuf:"bytes,2,opt,name=last_name,json=lastName,proto3" json:"last_name,omitempty"`
+	Email         string                 `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateMeRequest) Reset() {
+	*x = UpdateMeRequest{}
+	mi := &file_proto_user_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateMeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateMeRequest) ProtoMessage() {}
+
+func (x *UpdateMeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateMeRequest.ProtoReflect.Descriptor instead.
+func (*UpdateMeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *UpdateMeRequest) GetFirstName() string {
+	if x != nil {
+		return x.FirstName
+	}
+	return ""
+}
+
+func (x *UpdateMeRequest) GetLastName() string {
+	if x != nil {
+		return x.LastName
+	}
+	return ""
+}
+
+func (x *UpdateMeRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
 var File_proto_user_proto protoreflect.FileDescriptor
 
 const file_proto_user_proto_rawDesc = "" +
 	"\n" +
-	"\x10proto/user.proto\x12\x04user\"\x80\x01\n" +
+	"\x10proto/user.proto\x12\x04user\x1a\x17proto/authz/authz.proto\"\x80\x01\n" +
 	"\x10ListUsersRequest\x12\x1f\n" +
 	"\vname_filter\x18\x01 \x01(\tR\n" +
 	"nameFilter\x12!\n" +
@@ -621,13 +720,22 @@ const file_proto_user_proto_rawDesc = "" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\"K\n" +
 	"\x15DeleteProfileResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage2\xa0\x02\n" +
-	"\vUserService\x12<\n" +
-	"\tListUsers\x12\x16.user.ListUsersRequest\x1a\x17.user.ListUsersResponse\x12?\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x0e\n" +
+	"\fGetMeRequest\"c\n" +
+	"\x0fUpdateMeRequest\x12\x1d\n" +
+	"\n" +
+	"first_name\x18\x01 \x01(\tR\tfirstName\x12\x1b\n" +
+	"\tlast_name\x18\x02 \x01(\tR\blastName\x12\x14\n" +
+	"\x05email\x18\x03 \x01(\tR\x05email2\x8d\x04\n" +
+	"\vUserService\x12N\n" +
+	"\tListUsers\x12\x16.user.ListUsersRequest\x1a\x17.user.ListUsersResponse\"\x10\xa2\xbb\x18\f\x1a\n" +
+	"users:read\x12S\n" +
 	"\n" +
-	"GetProfile\x12\x17.user.GetProfileRequest\x1a\x18.user.GetProfileResponse\x12H\n" +
-	"\rUpdateProfile\x12\x1a.user.UpdateProfileRequest\x1a\x1b.user.UpdateProfileResponse\x12H\n" +
-	"\rDeleteProfile\x12\x1a.user.DeleteProfileRequest\x1a\x1b.user.DeleteProfileResponseB\x0eZ\f./proto/userb\x06proto3"
+	"GetProfile\x12\x17.user.GetProfileRequest\x1a\x18.user.GetProfileResponse\"\x12\xa2\xbb\x18\x0e\x1a\faccount:self\x12\\\n" +
+	"\rUpdateProfile\x12\x1a.user.UpdateProfileRequest\x1a\x1b.user.UpdateProfileResponse\"\x12\xa2\xbb\x18\x0e\x1a\faccount:self\x12\\\n" +
+	"\rDeleteProfile\x12\x1a.user.DeleteProfileRequest\x1a\x1b.user.DeleteProfileResponse\"\x12\xa2\xbb\x18\x0e\x1a\faccount:self\x12I\n" +
+	"\x05GetMe\x12\x12.user.GetMeRequest\x1a\x18.user.GetProfileResponse\"\x12\xa2\xbb\x18\x0e\x1a\faccount:self\x12R\n" +
+	"\bUpdateMe\x12\x15.user.UpdateMeRequest\x1a\x1b.user.UpdateProfileResponse\"\x12\xa2\xbb\x18\x0e\x1a\faccount:selfB\x0eZ\f./proto/userb\x06proto3"
 
 var (
 	file_proto_user_proto_rawDescOnce sync.Once
@@ -641,7 +749,7 @@ func file_proto_user_proto_rawDescGZIP() []byte {
 	return file_proto_user_proto_rawDescData
 }
 
-var file_proto_user_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_proto_user_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
 var file_proto_user_proto_goTypes = []any{
 	(*ListUsersRequest)(nil),      // 0: user.ListUsersRequest
 	(*User)(nil),                  // 1: user.User
@@ -652,23 +760,29 @@ var file_proto_user_proto_goTypes = []any{
 	(*UpdateProfileResponse)(nil), // 6: user.UpdateProfileResponse
 	(*DeleteProfileRequest)(nil),  // 7: user.DeleteProfileRequest
 	(*DeleteProfileResponse)(nil), // 8: user.DeleteProfileResponse
+	(*GetMeRequest)(nil),          // 9: user.GetMeRequest
+	(*UpdateMeRequest)(nil),       // 10: user.UpdateMeRequest
 }
 var file_proto_user_proto_depIdxs = []int32{
-	1, // 0: user.ListUsersResponse.users:type_name -> user.User
-	1, // 1: user.GetProfileResponse.user:type_name -> user.User
-	0, // 2: user.UserService.ListUsers:input_type -> user.ListUsersRequest
-	3, // 3: user.UserService.GetProfile:input_type -> user.GetProfileRequest
-	5, // 4: user.UserService.UpdateProfile:input_type -> user.UpdateProfileRequest
-	7, // 5: user.UserService.DeleteProfile:input_type -> user.DeleteProfileRequest
-	2, // 6: user.UserService.ListUsers:output_type -> user.ListUsersResponse
-	4, // 7: user.UserService.GetProfile:output_type -> user.GetProfileResponse
-	6, // 8: user.UserService.UpdateProfile:output_type -> user.UpdateProfileResponse
-	8, // 9: user.UserService.DeleteProfile:output_type -> user.DeleteProfileResponse
-	6, // [6:10] is the sub-list for method output_type
-	2, // [2:6] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	1,  // 0: user.ListUsersResponse.users:type_name -> user.User
+	1,  // 1: user.GetProfileResponse.user:type_name -> user.User
+	0,  // 2: user.UserService.ListUsers:input_type -> user.ListUsersRequest
+	3,  // 3: user.UserService.GetProfile:input_type -> user.GetProfileRequest
+	5,  // 4: user.UserService.UpdateProfile:input_type -> user.UpdateProfileRequest
+	7,  // 5: user.UserService.DeleteProfile:input_type -> user.DeleteProfileRequest
+	9,  // 6: user.UserService.GetMe:input_type -> user.GetMeRequest
+	10, // 7: user.UserService.UpdateMe:input_type -> user.UpdateMeRequest
+	2,  // 8: user.UserService.ListUsers:output_type -> user.ListUsersResponse
+	4,  // 9: user.UserService.GetProfile:output_type -> user.GetProfileResponse
+	6,  // 10: user.UserService.UpdateProfile:output_type -> user.UpdateProfileResponse
+	8,  // 11: user.UserService.DeleteProfile:output_type -> user.DeleteProfileResponse
+	4,  // 12: user.UserService.GetMe:output_type -> user.GetProfileResponse
+	6,  // 13: user.UserService.UpdateMe:output_type -> user.UpdateProfileResponse
+	8,  // [8:14] is the sub-list for method output_type
+	2,  // [2:8] is the sub-list for method input_type
+	2,  // [2:2] is the sub-list for extension type_name
+	2,  // [2:2] is the sub-list for extension extendee
+	0,  // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_proto_user_proto_init() }
@@ -682,7 +796,7 @@ func file_proto_user_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_user_proto_rawDesc), len(file_proto_user_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   9,
+			NumMessages:   11,
 			NumExtensions: 0,
 			NumServices:   1,
 		},